@@ -18,12 +18,16 @@ package v1alpha3
 
 import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 const (
-	RunningPhase   OrchestratorPhase = "Running"
-	CompletedPhase OrchestratorPhase = "Completed"
-	FailedPhase    OrchestratorPhase = "Failed"
+	RunningPhase    OrchestratorPhase = "Running"
+	InstallingPhase OrchestratorPhase = "Installing"
+	CompletedPhase  OrchestratorPhase = "Completed"
+	FailedPhase     OrchestratorPhase = "Failed"
+	DeletingPhase   OrchestratorPhase = "Deleting"
+	PausedPhase     OrchestratorPhase = "Paused"
 )
 
 // OrchestratorSpec defines the desired state of Orchestrator
@@ -56,18 +60,98 @@ type OrchestratorSpec struct {
 	// Configuration for ArgoCD. Optional
 	// +kubebuilder:default={enabled: false}
 	ArgoCd ArgoCD `json:"argocd,omitempty"`
+
+	// Configuration for installing the Orchestrator on a cluster without internet access. Optional
+	Disconnected DisconnectedConfig `json:"disconnected,omitempty"`
+
+	// Determines whether the install plans of the operator Subscriptions are only approved when they install
+	// versions of the compatibility matrix of this release. Defaults to false
+	// +kubebuilder:default=false
+	EnforceCompatibility bool `json:"enforceCompatibility,omitempty"`
+
+	// Determines what happens to the objects created by the operator when the Orchestrator CR is deleted.
+	// Delete removes them, Orphan keeps them all, and RetainData removes them except for the workflow and PostgreSQL
+	// namespaces, secrets and volume claims which hold the workflows and their data. Defaults to Delete
+	// +kubebuilder:validation:Enum=Delete;Orphan;RetainData
+	// +kubebuilder:default=Delete
+	DeletionPolicy DeletionPolicy `json:"deletionPolicy,omitempty"`
 }
 
+type DeletionPolicy string
+
+const (
+	DeletionPolicyDelete     DeletionPolicy = "Delete"
+	DeletionPolicyOrphan     DeletionPolicy = "Orphan"
+	DeletionPolicyRetainData DeletionPolicy = "RetainData"
+)
+
 type ServerlessLogicOperator struct {
 	// Determines whether to install the ServerlessLogic operator
 	// +kubebuilder:default=true
 	InstallOperator bool `json:"installOperator"`
+
+	// Determines how the operator is provided when installOperator is true. Managed installs it with an OLM
+	// Subscription. Detect adopts the operator already installed on the cluster, found from its CRDs and
+	// ClusterServiceVersion, and only installs it when none is found. Defaults to Managed
+	// +kubebuilder:validation:Enum=Managed;Detect
+	// +kubebuilder:default=Managed
+	InstallMode OperatorInstallMode `json:"installMode,omitempty"`
+
+	// OLM subscription configuration for the ServerlessLogic operator. Optional
+	Subscription SubscriptionConfig `json:"subscription,omitempty"`
 }
 
 type ServerlessOperator struct {
 	// Determines whether to install the Serverless operator
 	// +kubebuilder:default=true
 	InstallOperator bool `json:"installOperator"`
+
+	// Determines how the operator is provided when installOperator is true. Managed installs it with an OLM
+	// Subscription. Detect adopts the operator already installed on the cluster, found from its CRDs and
+	// ClusterServiceVersion, and only installs it when none is found. Defaults to Managed
+	// +kubebuilder:validation:Enum=Managed;Detect
+	// +kubebuilder:default=Managed
+	InstallMode OperatorInstallMode `json:"installMode,omitempty"`
+
+	// OLM subscription configuration for the Serverless operator. Optional
+	Subscription SubscriptionConfig `json:"subscription,omitempty"`
+
+	// Spec of the KnativeServing CR, such as high availability, ingress, config maps and workload overrides.
+	// When set, it replaces the spec of the live CR. See https://knative.dev/docs/install/operator/configuring-serving-cr/
+	// Optional
+	// +kubebuilder:pruning:PreserveUnknownFields
+	Serving *runtime.RawExtension `json:"serving,omitempty"`
+
+	// Spec of the KnativeEventing CR, such as high availability, config maps and workload overrides.
+	// When set, it replaces the spec of the live CR. See https://knative.dev/docs/install/operator/configuring-eventing-cr/
+	// Optional
+	// +kubebuilder:pruning:PreserveUnknownFields
+	Eventing *runtime.RawExtension `json:"eventing,omitempty"`
+}
+
+type OperatorInstallMode string
+
+const (
+	OperatorInstallModeManaged OperatorInstallMode = "Managed"
+	OperatorInstallModeDetect  OperatorInstallMode = "Detect"
+)
+
+type SubscriptionConfig struct {
+	// Channel of the operator package to subscribe to. Defaults to the channel supported by this release
+	Channel string `json:"channel,omitempty"`
+
+	// Name of the CSV the subscription starts from. Defaults to the CSV supported by this release
+	StartingCSV string `json:"startingCSV,omitempty"`
+
+	// Name of the catalog source providing the operator package. Defaults to redhat-operators
+	Source string `json:"source,omitempty"`
+
+	// Namespace of the catalog source. Defaults to openshift-marketplace
+	SourceNamespace string `json:"sourceNamespace,omitempty"`
+
+	// Approval strategy of the install plans created for the subscription. Defaults to Manual
+	// +kubebuilder:validation:Enum=Manual;Automatic
+	InstallPlanApproval string `json:"installPlanApproval,omitempty"`
 }
 
 type RHDHConfig struct {
@@ -93,6 +177,180 @@ type RHDHConfig struct {
 
 	// Configuration for RHDH Plugins.
 	RHDHPlugins RHDHPlugins `json:"plugins,omitempty"`
+
+	// Determines how the operator is provided when installOperator is true. Managed installs it with an OLM
+	// Subscription. Detect adopts the operator already installed on the cluster, found from its CRDs and
+	// ClusterServiceVersion, and only installs it when none is found. Defaults to Managed
+	// +kubebuilder:validation:Enum=Managed;Detect
+	// +kubebuilder:default=Managed
+	InstallMode OperatorInstallMode `json:"installMode,omitempty"`
+
+	// OLM subscription configuration for the RHDH operator. Optional
+	Subscription SubscriptionConfig `json:"subscription,omitempty"`
+
+	// Number of replicas of the RHDH instance. Defaults to 1
+	// +kubebuilder:default=1
+	// +kubebuilder:validation:Minimum=0
+	Replicas *int32 `json:"replicas,omitempty"`
+
+	// Compute resources of the RHDH backend container. Unset values are left to the RHDH operator. Optional
+	Resources ContainerResources `json:"resources,omitempty"`
+
+	// Names of existing secrets in the RHDH namespace whose keys are injected as environment variables
+	// into the RHDH instance, in addition to the backstage-backend-auth-secret. Optional
+	ExtraEnvSecrets []string `json:"extraEnvSecrets,omitempty"`
+
+	// External URL of the RHDH instance, used by the app-config. Defaults to
+	// https://backstage-<name>-<namespace>.<cluster domain>. Optional
+	// +kubebuilder:validation:Pattern=`^https?://`
+	BaseURL string `json:"baseUrl,omitempty"`
+
+	// Resource exposing the RHDH instance on clusters without OpenShift Routes. Ignored on OpenShift,
+	// where the RHDH operator creates a Route. Optional
+	// +kubebuilder:default={type: Ingress}
+	Ingress RHDHIngress `json:"ingress,omitempty"`
+
+	// Authentication of the RHDH instance. Defaults to the GitHub provider, and to the guest provider in devMode.
+	// Optional
+	Auth RHDHAuth `json:"auth,omitempty"`
+
+	// SCM integrations of the RHDH instance, used by the catalog and the scaffolder. The scaffolder backend module
+	// of each integration type is enabled. Defaults to github.com with the GITHUB_TOKEN key, and to the GitLab host
+	// of the GITLAB_HOST key with the GITLAB_TOKEN key. Optional
+	Integrations []RHDHIntegration `json:"integrations,omitempty"`
+}
+
+type RHDHIngressType string
+
+const (
+	RHDHIngressTypeIngress   RHDHIngressType = "Ingress"
+	RHDHIngressTypeHTTPRoute RHDHIngressType = "HTTPRoute"
+	RHDHIngressTypeNone      RHDHIngressType = "None"
+)
+
+type RHDHIngress struct {
+	// Kind of resource exposing RHDH: a Kubernetes Ingress, a Gateway API HTTPRoute, or None to expose
+	// it by other means. Defaults to Ingress
+	// +kubebuilder:validation:Enum=Ingress;HTTPRoute;None
+	// +kubebuilder:default=Ingress
+	Type RHDHIngressType `json:"type,omitempty"`
+
+	// Name of the IngressClass of the Ingress. Defaults to the default IngressClass of the cluster. Optional
+	ClassName string `json:"className,omitempty"`
+
+	// Name of the secret holding the TLS certificate of the Ingress host. Optional
+	TLSSecretName string `json:"tlsSecretName,omitempty"`
+
+	// Name of the Gateway the HTTPRoute is attached to. Required for the HTTPRoute type
+	GatewayName string `json:"gatewayName,omitempty"`
+
+	// Namespace of the Gateway the HTTPRoute is attached to. Defaults to the RHDH namespace
+	GatewayNamespace string `json:"gatewayNamespace,omitempty"`
+}
+
+type RHDHAuthProviderType string
+
+const (
+	RHDHAuthProviderGitHub    RHDHAuthProviderType = "github"
+	RHDHAuthProviderGitLab    RHDHAuthProviderType = "gitlab"
+	RHDHAuthProviderOIDC      RHDHAuthProviderType = "oidc"
+	RHDHAuthProviderMicrosoft RHDHAuthProviderType = "microsoft"
+	RHDHAuthProviderGuest     RHDHAuthProviderType = "guest"
+)
+
+// SupportedSignInResolvers are the built-in sign-in resolvers of each provider in RHDH.
+var SupportedSignInResolvers = map[RHDHAuthProviderType][]string{
+	RHDHAuthProviderGitHub: {
+		"usernameMatchingUserEntityName", "emailMatchingUserEntityProfileEmail", "emailLocalPartMatchingUserEntityName"},
+	RHDHAuthProviderGitLab: {
+		"usernameMatchingUserEntityName", "emailMatchingUserEntityProfileEmail", "emailLocalPartMatchingUserEntityName"},
+	RHDHAuthProviderOIDC: {
+		"emailMatchingUserEntityProfileEmail", "emailLocalPartMatchingUserEntityName",
+		"preferredUsernameMatchingUserEntityName", "oidcSubClaimMatchingKeycloakUserId"},
+	RHDHAuthProviderMicrosoft: {
+		"emailMatchingUserEntityProfileEmail", "emailLocalPartMatchingUserEntityName",
+		"emailMatchingUserEntityAnnotation", "userIdMatchingUserEntityAnnotation"},
+}
+
+type RHDHAuth struct {
+	// Auth environment of RHDH, under which the provider credentials are configured. Defaults to development
+	// +kubebuilder:default=development
+	// +kubebuilder:validation:Pattern=`^[a-zA-Z][a-zA-Z0-9_-]*$`
+	Environment string `json:"environment,omitempty"`
+
+	// Authentication providers of RHDH. The first one is the provider of the sign-in page. The credentials are read
+	// from the keys of the backstage-backend-auth-secret or of the extraEnvSecrets. Defaults to the GitHub provider
+	// +listType=map
+	// +listMapKey=type
+	Providers []RHDHAuthProvider `json:"providers,omitempty"`
+
+	// Secret key holding the secret which signs the auth sessions, required by the oidc provider. Defaults to
+	// AUTH_SESSION_SECRET
+	SessionSecretKey string `json:"sessionSecretKey,omitempty"`
+}
+
+type RHDHAuthProvider struct {
+	// Type of the provider: github, gitlab, oidc (such as Keycloak), microsoft or guest
+	// +kubebuilder:validation:Enum=github;gitlab;oidc;microsoft;guest
+	// +kubebuilder:validation:Required
+	Type RHDHAuthProviderType `json:"type"`
+
+	// Secret key holding the client ID. Defaults to <TYPE>_CLIENT_ID, such as OIDC_CLIENT_ID. Ignored by guest
+	ClientIDKey string `json:"clientIdKey,omitempty"`
+
+	// Secret key holding the client secret. Defaults to <TYPE>_CLIENT_SECRET, such as OIDC_CLIENT_SECRET.
+	// Ignored by guest
+	ClientSecretKey string `json:"clientSecretKey,omitempty"`
+
+	// Base URL of the provider: the GitHub Enterprise instance, the GitLab instance, which defaults to
+	// https://gitlab.com, or the OIDC metadata URL, such as
+	// https://keycloak.example.com/realms/my-realm/.well-known/openid-configuration, which is required for oidc.
+	// Optional
+	// +kubebuilder:validation:Pattern=`^https?://`
+	URL string `json:"url,omitempty"`
+
+	// Secret key holding the tenant ID of Microsoft Entra ID. Defaults to MICROSOFT_TENANT_ID. Only used by microsoft
+	TenantIDKey string `json:"tenantIdKey,omitempty"`
+
+	// Sign-in resolvers matching the signed-in user to a catalog User entity, tried in order. Defaults to
+	// usernameMatchingUserEntityName for github and gitlab, and emailMatchingUserEntityProfileEmail for oidc and
+	// microsoft. Ignored by guest
+	SignInResolvers []string `json:"signInResolvers,omitempty"`
+}
+
+type RHDHIntegrationType string
+
+const (
+	RHDHIntegrationGitHub          RHDHIntegrationType = "github"
+	RHDHIntegrationGitLab          RHDHIntegrationType = "gitlab"
+	RHDHIntegrationBitbucketServer RHDHIntegrationType = "bitbucketServer"
+	RHDHIntegrationBitbucketCloud  RHDHIntegrationType = "bitbucketCloud"
+	RHDHIntegrationAzure           RHDHIntegrationType = "azure"
+)
+
+type RHDHIntegration struct {
+	// Type of the SCM: github, including GitHub Enterprise, gitlab, bitbucketServer, bitbucketCloud or azure for
+	// Azure DevOps
+	// +kubebuilder:validation:Enum=github;gitlab;bitbucketServer;bitbucketCloud;azure
+	// +kubebuilder:validation:Required
+	Type RHDHIntegrationType `json:"type"`
+
+	// Host of the SCM, such as github.example.com. Defaults to github.com, gitlab.com or dev.azure.com. Required for
+	// bitbucketServer and ignored by bitbucketCloud
+	Host string `json:"host,omitempty"`
+
+	// Base URL of the SCM API. Defaults to https://<host>/api/v3 for GitHub Enterprise, https://<host>/api/v4 for
+	// gitlab and https://<host>/rest/api/1.0 for bitbucketServer. Optional
+	// +kubebuilder:validation:Pattern=`^https?://`
+	APIBaseURL string `json:"apiBaseUrl,omitempty"`
+
+	// Secret key holding the token, the app password of bitbucketCloud, or the personal access token of azure.
+	// Defaults to GITHUB_TOKEN, GITLAB_TOKEN, BITBUCKET_SERVER_TOKEN, BITBUCKET_CLOUD_APP_PASSWORD or AZURE_TOKEN
+	TokenKey string `json:"tokenKey,omitempty"`
+
+	// Secret key holding the username of bitbucketCloud. Defaults to BITBUCKET_CLOUD_USERNAME. Only used by
+	// bitbucketCloud
+	UsernameKey string `json:"usernameKey,omitempty"`
 }
 
 type RHDHPlugins struct {
@@ -137,8 +395,45 @@ type PostgresConfig struct {
 	// Existing database instance used by data index and job service
 	// +kubebuilder:validation:Required
 	DatabaseName string `json:"database"`
+
+	// TLS mode of the connection of the database preflight check: disable, prefer, which uses TLS when the server
+	// supports it, or require, which fails when it does not. Defaults to prefer
+	// +kubebuilder:validation:Enum=disable;prefer;require
+	// +kubebuilder:default=prefer
+	SSLMode string `json:"sslMode,omitempty"`
+
+	// Determines whether the operator deploys a single-instance PostgreSQL StatefulSet named after the service,
+	// generates the credentials into the auth secret and creates the database. Intended for dev and test clusters.
+	// +kubebuilder:default=false
+	Provision bool `json:"provision,omitempty"`
+
+	// Image of the provisioned PostgreSQL instance
+	// +kubebuilder:default="registry.redhat.io/rhel9/postgresql-15:latest"
+	Image string `json:"image,omitempty"`
+
+	// Size of the persistent volume claim of the provisioned PostgreSQL instance
+	// +kubebuilder:validation:Pattern=`^(([0-9]+(\.[0-9]*)?)|(\.[0-9]+))(([KMGTPE]i)|[numkMGTPE]|([eE](\+|-)?(([0-9]+(\.[0-9]*)?)|(\.[0-9]+))))?$`
+	// +kubebuilder:default="1Gi"
+	StorageSize string `json:"storageSize,omitempty"`
+
+	// Storage class of the persistent volume claim of the provisioned PostgreSQL instance.
+	// Uses the cluster default storage class when empty.
+	StorageClassName string `json:"storageClassName,omitempty"`
+
+	// Determines whether the persistent volume claim and the generated secret of the provisioned PostgreSQL instance
+	// are kept or deleted when the Orchestrator CR is deleted
+	// +kubebuilder:validation:Enum=Retain;Delete
+	// +kubebuilder:default=Retain
+	RetentionPolicy PostgresRetentionPolicy `json:"retentionPolicy,omitempty"`
 }
 
+type PostgresRetentionPolicy string
+
+const (
+	PostgresRetainPolicy PostgresRetentionPolicy = "Retain"
+	PostgresDeletePolicy PostgresRetentionPolicy = "Delete"
+)
+
 type PostgresAuthSecret struct {
 	// Name of existing secret to use for PostgreSQL credentials.
 	// +kubebuilder:validation:Required
@@ -166,6 +461,16 @@ type PlatformConfig struct {
 
 	// Configuration for sonataflow platform monitoring
 	Monitoring MonitoringConfig `json:"monitoring,omitempty"`
+
+	// Additional namespaces for workflows which share the Data Index and Job Service of the platform namespace.
+	// Each namespace gets a SonataFlowPlatform without services, the network policies and a copy of the
+	// PostgreSQL secret. Optional
+	// +listType=set
+	AdditionalWorkflowNamespaces []string `json:"additionalWorkflowNamespaces,omitempty"`
+
+	// Domain of the cluster routes, used to compute the default RHDH URL. Defaults to the domain of the
+	// OpenShift ingress configuration; required on other clusters, unless rhdh.baseUrl is set. Optional
+	ClusterDomain string `json:"clusterDomain,omitempty"`
 }
 
 type Eventing struct {
@@ -180,11 +485,21 @@ type MonitoringConfig struct {
 }
 
 type Broker struct {
-	// Name of existing Broker instance
+	// Name of existing Broker instance, or of the Broker instance to create
 	Name string `json:"name,omitempty"`
 
-	// Namespace of existing Broker instance
+	// Namespace of existing Broker instance, or of the Broker instance to create
 	Namespace string `json:"namespace,omitempty"`
+
+	// Determines whether the operator creates the Broker instance. Defaults to false
+	// +kubebuilder:default=false
+	Create bool `json:"create,omitempty"`
+
+	// Class of the created Broker instance. The Kafka class uses the kafka-broker-config ConfigMap
+	// of the knative-eventing namespace. Defaults to MTChannelBasedBroker
+	// +kubebuilder:validation:Enum=MTChannelBasedBroker;Kafka
+	// +kubebuilder:default=MTChannelBasedBroker
+	Class string `json:"class,omitempty"`
 }
 
 type Resource struct {
@@ -206,6 +521,24 @@ type MemoryCpu struct {
 	Cpu string `json:"cpu,omitempty"`
 }
 
+// ContainerResources are compute resources without defaults, so that unset values are left to the deployed operator.
+type ContainerResources struct {
+	// Describe the minimum amount of compute resources required.
+	// Requests cannot exceed Limits. More info: https://kubernetes.io/docs/concepts/configuration/manage-resources-containers/
+	Requests ResourceQuantities `json:"requests,omitempty"`
+	// Describes the maximum amount of compute resources allowed.
+	// More info: https://kubernetes.io/docs/concepts/configuration/manage-resources-containers/
+	Limits ResourceQuantities `json:"limits,omitempty"`
+}
+
+type ResourceQuantities struct {
+	// Defines the memory resource quantity. Optional
+	Memory string `json:"memory,omitempty"`
+
+	// Defines the CPU resource quantity. Optional
+	Cpu string `json:"cpu,omitempty"`
+}
+
 type Tekton struct {
 	// Determines whether to create the Tekton pipeline resources. Defaults to false.
 	// +kubebuilder:default=false
@@ -222,14 +555,115 @@ type ArgoCD struct {
 	Namespace string `json:"namespace,omitempty"`
 }
 
+type DisconnectedConfig struct {
+	// Determines whether the cluster is disconnected. When enabled, the DisconnectedReady condition reports
+	// the external URLs still referenced by the rendered configuration. Defaults to false
+	// +kubebuilder:default=false
+	Enabled bool `json:"enabled,omitempty"`
+
+	// URL of the npm registry mirror used to install the RHDH dynamic plugins. Optional
+	NpmRegistry string `json:"npmRegistry,omitempty"`
+
+	// Base URL of the mirror serving the Orchestrator plugin tarballs. Optional
+	PluginBaseUrl string `json:"pluginBaseUrl,omitempty"`
+
+	// URLs of the catalog locations registered in RHDH, replacing the default GitHub locations. Optional
+	CatalogLocations []string `json:"catalogLocations,omitempty"`
+
+	// Mirrored images and tools used by the Tekton tasks. Optional
+	ToolImages ToolImages `json:"toolImages,omitempty"`
+}
+
+type ToolImages struct {
+	// Image used by the flattener, build-manifests and build-gitops Tekton tasks. Optional
+	BaseImage string `json:"baseImage,omitempty"`
+
+	// Image used by the git-cli Tekton task. Optional
+	GitImage string `json:"gitImage,omitempty"`
+
+	// URL of the kn-workflow CLI tarball downloaded by the build-manifests Tekton task. Optional
+	KnWorkflowCliUrl string `json:"knWorkflowCliUrl,omitempty"`
+
+	// URL of the workflow builder Dockerfile downloaded by the flattener Tekton task. Optional
+	WorkflowBuilderDockerfileUrl string `json:"workflowBuilderDockerfileUrl,omitempty"`
+}
+
 type OrchestratorPhase string
 
+// InventoryEntry identifies an object created by the operator for the Orchestrator
+type InventoryEntry struct {
+	// API version of the object
+	APIVersion string `json:"apiVersion"`
+
+	// Kind of the object
+	Kind string `json:"kind"`
+
+	// Namespace of the object, empty for cluster scoped objects
+	Namespace string `json:"namespace,omitempty"`
+
+	// Name of the object
+	Name string `json:"name"`
+}
+
+type OperatorSource string
+
+const (
+	OperatorSourceManaged  OperatorSource = "Managed"
+	OperatorSourceExternal OperatorSource = "External"
+)
+
+// OperatorStatus reports how an operator used by the Orchestrator is provided
+type OperatorStatus struct {
+	// Name of the operator: ServerlessLogic, Knative or RHDH
+	Name string `json:"name"`
+
+	// Managed when installed by the Subscription of the Orchestrator, External when adopted
+	// +kubebuilder:validation:Enum=Managed;External
+	Source OperatorSource `json:"source"`
+
+	// ClusterServiceVersion of an external operator, empty when it was not installed by OLM
+	CSV string `json:"csv,omitempty"`
+
+	// Namespace of the ClusterServiceVersion
+	Namespace string `json:"namespace,omitempty"`
+
+	// Version of an external operator, empty when unknown
+	Version string `json:"version,omitempty"`
+}
+
 // OrchestratorStatus defines the observed state of Orchestrator
 type OrchestratorStatus struct {
 	// INSERT ADDITIONAL STATUS FIELD - define observed state of cluster
 	// Important: Run "make" to regenerate code after modifying this file
+
+	// The generation of the Orchestrator spec that was last reconciled
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// Address of the Broker created by the operator
+	BrokerURL string `json:"brokerUrl,omitempty"`
+
+	// Platform detected from the APIs served by the cluster: OpenShift or Kubernetes
+	Platform string `json:"platform,omitempty"`
+
+	// Operators used by the Orchestrator, installed by the operator or adopted
+	// +listType=map
+	// +listMapKey=name
+	Operators []OperatorStatus `json:"operators,omitempty"`
+
+	// Objects created by the operator, in creation order. They are deleted with the Orchestrator
+	// +listType=atomic
+	Inventory []InventoryEntry `json:"inventory,omitempty"`
+
+	// Objects which the deletion of the Orchestrator would remove, listed while the deletion preview is requested
+	// +listType=atomic
+	DeletionPreview []InventoryEntry `json:"deletionPreview,omitempty"`
+
+	// Conditions of the Orchestrator, with one condition per managed component:
+	// ServerlessLogicReady, KnativeReady, RHDHReady, NetworkPoliciesReady and GitOpsReady
+	// +listType=map
+	// +listMapKey=type
 	Conditions []metav1.Condition `json:"conditions,omitempty"`
-	// +kubebuilder:validation:Enum={"Running","Completed", "Failed"}
+	// +kubebuilder:validation:Enum={"Running","Installing","Completed", "Failed", "Deleting", "Paused"}
 	Phase OrchestratorPhase `json:"phase,omitempty" protobuf:"bytes,1,opt,casttype=OrchestratorPhase"`
 }
 
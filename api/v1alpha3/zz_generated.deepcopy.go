@@ -22,7 +22,7 @@ package v1alpha3
 
 import (
 	"k8s.io/apimachinery/pkg/apis/meta/v1"
-	runtime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime"
 )
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
@@ -55,6 +55,44 @@ func (in *Broker) DeepCopy() *Broker {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ContainerResources) DeepCopyInto(out *ContainerResources) {
+	*out = *in
+	out.Requests = in.Requests
+	out.Limits = in.Limits
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ContainerResources.
+func (in *ContainerResources) DeepCopy() *ContainerResources {
+	if in == nil {
+		return nil
+	}
+	out := new(ContainerResources)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DisconnectedConfig) DeepCopyInto(out *DisconnectedConfig) {
+	*out = *in
+	if in.CatalogLocations != nil {
+		in, out := &in.CatalogLocations, &out.CatalogLocations
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	out.ToolImages = in.ToolImages
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DisconnectedConfig.
+func (in *DisconnectedConfig) DeepCopy() *DisconnectedConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(DisconnectedConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Eventing) DeepCopyInto(out *Eventing) {
 	*out = *in
@@ -71,6 +109,21 @@ func (in *Eventing) DeepCopy() *Eventing {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *InventoryEntry) DeepCopyInto(out *InventoryEntry) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new InventoryEntry.
+func (in *InventoryEntry) DeepCopy() *InventoryEntry {
+	if in == nil {
+		return nil
+	}
+	out := new(InventoryEntry)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *MemoryCpu) DeepCopyInto(out *MemoryCpu) {
 	*out = *in
@@ -116,12 +169,27 @@ func (in *NotificationConfig) DeepCopy() *NotificationConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OperatorStatus) DeepCopyInto(out *OperatorStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OperatorStatus.
+func (in *OperatorStatus) DeepCopy() *OperatorStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(OperatorStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Orchestrator) DeepCopyInto(out *Orchestrator) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	in.Status.DeepCopyInto(&out.Status)
 }
 
@@ -179,12 +247,13 @@ func (in *OrchestratorList) DeepCopyObject() runtime.Object {
 func (in *OrchestratorSpec) DeepCopyInto(out *OrchestratorSpec) {
 	*out = *in
 	out.ServerlessLogicOperator = in.ServerlessLogicOperator
-	out.ServerlessOperator = in.ServerlessOperator
-	out.RHDHConfig = in.RHDHConfig
+	in.ServerlessOperator.DeepCopyInto(&out.ServerlessOperator)
+	in.RHDHConfig.DeepCopyInto(&out.RHDHConfig)
 	out.PostgresConfig = in.PostgresConfig
-	out.PlatformConfig = in.PlatformConfig
+	in.PlatformConfig.DeepCopyInto(&out.PlatformConfig)
 	out.Tekton = in.Tekton
 	out.ArgoCd = in.ArgoCd
+	in.Disconnected.DeepCopyInto(&out.Disconnected)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OrchestratorSpec.
@@ -200,6 +269,21 @@ func (in *OrchestratorSpec) DeepCopy() *OrchestratorSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *OrchestratorStatus) DeepCopyInto(out *OrchestratorStatus) {
 	*out = *in
+	if in.Operators != nil {
+		in, out := &in.Operators, &out.Operators
+		*out = make([]OperatorStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.Inventory != nil {
+		in, out := &in.Inventory, &out.Inventory
+		*out = make([]InventoryEntry, len(*in))
+		copy(*out, *in)
+	}
+	if in.DeletionPreview != nil {
+		in, out := &in.DeletionPreview, &out.DeletionPreview
+		*out = make([]InventoryEntry, len(*in))
+		copy(*out, *in)
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make([]v1.Condition, len(*in))
@@ -225,6 +309,11 @@ func (in *PlatformConfig) DeepCopyInto(out *PlatformConfig) {
 	out.Resources = in.Resources
 	out.Eventing = in.Eventing
 	out.Monitoring = in.Monitoring
+	if in.AdditionalWorkflowNamespaces != nil {
+		in, out := &in.AdditionalWorkflowNamespaces, &out.AdditionalWorkflowNamespaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new PlatformConfig.
@@ -268,10 +357,71 @@ func (in *PostgresConfig) DeepCopy() *PostgresConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RHDHAuth) DeepCopyInto(out *RHDHAuth) {
+	*out = *in
+	if in.Providers != nil {
+		in, out := &in.Providers, &out.Providers
+		*out = make([]RHDHAuthProvider, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RHDHAuth.
+func (in *RHDHAuth) DeepCopy() *RHDHAuth {
+	if in == nil {
+		return nil
+	}
+	out := new(RHDHAuth)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RHDHAuthProvider) DeepCopyInto(out *RHDHAuthProvider) {
+	*out = *in
+	if in.SignInResolvers != nil {
+		in, out := &in.SignInResolvers, &out.SignInResolvers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RHDHAuthProvider.
+func (in *RHDHAuthProvider) DeepCopy() *RHDHAuthProvider {
+	if in == nil {
+		return nil
+	}
+	out := new(RHDHAuthProvider)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RHDHConfig) DeepCopyInto(out *RHDHConfig) {
 	*out = *in
 	out.RHDHPlugins = in.RHDHPlugins
+	out.Subscription = in.Subscription
+	if in.Replicas != nil {
+		in, out := &in.Replicas, &out.Replicas
+		*out = new(int32)
+		**out = **in
+	}
+	out.Resources = in.Resources
+	if in.ExtraEnvSecrets != nil {
+		in, out := &in.ExtraEnvSecrets, &out.ExtraEnvSecrets
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	out.Ingress = in.Ingress
+	in.Auth.DeepCopyInto(&out.Auth)
+	if in.Integrations != nil {
+		in, out := &in.Integrations, &out.Integrations
+		*out = make([]RHDHIntegration, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RHDHConfig.
@@ -284,6 +434,36 @@ func (in *RHDHConfig) DeepCopy() *RHDHConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RHDHIngress) DeepCopyInto(out *RHDHIngress) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RHDHIngress.
+func (in *RHDHIngress) DeepCopy() *RHDHIngress {
+	if in == nil {
+		return nil
+	}
+	out := new(RHDHIngress)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RHDHIntegration) DeepCopyInto(out *RHDHIntegration) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RHDHIntegration.
+func (in *RHDHIntegration) DeepCopy() *RHDHIntegration {
+	if in == nil {
+		return nil
+	}
+	out := new(RHDHIntegration)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RHDHPlugins) DeepCopyInto(out *RHDHPlugins) {
 	*out = *in
@@ -317,9 +497,25 @@ func (in *Resource) DeepCopy() *Resource {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ResourceQuantities) DeepCopyInto(out *ResourceQuantities) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ResourceQuantities.
+func (in *ResourceQuantities) DeepCopy() *ResourceQuantities {
+	if in == nil {
+		return nil
+	}
+	out := new(ResourceQuantities)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ServerlessLogicOperator) DeepCopyInto(out *ServerlessLogicOperator) {
 	*out = *in
+	out.Subscription = in.Subscription
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServerlessLogicOperator.
@@ -335,6 +531,17 @@ func (in *ServerlessLogicOperator) DeepCopy() *ServerlessLogicOperator {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ServerlessOperator) DeepCopyInto(out *ServerlessOperator) {
 	*out = *in
+	out.Subscription = in.Subscription
+	if in.Serving != nil {
+		in, out := &in.Serving, &out.Serving
+		*out = new(runtime.RawExtension)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Eventing != nil {
+		in, out := &in.Eventing, &out.Eventing
+		*out = new(runtime.RawExtension)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ServerlessOperator.
@@ -347,6 +554,21 @@ func (in *ServerlessOperator) DeepCopy() *ServerlessOperator {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SubscriptionConfig) DeepCopyInto(out *SubscriptionConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubscriptionConfig.
+func (in *SubscriptionConfig) DeepCopy() *SubscriptionConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(SubscriptionConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *Tekton) DeepCopyInto(out *Tekton) {
 	*out = *in
@@ -361,3 +583,18 @@ func (in *Tekton) DeepCopy() *Tekton {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ToolImages) DeepCopyInto(out *ToolImages) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ToolImages.
+func (in *ToolImages) DeepCopy() *ToolImages {
+	if in == nil {
+		return nil
+	}
+	out := new(ToolImages)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,159 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	orchestratorv1alpha2 "github.com/rhdhorchestrator/orchestrator-operator/api/v1alpha3"
+	"github.com/rhdhorchestrator/orchestrator-operator/internal/controller/kube"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	knativeBrokerCRDName         = "brokers.eventing.knative.dev"
+	knativeBrokerClassAnnotation = "eventing.knative.dev/broker.class"
+	knativeKafkaBrokerClass      = "Kafka"
+	knativeMTChannelBrokerClass  = "MTChannelBasedBroker"
+	knativeKafkaBrokerConfig     = "kafka-broker-config"
+)
+
+// handleBroker creates the Broker configured with create mode and returns its address once it is ready.
+// It returns an empty address when the Broker is not managed by the operator.
+func handleBroker(
+	ctx context.Context, k8client client.Client, orchestrator *orchestratorv1alpha2.Orchestrator) (string, error) {
+	logger := log.FromContext(ctx)
+
+	broker := orchestrator.Spec.PlatformConfig.Eventing.Broker
+	if !broker.Create {
+		return "", nil
+	}
+	logger.Info("Handling Knative Broker", "Broker", broker.Name, "NS", broker.Namespace)
+
+	if err := kube.CheckCRDExists(ctx, k8client, knativeBrokerCRDName); err != nil {
+		if apierrors.IsNotFound(err) {
+			logger.Info("CRD resource not found or ready", "CRD", knativeBrokerCRDName)
+			return "", err
+		}
+		logger.Error(err, "Error occurred when retrieving CRD", "CRD", knativeBrokerCRDName)
+		return "", err
+	}
+
+	if _, err := kube.CheckNamespaceExist(ctx, k8client, broker.Namespace); err != nil {
+		if !apierrors.IsNotFound(err) {
+			logger.Error(err, "Error occurred when checking namespace exist", "NS", broker.Namespace)
+			return "", err
+		}
+		if err := kube.CreateNamespace(ctx, k8client, broker.Namespace); err != nil {
+			logger.Error(err, "Error occurred when creating namespace", "NS", broker.Namespace)
+			return "", err
+		}
+	}
+
+	existingBroker := newBrokerObject()
+	if err := k8client.Get(ctx, types.NamespacedName{Name: broker.Name, Namespace: broker.Namespace}, existingBroker); err != nil {
+		if !apierrors.IsNotFound(err) {
+			logger.Error(err, "Error occurred when retrieving Broker", "Broker", broker.Name)
+			return "", err
+		}
+		desiredBroker := getBrokerObject(broker)
+		if err := k8client.Create(ctx, desiredBroker); err != nil {
+			logger.Error(err, "Error occurred when creating Broker", "Broker", broker.Name)
+			return "", err
+		}
+		logger.Info("Successfully created Broker", "Broker", broker.Name)
+		return "", &kube.NotReadyError{Kind: "Broker", Name: broker.Name}
+	}
+
+	// the broker class cannot be changed once the broker is created
+	if existingClass := existingBroker.GetAnnotations()[knativeBrokerClassAnnotation]; existingClass != getBrokerClass(broker) {
+		return "", fmt.Errorf("broker %s/%s has class %s; delete it to recreate it with class %s",
+			broker.Namespace, broker.Name, existingClass, getBrokerClass(broker))
+	}
+
+	if !isBrokerReady(existingBroker) {
+		logger.Info("Broker is not ready", "Broker", broker.Name)
+		return "", &kube.NotReadyError{Kind: "Broker", Name: broker.Name}
+	}
+	brokerURL, _, _ := unstructured.NestedString(existingBroker.Object, "status", "address", "url")
+	return brokerURL, nil
+}
+
+func newBrokerObject() *unstructured.Unstructured {
+	brokerObject := &unstructured.Unstructured{}
+	brokerObject.SetGroupVersionKind(schema.FromAPIVersionAndKind(knativeBrokerAPIVersion, knativeBrokerKind))
+	return brokerObject
+}
+
+func getBrokerClass(broker orchestratorv1alpha2.Broker) string {
+	if broker.Class == "" {
+		return knativeMTChannelBrokerClass
+	}
+	return broker.Class
+}
+
+func getBrokerObject(broker orchestratorv1alpha2.Broker) *unstructured.Unstructured {
+	brokerObject := newBrokerObject()
+	brokerObject.SetName(broker.Name)
+	brokerObject.SetNamespace(broker.Namespace)
+	brokerObject.SetLabels(kube.AddLabel())
+	brokerObject.SetAnnotations(map[string]string{knativeBrokerClassAnnotation: getBrokerClass(broker)})
+	if getBrokerClass(broker) == knativeKafkaBrokerClass {
+		brokerObject.Object["spec"] = map[string]interface{}{
+			"config": map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "ConfigMap",
+				"name":       knativeKafkaBrokerConfig,
+				"namespace":  knativeEventingNamespacedName,
+			},
+		}
+	}
+	return brokerObject
+}
+
+func isBrokerReady(brokerObject *unstructured.Unstructured) bool {
+	conditions, _, _ := unstructured.NestedSlice(brokerObject.Object, "status", "conditions")
+	for _, condition := range conditions {
+		conditionMap, ok := condition.(map[string]interface{})
+		if ok && conditionMap["type"] == "Ready" && conditionMap["status"] == string(corev1.ConditionTrue) {
+			return true
+		}
+	}
+	return false
+}
+
+// handleBrokerCleanUp deletes the Brokers created by the operator which are no longer configured: every one of them
+// when the create mode is turned off, and the previous one when the Broker is renamed.
+func handleBrokerCleanUp(ctx context.Context, inventory *kube.InventoryClient, broker orchestratorv1alpha2.Broker) error {
+	_, err := inventory.DeleteInventory(ctx, func(entry orchestratorv1alpha2.InventoryEntry) bool {
+		if entry.APIVersion != knativeBrokerAPIVersion || entry.Kind != knativeBrokerKind {
+			return false
+		}
+		return !broker.Create || entry.Namespace != broker.Namespace || entry.Name != broker.Name
+	})
+	if err != nil {
+		log.FromContext(ctx).Error(err, "Error occurred when deleting Knative Broker", "Broker", broker.Name)
+	}
+	return err
+}
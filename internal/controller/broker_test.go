@@ -0,0 +1,173 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	orchestratorv1alpha2 "github.com/rhdhorchestrator/orchestrator-operator/api/v1alpha3"
+	"github.com/rhdhorchestrator/orchestrator-operator/internal/controller/kube"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestHandleBroker(t *testing.T) {
+	ctx := context.TODO()
+	scheme := runtime.NewScheme()
+	utilruntime.Must(corev1.AddToScheme(scheme))
+	utilruntime.Must(apiextensionsv1.AddToScheme(scheme))
+
+	brokerCRD := &apiextensionsv1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: knativeBrokerCRDName}}
+	brokerConfig := orchestratorv1alpha2.Broker{Name: "default", Namespace: "orchestrator-broker", Create: true}
+
+	readyBroker := getBrokerObject(brokerConfig)
+	readyBroker.Object["status"] = map[string]interface{}{
+		"conditions": []interface{}{map[string]interface{}{"type": "Ready", "status": "True"}},
+		"address":    map[string]interface{}{"url": "http://broker-ingress.knative-eventing.svc.cluster.local/orchestrator-broker/default"},
+	}
+
+	testCases := []struct {
+		name        string
+		broker      orchestratorv1alpha2.Broker
+		existing    []client.Object
+		expectedURL string
+		expectErr   bool
+		notReady    bool
+	}{
+		{
+			name:     "Broker without create mode is ignored",
+			broker:   orchestratorv1alpha2.Broker{Name: "default", Namespace: "orchestrator-broker"},
+			existing: []client.Object{brokerCRD},
+		},
+		{
+			name:     "Missing Broker is created",
+			broker:   brokerConfig,
+			existing: []client.Object{brokerCRD},
+			notReady: true,
+		},
+		{
+			name:     "Broker which is not ready is awaited",
+			broker:   brokerConfig,
+			existing: []client.Object{brokerCRD, getBrokerObject(brokerConfig)},
+			notReady: true,
+		},
+		{
+			name:        "Ready Broker reports its URL",
+			broker:      brokerConfig,
+			existing:    []client.Object{brokerCRD, readyBroker},
+			expectedURL: "http://broker-ingress.knative-eventing.svc.cluster.local/orchestrator-broker/default",
+		},
+		{
+			name: "Changed Broker class is rejected",
+			broker: orchestratorv1alpha2.Broker{
+				Name: "default", Namespace: "orchestrator-broker", Create: true, Class: knativeKafkaBrokerClass,
+			},
+			existing:  []client.Object{brokerCRD, readyBroker},
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "orchestrator-broker", Labels: kube.AddLabel()}}
+			objects := []client.Object{namespace}
+			for _, object := range tc.existing {
+				objects = append(objects, object.DeepCopyObject().(client.Object))
+			}
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+			orchestrator := newTestOrchestrator()
+			orchestrator.Spec.PlatformConfig.Eventing.Broker = tc.broker
+
+			brokerURL, err := handleBroker(ctx, fakeClient, orchestrator)
+			assert.Equal(t, tc.expectedURL, brokerURL)
+			switch {
+			case tc.expectErr:
+				assert.Error(t, err)
+				assert.False(t, kube.IsNotReady(err))
+			case tc.notReady:
+				assert.True(t, kube.IsNotReady(err))
+			default:
+				assert.NoError(t, err)
+			}
+
+			if tc.broker.Create {
+				created := newBrokerObject()
+				assert.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: "default", Namespace: "orchestrator-broker"}, created))
+				assert.Equal(t, knativeMTChannelBrokerClass, created.GetAnnotations()[knativeBrokerClassAnnotation])
+				assert.True(t, kube.CheckLabelExist(created.GetLabels()))
+			}
+		})
+	}
+}
+
+func TestHandleBrokerCleanUp(t *testing.T) {
+	ctx := context.TODO()
+	scheme := runtime.NewScheme()
+	utilruntime.Must(corev1.AddToScheme(scheme))
+	utilruntime.Must(orchestratorv1alpha2.AddToScheme(scheme))
+
+	brokerConfig := orchestratorv1alpha2.Broker{Name: "default", Namespace: "orchestrator-broker", Create: true}
+	brokerEntry := orchestratorv1alpha2.InventoryEntry{
+		APIVersion: knativeBrokerAPIVersion, Kind: knativeBrokerKind, Namespace: brokerConfig.Namespace, Name: brokerConfig.Name}
+	userBroker := getBrokerObject(orchestratorv1alpha2.Broker{Name: "user", Namespace: brokerConfig.Namespace})
+
+	testCases := []struct {
+		name          string
+		broker        orchestratorv1alpha2.Broker
+		expectDeleted bool
+	}{
+		{
+			name:   "Configured Broker is kept",
+			broker: brokerConfig,
+		},
+		{
+			name:          "Broker is deleted when the create mode is turned off",
+			broker:        orchestratorv1alpha2.Broker{Name: "default", Namespace: "orchestrator-broker"},
+			expectDeleted: true,
+		},
+		{
+			name:          "Previous Broker is deleted when the Broker is renamed",
+			broker:        orchestratorv1alpha2.Broker{Name: "renamed", Namespace: "orchestrator-broker", Create: true},
+			expectDeleted: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).
+				WithObjects(getBrokerObject(brokerConfig), userBroker.DeepCopy()).Build()
+			orchestrator := newTestOrchestrator()
+			orchestrator.Status.Inventory = []orchestratorv1alpha2.InventoryEntry{brokerEntry}
+			k8client := kube.NewInventoryClient(fakeClient, fakeClient, nil, nil, orchestrator)
+
+			assert.NoError(t, handleBrokerCleanUp(ctx, k8client, tc.broker))
+			err := fakeClient.Get(ctx, client.ObjectKeyFromObject(getBrokerObject(brokerConfig)), newBrokerObject())
+			if tc.expectDeleted {
+				assert.True(t, apierrors.IsNotFound(err))
+				assert.Empty(t, orchestrator.Status.Inventory)
+			} else {
+				assert.NoError(t, err)
+				assert.Equal(t, []orchestratorv1alpha2.InventoryEntry{brokerEntry}, orchestrator.Status.Inventory)
+			}
+			assert.NoError(t, fakeClient.Get(ctx, client.ObjectKeyFromObject(userBroker), newBrokerObject()),
+				"Brokers which are not in the inventory are kept")
+		})
+	}
+}
+
+func TestGetBrokerObjectKafka(t *testing.T) {
+	brokerObject := getBrokerObject(orchestratorv1alpha2.Broker{
+		Name: "default", Namespace: "orchestrator-broker", Create: true, Class: knativeKafkaBrokerClass,
+	})
+	assert.Equal(t, knativeKafkaBrokerClass, brokerObject.GetAnnotations()[knativeBrokerClassAnnotation])
+	configName, _, _ := unstructured.NestedString(brokerObject.Object, "spec", "config", "name")
+	assert.Equal(t, knativeKafkaBrokerConfig, configName)
+}
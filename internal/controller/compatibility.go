@@ -0,0 +1,193 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/blang/semver/v4"
+	orchestratorv1alpha2 "github.com/rhdhorchestrator/orchestrator-operator/api/v1alpha3"
+	"github.com/rhdhorchestrator/orchestrator-operator/internal/controller/kube"
+	"github.com/rhdhorchestrator/orchestrator-operator/internal/controller/rhdh"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// TypeIncompatibleVersions reports the installed versions which are not in the compatibility matrix.
+	TypeIncompatibleVersions string = "IncompatibleVersions"
+	ReasonVersionMismatch           = "VersionMismatch"
+	ReasonVersionsCompatible        = "VersionsCompatible"
+)
+
+// supportedVersions is a range of versions, such as 1.35.x, of the compatibility matrix.
+type supportedVersions struct {
+	text     string
+	versions semver.Range
+}
+
+func newSupportedVersions(text string) supportedVersions {
+	return supportedVersions{text: text, versions: semver.MustParseRange(text)}
+}
+
+type operatorCompatibility struct {
+	detection kube.OperatorDetection
+	supported supportedVersions
+}
+
+// compatibilityMatrix lists the versions of the operators and of the RHDH image supported by this release,
+// which installs the orchestrator plugins of rhdh.PluginsVersion.
+var compatibilityMatrix = struct {
+	operators []operatorCompatibility
+	backstage supportedVersions
+}{
+	operators: []operatorCompatibility{
+		{detection: serverlessLogicOperatorDetection, supported: newSupportedVersions("1.35.x")},
+		{detection: knativeOperatorDetection, supported: newSupportedVersions("1.35.x")},
+		{detection: rhdh.RHDHOperatorDetection, supported: newSupportedVersions("1.5.x")},
+	},
+	backstage: newSupportedVersions("1.5.x"),
+}
+
+func getOperatorCompatibility(name string) (operatorCompatibility, bool) {
+	for _, compatibility := range compatibilityMatrix.operators {
+		if compatibility.detection.Name == name {
+			return compatibility, true
+		}
+	}
+	return operatorCompatibility{}, false
+}
+
+// getInstallPlanPolicy refuses the install plans of the operator whose ClusterServiceVersions are not in the
+// compatibility matrix, when enforced by the spec.
+func getInstallPlanPolicy(orchestrator *orchestratorv1alpha2.Orchestrator, detection kube.OperatorDetection) kube.InstallPlanPolicy {
+	compatibility, found := getOperatorCompatibility(detection.Name)
+	if !orchestrator.Spec.EnforceCompatibility || !found {
+		return nil
+	}
+	return func(csvNames []string) error {
+		for _, csvName := range csvNames {
+			version, err := getCSVNameVersion(csvName)
+			if err != nil {
+				return fmt.Errorf("the version of %s is unknown: %w", csvName, err)
+			}
+			if !compatibility.supported.versions(version) {
+				return fmt.Errorf("%s is not in the supported versions %s of the %s operator",
+					csvName, compatibility.supported.text, detection.Name)
+			}
+		}
+		return nil
+	}
+}
+
+// getCSVNameVersion parses the version of a ClusterServiceVersion named <package>.v<version>.
+func getCSVNameVersion(csvName string) (semver.Version, error) {
+	index := strings.LastIndex(csvName, ".v")
+	if index < 0 {
+		return semver.Version{}, fmt.Errorf("no version in the name")
+	}
+	return semver.Parse(csvName[index+2:])
+}
+
+// getImageVersion parses the version of an image tagged by version, such as 1.5 or 1.5-200. The images referenced
+// by digest, or tagged otherwise, have no version.
+func getImageVersion(image string) (*semver.Version, bool) {
+	if strings.Contains(image, "@") {
+		return nil, false
+	}
+	index := strings.LastIndex(image, ":")
+	if index < 0 || strings.Contains(image[index:], "/") {
+		return nil, false
+	}
+	tag, _, _ := strings.Cut(strings.TrimPrefix(image[index+1:], "v"), "-")
+	version, err := semver.ParseTolerant(tag)
+	if err != nil {
+		return nil, false
+	}
+	return &version, true
+}
+
+// checkCompatibility compares the versions of the installed operators and of the RHDH image with the compatibility
+// matrix, and reports the mismatches in the IncompatibleVersions condition.
+func (r *OrchestratorReconciler) checkCompatibility(
+	ctx context.Context, installedCSVs *kube.InstalledCSVs, orchestrator *orchestratorv1alpha2.Orchestrator) error {
+	var mismatches []string
+	for _, operator := range orchestrator.Status.Operators {
+		compatibility, found := getOperatorCompatibility(operator.Name)
+		if !found {
+			continue
+		}
+		csvName, version, err := getOperatorVersion(ctx, installedCSVs, operator, compatibility.detection)
+		if err != nil {
+			return err
+		}
+		if version != nil && !compatibility.supported.versions(*version) {
+			mismatches = append(mismatches, fmt.Sprintf("%s operator %s has version %s, supported versions are %s",
+				operator.Name, csvName, version, compatibility.supported.text))
+		}
+	}
+
+	image, err := rhdh.GetBackstageImage(ctx, r.Client, orchestrator.Spec.RHDHConfig)
+	if err != nil {
+		return err
+	}
+	if version, found := getImageVersion(image); found && !compatibilityMatrix.backstage.versions(*version) {
+		mismatches = append(mismatches, fmt.Sprintf("RHDH image %s has version %s, the orchestrator plugins %s support %s",
+			image, version, rhdh.PluginsVersion, compatibilityMatrix.backstage.text))
+	}
+
+	condition := metav1.Condition{
+		Type:               TypeIncompatibleVersions,
+		Status:             metav1.ConditionFalse,
+		Reason:             ReasonVersionsCompatible,
+		Message:            fmt.Sprintf("The installed versions are supported by the orchestrator plugins %s", rhdh.PluginsVersion),
+		ObservedGeneration: orchestrator.Generation,
+	}
+	if len(mismatches) > 0 {
+		condition.Status = metav1.ConditionTrue
+		condition.Reason = ReasonVersionMismatch
+		condition.Message = strings.Join(mismatches, "; ")
+	}
+	meta.SetStatusCondition(&orchestrator.Status.Conditions, condition)
+	return nil
+}
+
+// getOperatorVersion returns the ClusterServiceVersion of the operator and its version: the one installed by the
+// Subscription of a managed operator, or the one detected for an external operator. The version is nil until known.
+func getOperatorVersion(
+	ctx context.Context, installedCSVs *kube.InstalledCSVs, operator orchestratorv1alpha2.OperatorStatus,
+	detection kube.OperatorDetection) (string, *semver.Version, error) {
+	if operator.Source == orchestratorv1alpha2.OperatorSourceExternal {
+		if operator.Version == "" {
+			return "", nil, nil
+		}
+		version, err := semver.Parse(operator.Version)
+		if err != nil {
+			return "", nil, nil
+		}
+		return kube.FormatObjectName(operator.Namespace, operator.CSV), &version, nil
+	}
+
+	csv, err := installedCSVs.GetInstalledCSV(ctx, detection.SubscriptionNamespace, detection.SubscriptionName)
+	if err != nil || csv == nil {
+		return "", nil, err
+	}
+	version := csv.Spec.Version.Version
+	return kube.FormatObjectName(csv.Namespace, csv.Name), &version, nil
+}
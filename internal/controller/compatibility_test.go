@@ -0,0 +1,197 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/blang/semver/v4"
+	"github.com/operator-framework/api/pkg/lib/version"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	olmclientsetfake "github.com/operator-framework/operator-lifecycle-manager/pkg/api/client/clientset/versioned/fake"
+	orchestratorv1alpha2 "github.com/rhdhorchestrator/orchestrator-operator/api/v1alpha3"
+	"github.com/rhdhorchestrator/orchestrator-operator/internal/controller/kube"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestGetInstallPlanPolicy(t *testing.T) {
+	testCases := []struct {
+		name        string
+		enforce     bool
+		csvNames    []string
+		expectNil   bool
+		expectedErr string
+	}{
+		{
+			name:      "Compatibility is not enforced",
+			csvNames:  []string{"serverless-operator.v1.36.0"},
+			expectNil: true,
+		},
+		{
+			name:     "Supported version is approved",
+			enforce:  true,
+			csvNames: []string{"serverless-operator.v1.35.1"},
+		},
+		{
+			name:        "Unsupported version is refused",
+			enforce:     true,
+			csvNames:    []string{"serverless-operator.v1.36.0"},
+			expectedErr: "serverless-operator.v1.36.0 is not in the supported versions 1.35.x of the Knative operator",
+		},
+		{
+			name:        "Unknown version is refused",
+			enforce:     true,
+			csvNames:    []string{"serverless-operator"},
+			expectedErr: "the version of serverless-operator is unknown",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			orchestrator := newTestOrchestrator()
+			orchestrator.Spec.EnforceCompatibility = tc.enforce
+			policy := getInstallPlanPolicy(orchestrator, knativeOperatorDetection)
+			if tc.expectNil {
+				assert.Nil(t, policy)
+				return
+			}
+			err := policy(tc.csvNames)
+			if tc.expectedErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorContains(t, err, tc.expectedErr)
+			}
+		})
+	}
+}
+
+func TestGetImageVersion(t *testing.T) {
+	testCases := []struct {
+		image           string
+		expectedVersion string
+	}{
+		{image: "registry.redhat.io/rhdh/rhdh-hub-rhel9:1.5", expectedVersion: "1.5.0"},
+		{image: "registry.redhat.io/rhdh/rhdh-hub-rhel9:1.4-123", expectedVersion: "1.4.0"},
+		{image: "quay.io/rhdh/rhdh-hub-rhel9:v1.5.1", expectedVersion: "1.5.1"},
+		{image: "quay.io/rhdh/rhdh-hub-rhel9:next"},
+		{image: "quay.io/rhdh/rhdh-hub-rhel9@sha256:0123456789abcdef"},
+		{image: "localhost:5000/rhdh-hub-rhel9"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.image, func(t *testing.T) {
+			imageVersion, found := getImageVersion(tc.image)
+			if tc.expectedVersion == "" {
+				assert.False(t, found)
+				return
+			}
+			assert.True(t, found)
+			assert.Equal(t, tc.expectedVersion, imageVersion.String())
+		})
+	}
+}
+
+func TestCheckCompatibility(t *testing.T) {
+	ctx := context.TODO()
+	scheme := runtime.NewScheme()
+	utilruntime.Must(appsv1.AddToScheme(scheme))
+	utilruntime.Must(orchestratorv1alpha2.AddToScheme(scheme))
+
+	newSubscription := func(csvName string) *v1alpha1.Subscription {
+		return &v1alpha1.Subscription{
+			ObjectMeta: metav1.ObjectMeta{Name: serverlessLogicOperatorDetection.SubscriptionName,
+				Namespace: serverlessLogicOperatorDetection.SubscriptionNamespace},
+			Status: v1alpha1.SubscriptionStatus{InstalledCSV: csvName},
+		}
+	}
+	newCSV := func(csvName, csvVersion string) *v1alpha1.ClusterServiceVersion {
+		return &v1alpha1.ClusterServiceVersion{
+			ObjectMeta: metav1.ObjectMeta{Name: csvName, Namespace: serverlessLogicOperatorDetection.SubscriptionNamespace},
+			Spec:       v1alpha1.ClusterServiceVersionSpec{Version: version.OperatorVersion{Version: semver.MustParse(csvVersion)}},
+		}
+	}
+	newDeployment := func(image string) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "backstage-my-rhdh", Namespace: testRHDHNamespace},
+			Spec: appsv1.DeploymentSpec{Template: corev1.PodTemplateSpec{Spec: corev1.PodSpec{
+				Containers: []corev1.Container{{Name: "backstage-backend", Image: image}}}}},
+		}
+	}
+	managed := orchestratorv1alpha2.OperatorStatus{Name: serverlessLogicOperatorDetection.Name,
+		Source: orchestratorv1alpha2.OperatorSourceManaged}
+
+	testCases := []struct {
+		name            string
+		operators       []orchestratorv1alpha2.OperatorStatus
+		objects         []client.Object
+		olmObjects      []runtime.Object
+		expectedStatus  metav1.ConditionStatus
+		expectedReason  string
+		expectedMessage string
+	}{
+		{
+			name:            "Nothing installed yet",
+			operators:       []orchestratorv1alpha2.OperatorStatus{managed},
+			expectedStatus:  metav1.ConditionFalse,
+			expectedReason:  ReasonVersionsCompatible,
+			expectedMessage: "The installed versions are supported by the orchestrator plugins 1.5.0-rc.2",
+		},
+		{
+			name:      "Supported versions",
+			operators: []orchestratorv1alpha2.OperatorStatus{managed},
+			objects:   []client.Object{newDeployment("registry.redhat.io/rhdh/rhdh-hub-rhel9:1.5-200")},
+			olmObjects: []runtime.Object{newSubscription("logic-operator-rhel8.v1.35.0"),
+				newCSV("logic-operator-rhel8.v1.35.0", "1.35.0")},
+			expectedStatus: metav1.ConditionFalse,
+			expectedReason: ReasonVersionsCompatible,
+		},
+		{
+			name:      "Managed operator mismatch",
+			operators: []orchestratorv1alpha2.OperatorStatus{managed},
+			olmObjects: []runtime.Object{newSubscription("logic-operator-rhel8.v1.36.0"),
+				newCSV("logic-operator-rhel8.v1.36.0", "1.36.0")},
+			expectedStatus: metav1.ConditionTrue,
+			expectedReason: ReasonVersionMismatch,
+			expectedMessage: "ServerlessLogic operator openshift-serverless-logic/logic-operator-rhel8.v1.36.0 " +
+				"has version 1.36.0, supported versions are 1.35.x",
+		},
+		{
+			name: "External operator and image mismatches",
+			operators: []orchestratorv1alpha2.OperatorStatus{{Name: knativeOperatorDetection.Name,
+				Source: orchestratorv1alpha2.OperatorSourceExternal, CSV: "serverless-operator.v1.34.0",
+				Namespace: knativeOperatorNamespace, Version: "1.34.0"}},
+			objects:        []client.Object{newDeployment("registry.redhat.io/rhdh/rhdh-hub-rhel9:1.4")},
+			expectedStatus: metav1.ConditionTrue,
+			expectedReason: ReasonVersionMismatch,
+			expectedMessage: "Knative operator openshift-serverless/serverless-operator.v1.34.0 has version 1.34.0, " +
+				"supported versions are 1.35.x; RHDH image registry.redhat.io/rhdh/rhdh-hub-rhel9:1.4 has version 1.4.0, " +
+				"the orchestrator plugins 1.5.0-rc.2 support 1.5.x",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			orchestrator := newTestOrchestrator()
+			orchestrator.Status.Operators = tc.operators
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(tc.objects...).Build()
+			r := &OrchestratorReconciler{Client: fakeClient, Scheme: scheme}
+			installedCSVs := kube.NewInstalledCSVs(olmclientsetfake.NewSimpleClientset(tc.olmObjects...))
+
+			assert.NoError(t, r.checkCompatibility(ctx, installedCSVs, orchestrator))
+			condition := meta.FindStatusCondition(orchestrator.Status.Conditions, TypeIncompatibleVersions)
+			assert.NotNil(t, condition)
+			assert.Equal(t, tc.expectedStatus, condition.Status)
+			assert.Equal(t, tc.expectedReason, condition.Reason)
+			if tc.expectedMessage != "" {
+				assert.Equal(t, tc.expectedMessage, condition.Message)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	orchestratorv1alpha2 "github.com/rhdhorchestrator/orchestrator-operator/api/v1alpha3"
+	"github.com/rhdhorchestrator/orchestrator-operator/internal/controller/database"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	TypeDatabaseReachable string = "DatabaseReachable"
+
+	ReasonDatabaseConnected    = "Connected"
+	ReasonAuthSecretNotFound   = "AuthSecretNotFound"
+	ReasonAuthSecretKeyMissing = "AuthSecretKeyMissing"
+	ReasonDatabaseCheckFailed  = "CheckFailed"
+
+	databasePreflightTimeout = 5 * time.Second
+)
+
+// getDatabaseHost returns the host of the PostgreSQL service within the cluster.
+func getDatabaseHost(postgresConfig orchestratorv1alpha2.PostgresConfig) string {
+	return fmt.Sprintf("%s.%s.svc", postgresConfig.Name, postgresConfig.Namespace)
+}
+
+// getDatabaseSelector returns the pod selector of the PostgreSQL service, or nil when the service does not exist yet.
+// The service of an external database is not cached, so reader reads the API server.
+func getDatabaseSelector(
+	ctx context.Context, reader client.Reader, postgresConfig orchestratorv1alpha2.PostgresConfig) (map[string]string, error) {
+	service := &corev1.Service{}
+	err := reader.Get(ctx, types.NamespacedName{Name: postgresConfig.Name, Namespace: postgresConfig.Namespace}, service)
+	if apierrors.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return service.Spec.Selector, nil
+}
+
+// getDatabaseCondition reads the PostgreSQL credentials from the auth secret and checks that they open a
+// connection to the database at host and port. The check is bounded by databasePreflightTimeout.
+func getDatabaseCondition(
+	ctx context.Context, k8client client.Client,
+	postgresConfig orchestratorv1alpha2.PostgresConfig, host string, port int) metav1.Condition {
+	address := net.JoinHostPort(host, strconv.Itoa(port))
+	condition := metav1.Condition{Type: TypeDatabaseReachable, Status: metav1.ConditionFalse}
+
+	authSecret := postgresConfig.AuthSecret
+	secret := &corev1.Secret{}
+	if err := k8client.Get(ctx, types.NamespacedName{Name: authSecret.SecretName, Namespace: postgresConfig.Namespace}, secret); err != nil {
+		condition.Reason = ReasonDatabaseCheckFailed
+		if apierrors.IsNotFound(err) {
+			condition.Reason = ReasonAuthSecretNotFound
+		}
+		condition.Message = fmt.Sprintf("Failed to read secret %s/%s: %s", postgresConfig.Namespace, authSecret.SecretName, err)
+		return condition
+	}
+
+	var missingKeys []string
+	for _, key := range []string{authSecret.UserKey, authSecret.PasswordKey} {
+		if len(secret.Data[key]) == 0 {
+			missingKeys = append(missingKeys, key)
+		}
+	}
+	if len(missingKeys) > 0 {
+		condition.Reason = ReasonAuthSecretKeyMissing
+		condition.Message = fmt.Sprintf("Secret %s/%s has no value for the keys %v",
+			postgresConfig.Namespace, authSecret.SecretName, missingKeys)
+		return condition
+	}
+
+	checkCtx, cancel := context.WithTimeout(ctx, databasePreflightTimeout)
+	defer cancel()
+	err := database.CheckConnection(checkCtx, database.ConnectionConfig{
+		Host:     host,
+		Port:     port,
+		User:     string(secret.Data[authSecret.UserKey]),
+		Password: string(secret.Data[authSecret.PasswordKey]),
+		Database: postgresConfig.DatabaseName,
+		SSLMode:  postgresConfig.SSLMode,
+	})
+	if err != nil {
+		condition.Reason = ReasonDatabaseCheckFailed
+		var connectionErr *database.ConnectionError
+		if errors.As(err, &connectionErr) {
+			condition.Reason = string(connectionErr.Reason)
+		}
+		condition.Message = fmt.Sprintf("Failed to connect to database %s on %s: %s", postgresConfig.DatabaseName, address, err)
+		return condition
+	}
+
+	condition.Status = metav1.ConditionTrue
+	condition.Reason = ReasonDatabaseConnected
+	condition.Message = fmt.Sprintf("Connected to database %s on %s", postgresConfig.DatabaseName, address)
+	return condition
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package database checks that a PostgreSQL database accepts the configured credentials.
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type FailureReason string
+
+const (
+	ReasonUnreachable          FailureReason = "DatabaseUnreachable"
+	ReasonAuthenticationFailed FailureReason = "AuthenticationFailed"
+	ReasonDatabaseNotFound     FailureReason = "DatabaseNotFound"
+	ReasonConnectionFailed     FailureReason = "ConnectionFailed"
+
+	// DefaultSSLMode uses TLS when the server supports it
+	DefaultSSLMode = "prefer"
+
+	// SQLSTATE codes reported in ErrorResponse messages
+	sqlStateInvalidPassword      = "28P01"
+	sqlStateInvalidAuthorization = "28000"
+	sqlStateInvalidCatalogName   = "3D000"
+)
+
+// ConnectionConfig describes the database to connect to.
+type ConnectionConfig struct {
+	Host     string
+	Port     int
+	User     string
+	Password string
+	Database string
+	// SSLMode is the libpq sslmode of the connection, such as disable, prefer or require
+	SSLMode string
+}
+
+// ConnectionError reports why the database could not be reached.
+type ConnectionError struct {
+	Reason FailureReason
+	Err    error
+}
+
+func (e *ConnectionError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ConnectionError) Unwrap() error {
+	return e.Err
+}
+
+// CheckConnection connects and authenticates to the database, then closes the connection.
+// The deadline of ctx bounds the whole exchange.
+func CheckConnection(ctx context.Context, config ConnectionConfig) error {
+	connConfig, err := getConnConfig(config)
+	if err != nil {
+		return &ConnectionError{Reason: ReasonConnectionFailed, Err: err}
+	}
+	conn, err := pgconn.ConnectConfig(ctx, connConfig)
+	if err != nil {
+		return classify(err)
+	}
+	return conn.Close(ctx)
+}
+
+// getConnConfig parses the connection settings, so that the TLS configuration of the sslmode is applied, then sets
+// the credentials which are not safe to quote in a connection string.
+func getConnConfig(config ConnectionConfig) (*pgconn.Config, error) {
+	sslMode := config.SSLMode
+	if sslMode == "" {
+		sslMode = DefaultSSLMode
+	}
+	connConfig, err := pgconn.ParseConfig(fmt.Sprintf("host=%s port=%d sslmode=%s", config.Host, config.Port, sslMode))
+	if err != nil {
+		return nil, err
+	}
+	connConfig.User = config.User
+	connConfig.Password = config.Password
+	connConfig.Database = config.Database
+	return connConfig, nil
+}
+
+func classify(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case sqlStateInvalidPassword, sqlStateInvalidAuthorization:
+			return &ConnectionError{Reason: ReasonAuthenticationFailed, Err: err}
+		case sqlStateInvalidCatalogName:
+			return &ConnectionError{Reason: ReasonDatabaseNotFound, Err: err}
+		}
+		return &ConnectionError{Reason: ReasonConnectionFailed, Err: err}
+	}
+	var netErr net.Error
+	if errors.As(err, &netErr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		errors.Is(err, context.DeadlineExceeded) || pgconn.Timeout(err) {
+		return &ConnectionError{Reason: ReasonUnreachable, Err: err}
+	}
+	return &ConnectionError{Reason: ReasonConnectionFailed, Err: err}
+}
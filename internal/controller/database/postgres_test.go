@@ -0,0 +1,117 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckConnectionUnreachable(t *testing.T) {
+	// a closed listener gives an address which refuses connections
+	closedListener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	port := closedListener.Addr().(*net.TCPAddr).Port
+	assert.NoError(t, closedListener.Close())
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 5*time.Second)
+	defer cancel()
+	err = CheckConnection(ctx, ConnectionConfig{Host: "127.0.0.1", Port: port, User: "sonataflow", Database: "sonataflow"})
+	var connectionErr *ConnectionError
+	assert.True(t, errors.As(err, &connectionErr))
+	assert.Equal(t, ReasonUnreachable, connectionErr.Reason)
+}
+
+func TestCheckConnectionTimeout(t *testing.T) {
+	// a listener which never answers the startup message
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	defer listener.Close()
+
+	ctx, cancel := context.WithTimeout(context.TODO(), 200*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	err = CheckConnection(ctx, ConnectionConfig{
+		Host: "127.0.0.1", Port: listener.Addr().(*net.TCPAddr).Port, User: "sonataflow", Database: "sonataflow"})
+	var connectionErr *ConnectionError
+	assert.True(t, errors.As(err, &connectionErr))
+	assert.Equal(t, ReasonUnreachable, connectionErr.Reason)
+	assert.Less(t, time.Since(start), 2*time.Second)
+}
+
+func TestGetConnConfig(t *testing.T) {
+	testCases := []struct {
+		sslMode           string
+		expectTLS         bool
+		expectedFallbacks int
+	}{
+		{sslMode: "", expectTLS: true, expectedFallbacks: 1},
+		{sslMode: "prefer", expectTLS: true, expectedFallbacks: 1},
+		{sslMode: "require", expectTLS: true},
+		{sslMode: "disable"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.sslMode, func(t *testing.T) {
+			connConfig, err := getConnConfig(ConnectionConfig{Host: "postgres.sonataflow-infra.svc", Port: 5432,
+				User: "sonataflow", Password: "p@ss word='", Database: "sonataflow", SSLMode: tc.sslMode})
+			assert.NoError(t, err)
+			assert.Equal(t, "postgres.sonataflow-infra.svc", connConfig.Host)
+			assert.Equal(t, uint16(5432), connConfig.Port)
+			assert.Equal(t, "p@ss word='", connConfig.Password)
+			assert.Equal(t, tc.expectTLS, connConfig.TLSConfig != nil)
+			assert.Len(t, connConfig.Fallbacks, tc.expectedFallbacks)
+			for _, fallback := range connConfig.Fallbacks {
+				assert.Nil(t, fallback.TLSConfig, "The fallback of prefer is a plain connection")
+			}
+		})
+	}
+}
+
+func TestClassify(t *testing.T) {
+	testCases := []struct {
+		name           string
+		err            error
+		expectedReason FailureReason
+	}{
+		{
+			name:           "Wrong password",
+			err:            &pgconn.PgError{Code: "28P01", Message: "password authentication failed"},
+			expectedReason: ReasonAuthenticationFailed,
+		},
+		{
+			name:           "Unknown user",
+			err:            &pgconn.PgError{Code: "28000", Message: "no pg_hba.conf entry"},
+			expectedReason: ReasonAuthenticationFailed,
+		},
+		{
+			name:           "Missing database",
+			err:            fmt.Errorf("connect: %w", &pgconn.PgError{Code: "3D000", Message: "database does not exist"}),
+			expectedReason: ReasonDatabaseNotFound,
+		},
+		{
+			name:           "Other server error",
+			err:            &pgconn.PgError{Code: "53300", Message: "too many connections"},
+			expectedReason: ReasonConnectionFailed,
+		},
+		{
+			name:           "Deadline exceeded",
+			err:            fmt.Errorf("connect: %w", context.DeadlineExceeded),
+			expectedReason: ReasonUnreachable,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var connectionErr *ConnectionError
+			assert.True(t, errors.As(classify(tc.err), &connectionErr))
+			assert.Equal(t, tc.expectedReason, connectionErr.Reason)
+		})
+	}
+}
@@ -0,0 +1,75 @@
+package controller
+
+import (
+	"context"
+	"net"
+	"testing"
+
+	"github.com/rhdhorchestrator/orchestrator-operator/internal/controller/database"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestGetDatabaseCondition(t *testing.T) {
+	ctx := context.TODO()
+	scheme := runtime.NewScheme()
+	utilruntime.Must(corev1.AddToScheme(scheme))
+
+	// a closed listener gives an address which refuses connections
+	closedListener, err := net.Listen("tcp", "127.0.0.1:0")
+	assert.NoError(t, err)
+	port := closedListener.Addr().(*net.TCPAddr).Port
+	assert.NoError(t, closedListener.Close())
+
+	postgresConfig := newTestSonataFlowOrchestrator().Spec.PostgresConfig
+	authSecret := func(user, password string) *corev1.Secret {
+		return &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: postgresConfig.AuthSecret.SecretName, Namespace: testNamespace},
+			Data: map[string][]byte{
+				postgresConfig.AuthSecret.UserKey:     []byte(user),
+				postgresConfig.AuthSecret.PasswordKey: []byte(password),
+			},
+		}
+	}
+
+	testCases := []struct {
+		name           string
+		existing       []client.Object
+		expectedStatus metav1.ConditionStatus
+		expectedReason string
+	}{
+		{
+			name:           "Unreachable database",
+			existing:       []client.Object{authSecret("sonataflow", "secret")},
+			expectedStatus: metav1.ConditionFalse,
+			expectedReason: string(database.ReasonUnreachable),
+		},
+		{
+			name:           "Missing secret",
+			expectedStatus: metav1.ConditionFalse,
+			expectedReason: ReasonAuthSecretNotFound,
+		},
+		{
+			name:           "Missing password key",
+			existing:       []client.Object{authSecret("sonataflow", "")},
+			expectedStatus: metav1.ConditionFalse,
+			expectedReason: ReasonAuthSecretKeyMissing,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(tc.existing...).Build()
+
+			condition := getDatabaseCondition(ctx, fakeClient, postgresConfig, "127.0.0.1", port)
+			assert.Equal(t, TypeDatabaseReachable, condition.Type)
+			assert.Equal(t, tc.expectedStatus, condition.Status)
+			assert.Equal(t, tc.expectedReason, condition.Reason)
+		})
+	}
+}
@@ -0,0 +1,275 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	orchestratorv1alpha2 "github.com/rhdhorchestrator/orchestrator-operator/api/v1alpha3"
+	"github.com/rhdhorchestrator/orchestrator-operator/internal/controller/kube"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// DeletionPreviewAnnotation holds the deletion of the Orchestrator, and lists the objects it would remove
+	DeletionPreviewAnnotation = "rhdh.redhat.com/deletion-preview"
+
+	TypeDeletionPreview string = "DeletionPreview"
+	TypeDeleting        string = "Deleting"
+
+	ReasonNamespaceStuckTerminating = "NamespaceStuckTerminating"
+
+	// DeletionRequeueAfterTime is the interval at which the progress of the deletion is checked
+	DeletionRequeueAfterTime = 10 * time.Second
+	// NamespaceStuckTimeout is the time after which a namespace still terminating is reported as stuck
+	NamespaceStuckTimeout = 5 * time.Minute
+)
+
+// deletionStage selects the inventory entries deleted together. A stage starts once the objects of the
+// previous stage are gone.
+type deletionStage struct {
+	description string
+	reason      string
+	selected    func(entry orchestratorv1alpha2.InventoryEntry) bool
+}
+
+// isOperatorEntry reports whether the inventory entry installs an operator: a Subscription or an OperatorGroup.
+func isOperatorEntry(entry orchestratorv1alpha2.InventoryEntry) bool {
+	return strings.HasPrefix(entry.APIVersion, operatorsv1alpha1.GroupName+"/")
+}
+
+// deletionStages deletes the operands first, while their operators are still running to process their
+// finalizers, then the operators with their ClusterServiceVersions, and the namespaces last.
+var deletionStages = []deletionStage{
+	{
+		description: "operands",
+		reason:      "DeletingOperands",
+		selected: func(entry orchestratorv1alpha2.InventoryEntry) bool {
+			return !isOperatorEntry(entry) && !kube.IsNamespaceEntry(entry)
+		},
+	},
+	{description: "operators", reason: "DeletingOperators", selected: isOperatorEntry},
+	{description: "namespaces", reason: "DeletingNamespaces", selected: kube.IsNamespaceEntry},
+}
+
+func getDeletionPolicy(orchestrator *orchestratorv1alpha2.Orchestrator) orchestratorv1alpha2.DeletionPolicy {
+	if orchestrator.Spec.DeletionPolicy == "" {
+		return orchestratorv1alpha2.DeletionPolicyDelete
+	}
+	return orchestrator.Spec.DeletionPolicy
+}
+
+// isDataEntry reports whether the inventory entry holds workflows or their data: the workflow and PostgreSQL
+// namespaces, the secrets and the volume claims.
+func isDataEntry(orchestrator *orchestratorv1alpha2.Orchestrator, entry orchestratorv1alpha2.InventoryEntry) bool {
+	if entry.APIVersion != "v1" {
+		return false
+	}
+	switch entry.Kind {
+	case "Namespace":
+		return entry.Name == orchestrator.Spec.PostgresConfig.Namespace ||
+			slices.Contains(getWorkflowNamespaces(orchestrator.Spec.PlatformConfig), entry.Name)
+	case "Secret", "PersistentVolumeClaim":
+		return true
+	}
+	return false
+}
+
+// getDeletionSelector selects the inventory entries removed by the deletion of the orchestrator.
+func getDeletionSelector(orchestrator *orchestratorv1alpha2.Orchestrator) func(entry orchestratorv1alpha2.InventoryEntry) bool {
+	postgresConfig := orchestrator.Spec.PostgresConfig
+	switch getDeletionPolicy(orchestrator) {
+	case orchestratorv1alpha2.DeletionPolicyOrphan:
+		return func(orchestratorv1alpha2.InventoryEntry) bool { return false }
+	case orchestratorv1alpha2.DeletionPolicyRetainData:
+		return func(entry orchestratorv1alpha2.InventoryEntry) bool { return !isDataEntry(orchestrator, entry) }
+	default:
+		return func(entry orchestratorv1alpha2.InventoryEntry) bool {
+			return !isRetainedPostgresEntry(postgresConfig, entry)
+		}
+	}
+}
+
+// deletesPostgresVolumes reports whether the deletion of the orchestrator removes the data volumes of the
+// provisioned PostgreSQL, which are created by its StatefulSet outside of the inventory.
+func deletesPostgresVolumes(orchestrator *orchestratorv1alpha2.Orchestrator) bool {
+	postgresConfig := orchestrator.Spec.PostgresConfig
+	return getDeletionPolicy(orchestrator) == orchestratorv1alpha2.DeletionPolicyDelete &&
+		postgresConfig.Provision && postgresConfig.RetentionPolicy == orchestratorv1alpha2.PostgresDeletePolicy
+}
+
+// getDeletionPreview returns the objects which the deletion of the orchestrator removes, in the order of removal.
+func getDeletionPreview(
+	ctx context.Context, k8client *kube.InventoryClient,
+	orchestrator *orchestratorv1alpha2.Orchestrator) ([]orchestratorv1alpha2.InventoryEntry, error) {
+	preview := k8client.SelectInventory(getDeletionSelector(orchestrator))
+	if !deletesPostgresVolumes(orchestrator) {
+		return preview, nil
+	}
+	postgresConfig := orchestrator.Spec.PostgresConfig
+	volumeClaims := &corev1.PersistentVolumeClaimList{}
+	if err := k8client.List(ctx, volumeClaims, client.InNamespace(postgresConfig.Namespace),
+		client.MatchingLabels(getPostgresLabels(postgresConfig))); err != nil {
+		return nil, err
+	}
+	for _, volumeClaim := range volumeClaims.Items {
+		preview = append(preview, orchestratorv1alpha2.InventoryEntry{
+			APIVersion: "v1", Kind: "PersistentVolumeClaim", Namespace: volumeClaim.Namespace, Name: volumeClaim.Name})
+	}
+	return preview, nil
+}
+
+// previewCleanUp lists the objects which the deletion of the orchestrator would remove in its status, and in
+// an Event when the list changes. Nothing is deleted until the DeletionPreviewAnnotation is removed.
+func (r *OrchestratorReconciler) previewCleanUp(ctx context.Context, orchestrator *orchestratorv1alpha2.Orchestrator) error {
+	logger := log.FromContext(ctx)
+	k8client := kube.NewInventoryClient(r.Client, r.APIReader, r.OLMClient, r.Recorder, orchestrator)
+	preview, err := getDeletionPreview(ctx, k8client, orchestrator)
+	if err != nil {
+		logger.Error(err, "Error occurred when listing the objects removed by the deletion")
+		return err
+	}
+
+	if !equality.Semantic.DeepEqual(orchestrator.Status.DeletionPreview, preview) {
+		r.Recorder.Eventf(orchestrator, corev1.EventTypeNormal, "DeletionPreview",
+			"Deletion with the %s policy would remove %d objects: %s",
+			getDeletionPolicy(orchestrator), len(preview), formatInventoryEntries(preview))
+	}
+	orchestrator.Status.DeletionPreview = preview
+	return r.UpdateStatus(ctx, orchestrator, orchestrator.Status.Phase, metav1.Condition{
+		Type:   TypeDeletionPreview,
+		Status: metav1.ConditionTrue,
+		Reason: "PreviewRequested",
+		Message: fmt.Sprintf("Deletion with the %s policy would remove %d objects; remove the %s annotation to proceed",
+			getDeletionPolicy(orchestrator), len(preview), DeletionPreviewAnnotation),
+	})
+}
+
+func formatInventoryEntries(entries []orchestratorv1alpha2.InventoryEntry) string {
+	objects := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		objects = append(objects, entry.Kind+" "+kube.FormatObjectName(entry.Namespace, entry.Name))
+	}
+	return strings.Join(objects, ", ")
+}
+
+// handleCleanUp deletes the inventory selected by the deletion policy, one stage of deletionStages at a time.
+// While a stage waits for its objects to go away, it returns the Deleting condition reporting the progress;
+// it returns nil once the clean up is complete.
+func (r *OrchestratorReconciler) handleCleanUp(
+	ctx context.Context, orchestrator *orchestratorv1alpha2.Orchestrator) (*metav1.Condition, error) {
+	k8client := kube.NewInventoryClient(r.Client, r.APIReader, r.OLMClient, r.Recorder, orchestrator)
+	deletionSelector := getDeletionSelector(orchestrator)
+	for i, stage := range deletionStages {
+		// within a stage, the inventory is deleted in the reverse order of creation: the Broker goes before
+		// Knative, and the platform services before the provisioned PostgreSQL
+		pending, err := k8client.DeleteInventory(ctx, func(entry orchestratorv1alpha2.InventoryEntry) bool {
+			return deletionSelector(entry) && stage.selected(entry)
+		})
+		if err != nil {
+			return nil, err
+		}
+		if len(pending) == 0 {
+			continue
+		}
+
+		condition := &metav1.Condition{
+			Type:   TypeDeleting,
+			Status: metav1.ConditionTrue,
+			Reason: stage.reason,
+			Message: fmt.Sprintf("Stage %d/%d: waiting for %d %s to be deleted: %s",
+				i+1, len(deletionStages), len(pending), stage.description, formatInventoryEntries(pending)),
+		}
+		if stage.reason == "DeletingNamespaces" {
+			stuckNamespaces, err := getStuckNamespaces(ctx, k8client, pending)
+			if err != nil {
+				return nil, err
+			}
+			if len(stuckNamespaces) > 0 {
+				condition.Reason = ReasonNamespaceStuckTerminating
+				condition.Message = fmt.Sprintf("Stage %d/%d: namespaces terminating for more than %s: %s",
+					i+1, len(deletionStages), NamespaceStuckTimeout, strings.Join(stuckNamespaces, ", "))
+			}
+		}
+		// the progress is reported once per stage, and once when the namespaces are found stuck
+		previous := meta.FindStatusCondition(orchestrator.Status.Conditions, TypeDeleting)
+		if previous == nil || previous.Reason != condition.Reason {
+			eventType := corev1.EventTypeNormal
+			if condition.Reason == ReasonNamespaceStuckTerminating {
+				eventType = corev1.EventTypeWarning
+			}
+			r.Recorder.Event(orchestrator, eventType, condition.Reason, condition.Message)
+		}
+		return condition, nil
+	}
+
+	// the objects kept by the deletion policy must survive the garbage collection of the orchestrator
+	if err := k8client.OrphanInventory(ctx, func(orchestratorv1alpha2.InventoryEntry) bool { return true }); err != nil {
+		return nil, err
+	}
+	if deletesPostgresVolumes(orchestrator) {
+		// the data volumes of the provisioned PostgreSQL are created by its StatefulSet, outside of the inventory
+		if err := handlePostgresCleanUp(ctx, k8client, orchestrator.Spec.PostgresConfig); err != nil {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+// getStuckNamespaces returns the namespaces of entries terminating for longer than NamespaceStuckTimeout, with
+// the reasons reported by their conditions: the remaining finalizers or content.
+func getStuckNamespaces(
+	ctx context.Context, k8client client.Client, entries []orchestratorv1alpha2.InventoryEntry) ([]string, error) {
+	var stuckNamespaces []string
+	for _, entry := range entries {
+		namespace := &corev1.Namespace{}
+		if err := k8client.Get(ctx, types.NamespacedName{Name: entry.Name}, namespace); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		if namespace.DeletionTimestamp == nil || time.Since(namespace.DeletionTimestamp.Time) < NamespaceStuckTimeout {
+			continue
+		}
+		var reasons []string
+		for _, condition := range namespace.Status.Conditions {
+			if condition.Status == corev1.ConditionTrue && (condition.Type == corev1.NamespaceFinalizersRemaining ||
+				condition.Type == corev1.NamespaceContentRemaining) {
+				reasons = append(reasons, condition.Message)
+			}
+		}
+		if len(reasons) == 0 {
+			stuckNamespaces = append(stuckNamespaces, namespace.Name)
+		} else {
+			stuckNamespaces = append(stuckNamespaces, fmt.Sprintf("%s (%s)", namespace.Name, strings.Join(reasons, "; ")))
+		}
+	}
+	return stuckNamespaces, nil
+}
@@ -0,0 +1,230 @@
+package controller
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	orchestratorv1alpha2 "github.com/rhdhorchestrator/orchestrator-operator/api/v1alpha3"
+	"github.com/rhdhorchestrator/orchestrator-operator/internal/controller/kube"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+// newTestDeletionObjects returns an orchestrator with an inventory of a workflow namespace, a secret and a
+// ConfigMap owned by the orchestrator, and the inventory objects.
+func newTestDeletionObjects() (*orchestratorv1alpha2.Orchestrator, []client.Object) {
+	orchestrator := newTestSonataFlowOrchestrator()
+	orchestrator.UID = "orchestrator-uid"
+	orchestrator.Spec.PlatformConfig.Namespace = "workflows"
+	orchestrator.Status.Inventory = []orchestratorv1alpha2.InventoryEntry{
+		{APIVersion: "v1", Kind: "Namespace", Name: "workflows"},
+		{APIVersion: "v1", Kind: "Secret", Namespace: testNamespace, Name: "generated"},
+		{APIVersion: "v1", Kind: "ConfigMap", Namespace: testNamespace, Name: "rendered"},
+	}
+	ownerReference := metav1.OwnerReference{
+		APIVersion: orchestratorv1alpha2.GroupVersion.String(), Kind: "Orchestrator", Name: orchestrator.Name, UID: orchestrator.UID}
+	objects := []client.Object{
+		&corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "workflows", Labels: kube.AddLabel()}},
+		&corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+			Name: "generated", Namespace: testNamespace, OwnerReferences: []metav1.OwnerReference{ownerReference}}},
+		&corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+			Name: "rendered", Namespace: testNamespace, OwnerReferences: []metav1.OwnerReference{ownerReference}}},
+	}
+	return orchestrator, objects
+}
+
+func TestHandleCleanUpDeletionPolicy(t *testing.T) {
+	ctx := context.TODO()
+	scheme := runtime.NewScheme()
+	utilruntime.Must(corev1.AddToScheme(scheme))
+	utilruntime.Must(orchestratorv1alpha2.AddToScheme(scheme))
+
+	// workflows is the workflow namespace, rhdh another namespace, generated the secret and rendered the ConfigMap
+	// of the inventory
+	testCases := []struct {
+		name           string
+		deletionPolicy orchestratorv1alpha2.DeletionPolicy
+		expectedKept   []string
+	}{
+		{
+			name:           "Delete removes the inventory",
+			deletionPolicy: orchestratorv1alpha2.DeletionPolicyDelete,
+		},
+		{
+			name:           "Orphan keeps the inventory",
+			deletionPolicy: orchestratorv1alpha2.DeletionPolicyOrphan,
+			expectedKept:   []string{"workflows", "rhdh", "generated", "rendered"},
+		},
+		{
+			name:           "RetainData keeps the workflow namespaces and secrets",
+			deletionPolicy: orchestratorv1alpha2.DeletionPolicyRetainData,
+			expectedKept:   []string{"workflows", "generated"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			orchestrator, objects := newTestDeletionObjects()
+			orchestrator.Spec.DeletionPolicy = tc.deletionPolicy
+			orchestrator.Status.Inventory = append(orchestrator.Status.Inventory,
+				orchestratorv1alpha2.InventoryEntry{APIVersion: "v1", Kind: "Namespace", Name: "rhdh"})
+			objects = append(objects, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "rhdh", Labels: kube.AddLabel()}})
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+			r := &OrchestratorReconciler{Client: fakeClient, Scheme: scheme}
+
+			condition, err := r.handleCleanUp(ctx, orchestrator)
+			assert.NoError(t, err)
+			assert.Nil(t, condition, "The clean up is complete")
+			for _, object := range objects {
+				err := fakeClient.Get(ctx, client.ObjectKeyFromObject(object), object)
+				if slices.Contains(tc.expectedKept, object.GetName()) {
+					assert.NoError(t, err, object.GetName())
+					assert.Empty(t, object.GetOwnerReferences(), "%s is orphaned", object.GetName())
+				} else {
+					assert.True(t, apierrors.IsNotFound(err), object.GetName())
+				}
+			}
+		})
+	}
+}
+
+func TestHandleCleanUpStages(t *testing.T) {
+	ctx := context.TODO()
+	scheme := runtime.NewScheme()
+	utilruntime.Must(corev1.AddToScheme(scheme))
+	utilruntime.Must(orchestratorv1alpha2.AddToScheme(scheme))
+
+	orchestrator, objects := newTestDeletionObjects()
+	// the operand is held by the finalizer of its operator
+	rendered := objects[2].(*corev1.ConfigMap)
+	rendered.Finalizers = []string{"example.com/operand"}
+	workflows := objects[0].(*corev1.Namespace)
+	workflows.Finalizers = []string{"example.com/content"}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+	recorder := record.NewFakeRecorder(10)
+	r := &OrchestratorReconciler{Client: fakeClient, Scheme: scheme, Recorder: recorder}
+
+	condition, err := r.handleCleanUp(ctx, orchestrator)
+	assert.NoError(t, err)
+	assert.Equal(t, "DeletingOperands", condition.Reason)
+	assert.Equal(t, "Stage 1/3: waiting for 1 operands to be deleted: ConfigMap "+testNamespace+"/rendered", condition.Message)
+	namespace := &corev1.Namespace{}
+	assert.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: "workflows"}, namespace))
+	assert.Nil(t, namespace.DeletionTimestamp, "The namespaces wait for the operands")
+
+	assert.NoError(t, fakeClient.Get(ctx, client.ObjectKeyFromObject(rendered), rendered))
+	rendered.Finalizers = nil
+	assert.NoError(t, fakeClient.Update(ctx, rendered))
+	condition, err = r.handleCleanUp(ctx, orchestrator)
+	assert.NoError(t, err)
+	assert.Equal(t, "DeletingNamespaces", condition.Reason)
+	assert.Contains(t, condition.Message, "Stage 3/3: waiting for 1 namespaces")
+
+	var reasons []string
+	for len(recorder.Events) > 0 {
+		// fake recorder events are formatted as "<type> <reason> <message>"
+		reasons = append(reasons, strings.Fields(<-recorder.Events)[1])
+	}
+	assert.Equal(t, []string{"SecretDeleted", "DeletingOperands", "ConfigMapDeleted", "DeletingNamespaces"}, reasons)
+
+}
+
+func TestHandleCleanUpStuckNamespace(t *testing.T) {
+	ctx := context.TODO()
+	scheme := runtime.NewScheme()
+	utilruntime.Must(corev1.AddToScheme(scheme))
+	utilruntime.Must(orchestratorv1alpha2.AddToScheme(scheme))
+
+	orchestrator := newTestSonataFlowOrchestrator()
+	orchestrator.Status.Inventory = []orchestratorv1alpha2.InventoryEntry{{APIVersion: "v1", Kind: "Namespace", Name: "workflows"}}
+	terminatingSince := metav1.NewTime(time.Now().Add(-NamespaceStuckTimeout - time.Minute))
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "workflows", Labels: kube.AddLabel(), DeletionTimestamp: &terminatingSince,
+			Finalizers: []string{"example.com/content"}},
+	}
+	// unlike the fake client, the API server keeps the deletion timestamp of the objects already terminating
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(namespace).WithInterceptorFuncs(interceptor.Funcs{
+		Delete: func(context.Context, client.WithWatch, client.Object, ...client.DeleteOption) error { return nil },
+	}).Build()
+	recorder := record.NewFakeRecorder(10)
+	r := &OrchestratorReconciler{Client: fakeClient, Scheme: scheme, Recorder: recorder}
+
+	condition, err := r.handleCleanUp(ctx, orchestrator)
+	assert.NoError(t, err)
+	assert.Equal(t, ReasonNamespaceStuckTerminating, condition.Reason)
+	assert.Contains(t, condition.Message, "workflows")
+	meta.SetStatusCondition(&orchestrator.Status.Conditions, *condition)
+
+	_, err = r.handleCleanUp(ctx, orchestrator)
+	assert.NoError(t, err)
+	assert.Len(t, recorder.Events, 1, "The stuck namespace is reported once")
+	assert.Contains(t, <-recorder.Events, "Warning "+ReasonNamespaceStuckTerminating)
+}
+
+func TestGetStuckNamespaces(t *testing.T) {
+	ctx := context.TODO()
+	scheme := runtime.NewScheme()
+	utilruntime.Must(corev1.AddToScheme(scheme))
+
+	terminatingSince := metav1.NewTime(time.Now().Add(-NamespaceStuckTimeout - time.Minute))
+	stuck := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "stuck", DeletionTimestamp: &terminatingSince, Finalizers: []string{"example.com/content"}},
+		Status: corev1.NamespaceStatus{Conditions: []corev1.NamespaceCondition{
+			{Type: corev1.NamespaceFinalizersRemaining, Status: corev1.ConditionTrue, Message: "Some content has finalizers remaining"},
+			{Type: corev1.NamespaceDeletionDiscoveryFailure, Status: corev1.ConditionFalse, Message: "All resources discovered"},
+		}},
+	}
+	terminating := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{
+		Name: "terminating", DeletionTimestamp: &metav1.Time{Time: time.Now()}, Finalizers: []string{"example.com/content"}}}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(stuck, terminating).Build()
+
+	stuckNamespaces, err := getStuckNamespaces(ctx, fakeClient, []orchestratorv1alpha2.InventoryEntry{
+		{APIVersion: "v1", Kind: "Namespace", Name: "stuck"},
+		{APIVersion: "v1", Kind: "Namespace", Name: "terminating"},
+		{APIVersion: "v1", Kind: "Namespace", Name: "deleted"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"stuck (Some content has finalizers remaining)"}, stuckNamespaces)
+}
+
+func TestPreviewCleanUp(t *testing.T) {
+	ctx := context.TODO()
+	scheme := runtime.NewScheme()
+	utilruntime.Must(corev1.AddToScheme(scheme))
+	utilruntime.Must(orchestratorv1alpha2.AddToScheme(scheme))
+
+	orchestrator, objects := newTestDeletionObjects()
+	orchestrator.Spec.DeletionPolicy = orchestratorv1alpha2.DeletionPolicyRetainData
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(append(objects, orchestrator)...).WithStatusSubresource(orchestrator).Build()
+	recorder := record.NewFakeRecorder(10)
+	r := &OrchestratorReconciler{Client: fakeClient, Scheme: scheme, Recorder: recorder}
+
+	assert.NoError(t, r.previewCleanUp(ctx, orchestrator))
+	assert.NoError(t, r.previewCleanUp(ctx, orchestrator))
+	assert.Len(t, recorder.Events, 1, "The preview is reported once")
+	assert.Contains(t, <-recorder.Events, "would remove 1 objects: ConfigMap "+testNamespace+"/rendered")
+
+	updated := &orchestratorv1alpha2.Orchestrator{}
+	assert.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: orchestrator.Name, Namespace: orchestrator.Namespace}, updated))
+	assert.Equal(t, []orchestratorv1alpha2.InventoryEntry{
+		{APIVersion: "v1", Kind: "ConfigMap", Namespace: testNamespace, Name: "rendered"},
+	}, updated.Status.DeletionPreview)
+	assert.True(t, meta.IsStatusConditionTrue(updated.Status.Conditions, TypeDeletionPreview))
+	for _, object := range objects {
+		assert.NoError(t, fakeClient.Get(ctx, client.ObjectKeyFromObject(object), object), "Nothing is deleted")
+	}
+}
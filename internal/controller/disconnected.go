@@ -0,0 +1,170 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+
+	orchestratorv1alpha2 "github.com/rhdhorchestrator/orchestrator-operator/api/v1alpha3"
+	orchestratorgitops "github.com/rhdhorchestrator/orchestrator-operator/internal/controller/gitops"
+	"github.com/rhdhorchestrator/orchestrator-operator/internal/controller/rhdh"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	TypeDisconnectedReady string = "DisconnectedReady"
+
+	ReasonExternalURLsReferenced = "ExternalURLsReferenced"
+	ReasonNoExternalURLs         = "NoExternalURLs"
+)
+
+var (
+	// urlPattern matches the absolute URLs; the hosts set by environment variables or parameters are not matched.
+	urlPattern = regexp.MustCompile(`https?://[A-Za-z0-9][A-Za-z0-9.-]*(:[0-9]+)?(/[^\s"'` + "`" + `]*)?`)
+	// imagePattern matches the image references on their own line or following an image key.
+	imagePattern = regexp.MustCompile(`(?m)(?:^|image:\s*)([a-z0-9][a-z0-9-]*(?:\.[a-z0-9-]+)+(?::[0-9]+)?/[^\s"'` + "`" + `]+)`)
+	// hostPattern matches the hosts of the SCM integrations.
+	hostPattern = regexp.MustCompile(`\bhost:\s*([a-z0-9][a-z0-9-]*(?:\.[a-z0-9-]+)+)`)
+)
+
+// findExternalURLs returns the sorted list of distinct URLs, images and hosts referenced in contents, whose host is
+// not allowed.
+func findExternalURLs(allowedHost func(host string) bool, contents ...string) []string {
+	found := map[string]bool{}
+	addExternal := func(reference, host string) {
+		if !allowedHost(host) {
+			found[reference] = true
+		}
+	}
+	for _, content := range contents {
+		for _, match := range urlPattern.FindAllString(content, -1) {
+			if parsedURL, err := url.Parse(match); err == nil {
+				addExternal(match, parsedURL.Hostname())
+			}
+		}
+		for _, match := range imagePattern.FindAllStringSubmatch(content, -1) {
+			addExternal(match[1], getImageHost(match[1]))
+		}
+		for _, match := range hostPattern.FindAllStringSubmatch(content, -1) {
+			addExternal(match[1], match[1])
+		}
+	}
+	externalURLs := make([]string, 0, len(found))
+	for externalURL := range found {
+		externalURLs = append(externalURLs, externalURL)
+	}
+	sort.Strings(externalURLs)
+	return externalURLs
+}
+
+// getImageHost returns the registry host of an image reference, without its port.
+func getImageHost(image string) string {
+	host, _, _ := strings.Cut(image, "/")
+	host, _, _ = strings.Cut(host, ":")
+	return host
+}
+
+// getURLHost returns the host of a URL, or of an image reference.
+func getURLHost(reference string) string {
+	if parsedURL, err := url.Parse(reference); err == nil && parsedURL.Host != "" {
+		return parsedURL.Hostname()
+	}
+	return getImageHost(reference)
+}
+
+// getAllowedHost returns whether a host is served by a configured mirror or SCM integration, or by the cluster:
+// the hosts without domain, the cluster domains, and the services of the namespaces used by the Orchestrator.
+func getAllowedHost(spec orchestratorv1alpha2.OrchestratorSpec) func(host string) bool {
+	mirrors := []string{spec.Disconnected.NpmRegistry, spec.Disconnected.PluginBaseUrl,
+		spec.Disconnected.ToolImages.BaseImage, spec.Disconnected.ToolImages.GitImage,
+		spec.Disconnected.ToolImages.KnWorkflowCliUrl, spec.Disconnected.ToolImages.WorkflowBuilderDockerfileUrl}
+	mirrors = append(mirrors, spec.Disconnected.CatalogLocations...)
+	// the SCM hosts set explicitly are the internal instances of the cluster, unlike the defaults
+	for _, integration := range spec.RHDHConfig.Integrations {
+		mirrors = append(mirrors, integration.Host, integration.APIBaseURL)
+	}
+	// the default image of the provisioned PostgreSQL is replaced by a mirrored one
+	if image := getPostgresImage(spec.PostgresConfig); image != postgresDefaultImage {
+		mirrors = append(mirrors, image)
+	}
+	mirrorHosts := map[string]bool{}
+	for _, mirror := range mirrors {
+		if mirror != "" {
+			mirrorHosts[getURLHost(mirror)] = true
+		}
+	}
+
+	namespaces := append(getWorkflowNamespaces(spec.PlatformConfig), spec.RHDHConfig.Namespace,
+		spec.PostgresConfig.Namespace, spec.ArgoCd.Namespace, spec.PlatformConfig.Eventing.Broker.Namespace,
+		knativeEventingNamespacedName)
+	return func(host string) bool {
+		domainEnd := strings.LastIndex(host, ".")
+		switch {
+		case mirrorHosts[host], host == "localhost", domainEnd < 0:
+			return true
+		case strings.HasSuffix(host, ".svc"), strings.HasSuffix(host, ".cluster.local"):
+			return true
+		default:
+			return slices.Contains(namespaces, host[domainEnd+1:])
+		}
+	}
+}
+
+// getDisconnectedCondition renders the configuration of the enabled components and reports the
+// external URLs, images and hosts it still references.
+func getDisconnectedCondition(orchestrator *orchestratorv1alpha2.Orchestrator, platform clusterPlatform) (metav1.Condition, error) {
+	spec := orchestrator.Spec
+	renderedConfigs := make([]string, 0)
+	if spec.RHDHConfig.InstallOperator {
+		rhdhConfigs, err := rhdh.RenderConfigs("", spec.PlatformConfig,
+			spec.ArgoCd.Enabled, spec.Tekton.Enabled, spec.RHDHConfig, spec.Disconnected)
+		if err != nil {
+			return metav1.Condition{}, err
+		}
+		renderedConfigs = append(renderedConfigs, rhdhConfigs...)
+	}
+	if spec.ArgoCd.Enabled && spec.Tekton.Enabled {
+		renderedConfigs = append(renderedConfigs,
+			orchestratorgitops.RenderTasks(spec.ArgoCd.Namespace, spec.Disconnected.ToolImages)...)
+		renderedConfigs = append(renderedConfigs, orchestratorgitops.RenderPipelineTaskRefs(platform.openShift)...)
+	}
+	if spec.PostgresConfig.Provision {
+		renderedConfigs = append(renderedConfigs, getPostgresImage(spec.PostgresConfig))
+	}
+
+	externalURLs := findExternalURLs(getAllowedHost(spec), renderedConfigs...)
+	if len(externalURLs) > 0 {
+		return metav1.Condition{
+			Type:   TypeDisconnectedReady,
+			Status: metav1.ConditionFalse,
+			Reason: ReasonExternalURLsReferenced,
+			Message: fmt.Sprintf("The rendered configuration references external URLs: %s",
+				strings.Join(externalURLs, ", ")),
+		}, nil
+	}
+	return metav1.Condition{
+		Type:    TypeDisconnectedReady,
+		Status:  metav1.ConditionTrue,
+		Reason:  ReasonNoExternalURLs,
+		Message: "The rendered configuration does not reference external URLs",
+	}, nil
+}
@@ -0,0 +1,126 @@
+package controller
+
+import (
+	"testing"
+
+	orchestratorv1alpha2 "github.com/rhdhorchestrator/orchestrator-operator/api/v1alpha3"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestFindExternalURLs(t *testing.T) {
+	spec := newTestOrchestrator().Spec
+	spec.Disconnected.CatalogLocations = []string{"https://git.internal.example.com/catalog/users.yaml"}
+	externalURLs := findExternalURLs(getAllowedHost(spec),
+		"registry=https://npm.stage.registry.redhat.com",
+		"image: registry.access.redhat.com/ubi9-minimal",
+		"registry.redhat.io/rhel9/postgresql-15:latest",
+		"target: https://github.com/rhdhorchestrator/workflow-software-templates/blob/v1.5.x/entities/workflow-resources.yaml",
+		"registry=https://npm.stage.registry.redhat.com",
+		"  - host: gitlab.com\n    apiBaseUrl: https://gitlab.com/api/v4\n  - host: ${GITLAB_HOST}",
+		"url: http://sonataflow-platform-data-index-service."+testNamespace,
+		"url: http://broker-ingress.knative-eventing.svc.cluster.local/orchestrator-broker/default",
+		"target: https://git.internal.example.com/catalog/workflow-resources.yaml",
+		"apiVersion: sonataflow.org/v1alpha08",
+	)
+	assert.Equal(t, []string{
+		"gitlab.com",
+		"https://github.com/rhdhorchestrator/workflow-software-templates/blob/v1.5.x/entities/workflow-resources.yaml",
+		"https://gitlab.com/api/v4",
+		"https://npm.stage.registry.redhat.com",
+		"registry.access.redhat.com/ubi9-minimal",
+		"registry.redhat.io/rhel9/postgresql-15:latest",
+	}, externalURLs)
+}
+
+func TestGetDisconnectedCondition(t *testing.T) {
+	mirrored := orchestratorv1alpha2.DisconnectedConfig{
+		Enabled:          true,
+		NpmRegistry:      "https://npm.mirror.example.com",
+		PluginBaseUrl:    "https://artifacts.mirror.example.com/orchestrator-plugins/",
+		CatalogLocations: []string{"https://git.mirror.example.com/catalog/workflow-resources.yaml"},
+		ToolImages: orchestratorv1alpha2.ToolImages{
+			BaseImage:                    "registry.mirror.example.com/ubi9/ubi-minimal",
+			GitImage:                     "registry.mirror.example.com/chainguard/git:root-2.39",
+			KnWorkflowCliUrl:             "https://artifacts.mirror.example.com/kn-workflow-linux-amd64.tar.gz",
+			WorkflowBuilderDockerfileUrl: "https://artifacts.mirror.example.com/workflow-builder.Dockerfile",
+		},
+	}
+	mirroredIntegrations := []orchestratorv1alpha2.RHDHIntegration{
+		{Type: orchestratorv1alpha2.RHDHIntegrationGitHub, Host: "github.mirror.example.com"},
+	}
+
+	testCases := []struct {
+		name           string
+		disconnected   orchestratorv1alpha2.DisconnectedConfig
+		integrations   []orchestratorv1alpha2.RHDHIntegration
+		postgres       orchestratorv1alpha2.PostgresConfig
+		platform       clusterPlatform
+		expectedStatus metav1.ConditionStatus
+		expectedReason string
+		expectedURL    string
+	}{
+		{
+			name:           "Default configuration references external URLs",
+			disconnected:   orchestratorv1alpha2.DisconnectedConfig{Enabled: true},
+			platform:       clusterPlatform{openShift: true},
+			expectedStatus: metav1.ConditionFalse,
+			expectedReason: ReasonExternalURLsReferenced,
+			expectedURL:    "github.com",
+		},
+		{
+			name:           "Mirrored configuration does not reference external URLs",
+			disconnected:   mirrored,
+			integrations:   mirroredIntegrations,
+			platform:       clusterPlatform{openShift: true},
+			expectedStatus: metav1.ConditionTrue,
+			expectedReason: ReasonNoExternalURLs,
+		},
+		{
+			name:           "Buildah Task resolved from Artifact Hub outside OpenShift",
+			disconnected:   mirrored,
+			integrations:   mirroredIntegrations,
+			expectedStatus: metav1.ConditionFalse,
+			expectedReason: ReasonExternalURLsReferenced,
+			expectedURL:    "https://artifacthub.io",
+		},
+		{
+			name:           "Default image of the provisioned PostgreSQL",
+			disconnected:   mirrored,
+			integrations:   mirroredIntegrations,
+			postgres:       orchestratorv1alpha2.PostgresConfig{Provision: true},
+			platform:       clusterPlatform{openShift: true},
+			expectedStatus: metav1.ConditionFalse,
+			expectedReason: ReasonExternalURLsReferenced,
+			expectedURL:    postgresDefaultImage,
+		},
+		{
+			name:           "Mirrored image of the provisioned PostgreSQL",
+			disconnected:   mirrored,
+			integrations:   mirroredIntegrations,
+			postgres:       orchestratorv1alpha2.PostgresConfig{Provision: true, Image: "registry.mirror.example.com/rhel9/postgresql-15"},
+			platform:       clusterPlatform{openShift: true},
+			expectedStatus: metav1.ConditionTrue,
+			expectedReason: ReasonNoExternalURLs,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			orchestrator := newTestOrchestrator()
+			orchestrator.Spec.RHDHConfig.InstallOperator = true
+			orchestrator.Spec.RHDHConfig.Integrations = tc.integrations
+			orchestrator.Spec.ArgoCd = orchestratorv1alpha2.ArgoCD{Enabled: true, Namespace: "orchestrator-gitops"}
+			orchestrator.Spec.Tekton.Enabled = true
+			orchestrator.Spec.PostgresConfig = tc.postgres
+			orchestrator.Spec.Disconnected = tc.disconnected
+
+			condition, err := getDisconnectedCondition(orchestrator, tc.platform)
+			assert.NoError(t, err)
+			assert.Equal(t, TypeDisconnectedReady, condition.Type)
+			assert.Equal(t, tc.expectedStatus, condition.Status, condition.Message)
+			assert.Equal(t, tc.expectedReason, condition.Reason)
+			assert.Contains(t, condition.Message, tc.expectedURL)
+		})
+	}
+}
@@ -30,13 +30,17 @@ package gitops
 
 import (
 	"context"
+	orchestratorv1alpha2 "github.com/rhdhorchestrator/orchestrator-operator/api/v1alpha3"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
 
 // HandleGitOps performs the retrieval, creation and reconciling of Tekton and GitOps policy.
+// The Tasks provided by OpenShift Pipelines are only referenced on OpenShift.
 // It returns an error if any occurs during retrieval, creation or reconciliation.
-func HandleGitOps(client client.Client, ctx context.Context, gitOpsNamespace string) error {
+func HandleGitOps(
+	client client.Client, ctx context.Context, gitOpsNamespace string, disconnected orchestratorv1alpha2.DisconnectedConfig,
+	openShift bool) error {
 	logger := log.FromContext(ctx)
 	logger.Info("Handling GitOps resource")
 
@@ -44,24 +48,26 @@ func HandleGitOps(client client.Client, ctx context.Context, gitOpsNamespace str
 		return err
 	}
 
-	if err := handleTektonPipelineTasks(client, ctx, gitOpsNamespace); err != nil {
+	if err := handleTektonPipelineTasks(client, ctx, gitOpsNamespace, disconnected, openShift); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func handleTektonPipelineTasks(client client.Client, ctx context.Context, gitOpsNamespace string) error {
+func handleTektonPipelineTasks(
+	client client.Client, ctx context.Context, gitOpsNamespace string, disconnected orchestratorv1alpha2.DisconnectedConfig,
+	openShift bool) error {
 	logger := log.FromContext(ctx)
 	logger.Info("Handling Tekton resource")
 
 	// handle tekton task
-	if err := HandleTektonTasks(client, ctx, gitOpsNamespace); err != nil {
+	if err := HandleTektonTasks(client, ctx, gitOpsNamespace, disconnected); err != nil {
 		return err
 	}
 
 	// handle tekton pipeline
-	if err := HandleTektonPipeline(client, ctx, gitOpsNamespace); err != nil {
+	if err := HandleTektonPipeline(client, ctx, gitOpsNamespace, openShift); err != nil {
 		return err
 	}
 	return nil
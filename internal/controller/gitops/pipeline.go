@@ -23,9 +23,12 @@ const (
 	buildAndPushImagePipelineTask   = "build-and-push-image"
 	pushWorkflowGitOpsPipelineTask  = "push-workflow-gitops"
 	pipelineCRDName                 = "pipelines.tekton.dev"
+	buildahHubCatalog               = "tekton-catalog-tasks"
+	buildahHubVersion               = "0.9"
+	artifactHubURL                  = "https://artifacthub.io"
 )
 
-func HandleTektonPipeline(client client.Client, ctx context.Context, gitOpsNamespace string) error {
+func HandleTektonPipeline(client client.Client, ctx context.Context, gitOpsNamespace string, openShift bool) error {
 	logger := log.FromContext(ctx)
 	logger.Info("Handling tekton pipeline resources")
 
@@ -154,16 +157,7 @@ func HandleTektonPipeline(client client.Client, ctx context.Context, gitOpsNames
 				{
 					Name:     buildAndPushImagePipelineTask,
 					RunAfter: []string{flattenWorkflowPipelineTask},
-					TaskRef: &tektonv1.TaskRef{
-						ResolverRef: tektonv1.ResolverRef{
-							Resolver: "cluster",
-							Params: []tektonv1.Param{
-								{Name: "kind", Value: tektonv1.ParamValue{Type: tektonv1.ParamTypeString, StringVal: "task"}},
-								{Name: "name", Value: tektonv1.ParamValue{Type: tektonv1.ParamTypeString, StringVal: "buildah"}},
-								{Name: "namespace", Value: tektonv1.ParamValue{Type: tektonv1.ParamTypeString, StringVal: "openshift-pipelines"}},
-							},
-						},
-					},
+					TaskRef:  getBuildahTaskRef(openShift),
 					Workspaces: []tektonv1.WorkspacePipelineTaskBinding{
 						{Name: "source", Workspace: "workflow-source"},
 						{Name: "dockerconfig", Workspace: "docker-credentials"},
@@ -232,6 +226,44 @@ func HandleTektonPipeline(client client.Client, ctx context.Context, gitOpsNames
 	return nil
 }
 
+// RenderPipelineTaskRefs returns the endpoints the resolvers of the pipeline tasks fetch their Tasks from: the hub
+// resolver fetches the buildah Task of the artifact catalog from Artifact Hub by default.
+func RenderPipelineTaskRefs(openShift bool) []string {
+	if openShift {
+		return nil
+	}
+	return []string{artifactHubURL}
+}
+
+// getBuildahTaskRef references the buildah Task installed by OpenShift Pipelines, or the one of the Tekton
+// catalog on the other clusters.
+func getBuildahTaskRef(openShift bool) *tektonv1.TaskRef {
+	if openShift {
+		return &tektonv1.TaskRef{
+			ResolverRef: tektonv1.ResolverRef{
+				Resolver: "cluster",
+				Params: []tektonv1.Param{
+					{Name: "kind", Value: tektonv1.ParamValue{Type: tektonv1.ParamTypeString, StringVal: "task"}},
+					{Name: "name", Value: tektonv1.ParamValue{Type: tektonv1.ParamTypeString, StringVal: "buildah"}},
+					{Name: "namespace", Value: tektonv1.ParamValue{Type: tektonv1.ParamTypeString, StringVal: "openshift-pipelines"}},
+				},
+			},
+		}
+	}
+	return &tektonv1.TaskRef{
+		ResolverRef: tektonv1.ResolverRef{
+			Resolver: "hub",
+			Params: []tektonv1.Param{
+				{Name: "catalog", Value: tektonv1.ParamValue{Type: tektonv1.ParamTypeString, StringVal: buildahHubCatalog}},
+				{Name: "type", Value: tektonv1.ParamValue{Type: tektonv1.ParamTypeString, StringVal: "artifact"}},
+				{Name: "kind", Value: tektonv1.ParamValue{Type: tektonv1.ParamTypeString, StringVal: "task"}},
+				{Name: "name", Value: tektonv1.ParamValue{Type: tektonv1.ParamTypeString, StringVal: "buildah"}},
+				{Name: "version", Value: tektonv1.ParamValue{Type: tektonv1.ParamTypeString, StringVal: buildahHubVersion}},
+			},
+		},
+	}
+}
+
 func handleTektonPipelineCleanUp(client client.Client, ctx context.Context, gitOpsNamespace string) error {
 	pipelineLogger := log.FromContext(ctx)
 
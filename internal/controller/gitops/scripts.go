@@ -82,11 +82,11 @@ fi
 
 ls flat/$(params.workflowId)
 
-curl -L https://raw.githubusercontent.com/rhdhorchestrator/serverless-workflows/main/pipeline/workflow-builder.Dockerfile -o flat/workflow-builder.Dockerfile
+curl -L %s -o flat/workflow-builder.Dockerfile
 `
 
 const buildManifestTaskScript = `microdnf install -y tar gzip
-KN_CLI_URL="https://developers.redhat.com/content-gateway/file/pub/cgw/serverless-logic/1.35.0/kn-workflow-linux-amd64.tar.gz"
+KN_CLI_URL="%s"
 curl -L "$KN_CLI_URL" | tar -xz --no-same-owner && chmod +x kn-workflow-linux-amd64 && mv kn-workflow-linux-amd64 kn-workflow
 ./kn-workflow gen-manifest --namespace ""
 `
@@ -18,6 +18,10 @@ package gitops
 
 import (
 	"context"
+	"fmt"
+	"slices"
+
+	orchestratorv1alpha2 "github.com/rhdhorchestrator/orchestrator-operator/api/v1alpha3"
 	"github.com/rhdhorchestrator/orchestrator-operator/internal/controller/kube"
 	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
 	corev1 "k8s.io/api/core/v1"
@@ -36,6 +40,11 @@ const (
 	buildManifestTask    = "build-manifests"
 	buildGitOpsTask      = "build-gitops"
 	tektonCRDName        = "tasks.tekton.dev"
+
+	defaultTaskBaseImage                = "registry.access.redhat.com/ubi9-minimal"
+	defaultTaskGitImage                 = "cgr.dev/chainguard/git:root-2.39@sha256:7759f87050dd8bacabe61354d75ccd7f864d6b6f8ec42697db7159eccd491139"
+	defaultKnWorkflowCliUrl             = "https://developers.redhat.com/content-gateway/file/pub/cgw/serverless-logic/1.35.0/kn-workflow-linux-amd64.tar.gz"
+	defaultWorkflowBuilderDockerfileUrl = "https://raw.githubusercontent.com/rhdhorchestrator/serverless-workflows/main/pipeline/workflow-builder.Dockerfile"
 )
 
 var tektonTaskList = []string{
@@ -45,7 +54,11 @@ var tektonTaskList = []string{
 	buildGitOpsTask,
 }
 
-func HandleTektonTasks(client client.Client, ctx context.Context, gitOpsNamespace string) error {
+// HandleTektonTasks creates the Tekton Tasks of the pipeline, or updates the Tasks created by the operator when
+// their rendered images, scripts or parameter defaults differ. The mirrored tool images are used when the cluster
+// is disconnected.
+func HandleTektonTasks(
+	client client.Client, ctx context.Context, gitOpsNamespace string, disconnected orchestratorv1alpha2.DisconnectedConfig) error {
 	taskLogger := log.FromContext(ctx)
 	taskLogger.Info("Handling Tekton Tasks...")
 
@@ -54,43 +67,105 @@ func HandleTektonTasks(client client.Client, ctx context.Context, gitOpsNamespac
 		return err
 	}
 
+	toolImages := getToolImages(disconnected)
 	for _, taskName := range tektonTaskList {
+		desiredTask := getTaskObject(gitOpsNamespace, taskName, toolImages)
+		existingTask := &tektonv1.Task{}
 		if err := client.Get(ctx, types.NamespacedName{
-			Namespace: gitOpsNamespace, Name: taskName}, &tektonv1.Task{}); err != nil {
+			Namespace: gitOpsNamespace, Name: taskName}, existingTask); err != nil {
 			if apierrors.IsNotFound(err) {
-				tektonTask := getTaskObject(gitOpsNamespace, taskName)
-				if tektonTask != nil {
-					if err := client.Create(ctx, tektonTask); err != nil {
-						taskLogger.Error(err, "Error occurred when creating Tekton Task", "Task", taskName)
-						return err
-					}
-					taskLogger.Info("Successfully created Tekton Task", "Task", taskName)
-					continue
+				if err := client.Create(ctx, desiredTask); err != nil {
+					taskLogger.Error(err, "Error occurred when creating Tekton Task", "Task", taskName)
+					return err
 				}
+				taskLogger.Info("Successfully created Tekton Task", "Task", taskName)
+				continue
 			}
 			taskLogger.Error(err, "Error occurred when checking task exist", "Task", taskName)
 			continue
 		}
+
+		// the Tasks of the same name created by users are left untouched
+		if !kube.CheckLabelExist(existingTask.Labels) ||
+			slices.Equal(renderTask(existingTask), renderTask(desiredTask)) {
+			continue
+		}
+		existingTask.Spec = desiredTask.Spec
+		if err := client.Update(ctx, existingTask); err != nil {
+			taskLogger.Error(err, "Error occurred when updating Tekton Task", "Task", taskName)
+			return err
+		}
+		taskLogger.Info("Successfully updated Tekton Task", "Task", taskName)
 	}
 	return nil
 }
 
-func getTaskObject(gitOpsNamespace, taskName string) *tektonv1.Task {
+// RenderTasks returns the images and scripts of the Tekton tasks created with the given tool images.
+func RenderTasks(gitOpsNamespace string, toolImages orchestratorv1alpha2.ToolImages) []string {
+	renderedTasks := make([]string, 0)
+	for _, taskName := range tektonTaskList {
+		renderedTasks = append(renderedTasks,
+			renderTask(getTaskObject(gitOpsNamespace, taskName, withDefaultToolImages(toolImages)))...)
+	}
+	return renderedTasks
+}
+
+// renderTask returns the parameter defaults, images and scripts of the task. Unlike the whole spec, they are not
+// defaulted by Tekton, so that they compare the live task with the desired one.
+func renderTask(tektonTask *tektonv1.Task) []string {
+	renderedTask := make([]string, 0)
+	for _, param := range tektonTask.Spec.Params {
+		if param.Default != nil {
+			renderedTask = append(renderedTask, param.Default.StringVal)
+		}
+	}
+	for _, step := range tektonTask.Spec.Steps {
+		renderedTask = append(renderedTask, step.Image, step.Script)
+	}
+	return renderedTask
+}
+
+// getToolImages returns the mirrored tool images when the cluster is disconnected, completed by the defaults.
+func getToolImages(disconnected orchestratorv1alpha2.DisconnectedConfig) orchestratorv1alpha2.ToolImages {
+	if !disconnected.Enabled {
+		return withDefaultToolImages(orchestratorv1alpha2.ToolImages{})
+	}
+	return withDefaultToolImages(disconnected.ToolImages)
+}
+
+// withDefaultToolImages fills the tool images which are not mirrored with their default values.
+func withDefaultToolImages(toolImages orchestratorv1alpha2.ToolImages) orchestratorv1alpha2.ToolImages {
+	if toolImages.BaseImage == "" {
+		toolImages.BaseImage = defaultTaskBaseImage
+	}
+	if toolImages.GitImage == "" {
+		toolImages.GitImage = defaultTaskGitImage
+	}
+	if toolImages.KnWorkflowCliUrl == "" {
+		toolImages.KnWorkflowCliUrl = defaultKnWorkflowCliUrl
+	}
+	if toolImages.WorkflowBuilderDockerfileUrl == "" {
+		toolImages.WorkflowBuilderDockerfileUrl = defaultWorkflowBuilderDockerfileUrl
+	}
+	return toolImages
+}
+
+func getTaskObject(gitOpsNamespace, taskName string, toolImages orchestratorv1alpha2.ToolImages) *tektonv1.Task {
 	switch taskName {
 	case gitCLITask:
-		return createGitCLITaskObject(gitOpsNamespace)
+		return createGitCLITaskObject(gitOpsNamespace, toolImages.GitImage)
 	case flattenerTask:
-		return createFlattenerTaskObject(gitOpsNamespace)
+		return createFlattenerTaskObject(gitOpsNamespace, toolImages.BaseImage, toolImages.WorkflowBuilderDockerfileUrl)
 	case buildManifestTask:
-		return createBuildManifestTaskObject(gitOpsNamespace)
+		return createBuildManifestTaskObject(gitOpsNamespace, toolImages.BaseImage, toolImages.KnWorkflowCliUrl)
 	case buildGitOpsTask:
-		return createBuildGitOpsTaskObject(gitOpsNamespace)
+		return createBuildGitOpsTaskObject(gitOpsNamespace, toolImages.BaseImage)
 	default:
 		return nil
 	}
 }
 
-func createGitCLITaskObject(gitOpsNamespace string) *tektonv1.Task {
+func createGitCLITaskObject(gitOpsNamespace, gitImage string) *tektonv1.Task {
 	return &tektonv1.Task{
 		TypeMeta: metav1.TypeMeta{
 			APIVersion: tektonTaskAPIVersion,
@@ -138,7 +213,7 @@ func createGitCLITaskObject(gitOpsNamespace string) *tektonv1.Task {
 					Type:        tektonv1.ParamTypeString,
 					Default: &tektonv1.ParamValue{
 						Type:      tektonv1.ParamTypeString,
-						StringVal: "cgr.dev/chainguard/git:root-2.39@sha256:7759f87050dd8bacabe61354d75ccd7f864d6b6f8ec42697db7159eccd491139"},
+						StringVal: gitImage},
 				},
 				{
 					Name:        "GIT_USER_NAME",
@@ -214,7 +289,7 @@ func createGitCLITaskObject(gitOpsNamespace string) *tektonv1.Task {
 	}
 }
 
-func createFlattenerTaskObject(gitOpsNamespace string) *tektonv1.Task {
+func createFlattenerTaskObject(gitOpsNamespace, baseImage, dockerfileUrl string) *tektonv1.Task {
 	return &tektonv1.Task{
 		TypeMeta: metav1.TypeMeta{
 			APIVersion: tektonTaskAPIVersion,
@@ -250,16 +325,16 @@ func createFlattenerTaskObject(gitOpsNamespace string) *tektonv1.Task {
 			Steps: []tektonv1.Step{
 				{
 					Name:       "flatten",
-					Image:      "registry.access.redhat.com/ubi9-minimal",
+					Image:      baseImage,
 					WorkingDir: "$(workspaces.workflow-source.path)",
-					Script:     flattenerTaskScript,
+					Script:     fmt.Sprintf(flattenerTaskScript, dockerfileUrl),
 				},
 			},
 		},
 	}
 }
 
-func createBuildManifestTaskObject(gitOpsNamespace string) *tektonv1.Task {
+func createBuildManifestTaskObject(gitOpsNamespace, baseImage, knWorkflowCliUrl string) *tektonv1.Task {
 	return &tektonv1.Task{
 		TypeMeta: metav1.TypeMeta{
 			APIVersion: tektonTaskAPIVersion,
@@ -286,16 +361,16 @@ func createBuildManifestTaskObject(gitOpsNamespace string) *tektonv1.Task {
 			Steps: []tektonv1.Step{
 				{
 					Name:       buildManifestTask,
-					Image:      "registry.access.redhat.com/ubi9-minimal",
+					Image:      baseImage,
 					WorkingDir: "$(workspaces.workflow-source.path)/flat/$(params.workflowId)",
-					Script:     buildManifestTaskScript,
+					Script:     fmt.Sprintf(buildManifestTaskScript, knWorkflowCliUrl),
 				},
 			},
 		},
 	}
 }
 
-func createBuildGitOpsTaskObject(gitOpsNamespace string) *tektonv1.Task {
+func createBuildGitOpsTaskObject(gitOpsNamespace, baseImage string) *tektonv1.Task {
 	return &tektonv1.Task{
 		TypeMeta: metav1.TypeMeta{
 			APIVersion: tektonTaskAPIVersion,
@@ -325,7 +400,7 @@ func createBuildGitOpsTaskObject(gitOpsNamespace string) *tektonv1.Task {
 			Steps: []tektonv1.Step{
 				{
 					Name:       buildGitOpsTask,
-					Image:      "registry.access.redhat.com/ubi9-minimal",
+					Image:      baseImage,
 					WorkingDir: "$(workspaces.workflow-gitops.path)/workflow-gitops",
 					Script:     buildGitOpsTaskScript,
 				},
@@ -0,0 +1,81 @@
+package gitops
+
+import (
+	"context"
+	"testing"
+
+	orchestratorv1alpha2 "github.com/rhdhorchestrator/orchestrator-operator/api/v1alpha3"
+	"github.com/rhdhorchestrator/orchestrator-operator/internal/controller/kube"
+	"github.com/stretchr/testify/assert"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const testGitOpsNamespace = "orchestrator-gitops"
+
+func TestHandleTektonTasks(t *testing.T) {
+	ctx := context.TODO()
+	scheme := runtime.NewScheme()
+	utilruntime.Must(tektonv1.AddToScheme(scheme))
+	utilruntime.Must(apiextensionsv1.AddToScheme(scheme))
+
+	mirrored := orchestratorv1alpha2.DisconnectedConfig{
+		ToolImages: orchestratorv1alpha2.ToolImages{BaseImage: "mirror.example.com/ubi9-minimal"},
+	}
+	enabled := mirrored
+	enabled.Enabled = true
+
+	testCases := []struct {
+		name          string
+		disconnected  orchestratorv1alpha2.DisconnectedConfig
+		userTask      bool
+		expectedImage string
+	}{
+		{
+			name:          "Default image",
+			expectedImage: defaultTaskBaseImage,
+		},
+		{
+			name:          "Mirror ignored when the cluster is connected",
+			disconnected:  mirrored,
+			expectedImage: defaultTaskBaseImage,
+		},
+		{
+			name:          "Stale task updated to the mirror",
+			disconnected:  enabled,
+			expectedImage: "mirror.example.com/ubi9-minimal",
+		},
+		{
+			name:          "Task created by the user kept",
+			disconnected:  enabled,
+			userTask:      true,
+			expectedImage: "registry.example.com/user-image",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			existingTask := getTaskObject(testGitOpsNamespace, flattenerTask, withDefaultToolImages(orchestratorv1alpha2.ToolImages{}))
+			if tc.userTask {
+				existingTask.Labels = nil
+				existingTask.Spec.Steps[0].Image = "registry.example.com/user-image"
+			}
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).
+				WithObjects(existingTask, &apiextensionsv1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: tektonCRDName}}).
+				Build()
+
+			assert.NoError(t, HandleTektonTasks(fakeClient, ctx, testGitOpsNamespace, tc.disconnected))
+
+			task := &tektonv1.Task{}
+			assert.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Namespace: testGitOpsNamespace, Name: flattenerTask}, task))
+			assert.Equal(t, tc.expectedImage, task.Spec.Steps[0].Image)
+			assert.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Namespace: testGitOpsNamespace, Name: gitCLITask}, task))
+			assert.True(t, kube.CheckLabelExist(task.Labels), "Missing tasks are created")
+		})
+	}
+}
@@ -17,12 +17,20 @@ limitations under the License.
 package controller
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"fmt"
 	olmclientset "github.com/operator-framework/operator-lifecycle-manager/pkg/api/client/clientset/versioned"
+	orchestratorv1alpha2 "github.com/rhdhorchestrator/orchestrator-operator/api/v1alpha3"
 	"github.com/rhdhorchestrator/orchestrator-operator/internal/controller/kube"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	knative "knative.dev/operator/pkg/apis/operator/v1beta1"
 	"reflect"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -44,7 +52,18 @@ const (
 	knativeSubscriptionStartingCSV = "serverless-operator.v1.35.0"
 )
 
-func handleKNativeOperatorInstallation(ctx context.Context, client client.Client, olmClientSet olmclientset.Interface) error {
+// knativeOperatorDetection finds the Serverless operator installed without the Subscription of the Orchestrator.
+var knativeOperatorDetection = kube.OperatorDetection{
+	Name:                  "Knative",
+	CRDName:               knativeServingCRDName,
+	SubscriptionName:      knativeSubscriptionName,
+	SubscriptionNamespace: knativeOperatorNamespace,
+}
+
+func handleKNativeOperatorInstallation(
+	ctx context.Context, client *kube.InventoryClient, recorder record.EventRecorder,
+	orchestrator *orchestratorv1alpha2.Orchestrator, olmClientSet olmclientset.Interface,
+	subscriptionConfig orchestratorv1alpha2.SubscriptionConfig, installPlanPolicy kube.InstallPlanPolicy) error {
 	knativeLogger := log.FromContext(ctx)
 
 	if _, err := kube.CheckNamespaceExist(ctx, client, knativeOperatorNamespace); err != nil {
@@ -63,7 +82,8 @@ func handleKNativeOperatorInstallation(ctx context.Context, client client.Client
 		knativeSubscriptionName,
 		knativeOperatorNamespace,
 		knativeSubscriptionChannel,
-		knativeSubscriptionStartingCSV)
+		knativeSubscriptionStartingCSV,
+		subscriptionConfig)
 
 	// check if subscription exists
 	subscriptionExists, existingSubscription, err := kube.CheckSubscriptionExists(ctx, olmClientSet, serverlessSubscription)
@@ -80,6 +100,11 @@ func handleKNativeOperatorInstallation(ctx context.Context, client client.Client
 		}
 		knativeLogger.Info("Operator successfully installed", "SubscriptionName", knativeSubscriptionName)
 	} else {
+		// the subscriptions created by earlier releases are not in the inventory yet
+		if err := client.Adopt(ctx, existingSubscription); err != nil {
+			knativeLogger.Error(err, "Error occurred when adopting subscription", "SubscriptionName", knativeSubscriptionName)
+			return err
+		}
 		// Compare the current and desired state
 		if !reflect.DeepEqual(existingSubscription.Spec, serverlessSubscription.Spec) {
 			// Update the existing subscription with the new Spec
@@ -89,21 +114,27 @@ func handleKNativeOperatorInstallation(ctx context.Context, client client.Client
 				return err
 			}
 			knativeLogger.Info("Successfully updated updating subscription spec", "SubscriptionName", knativeSubscriptionName)
+			recorder.Eventf(orchestrator, corev1.EventTypeNormal, "SubscriptionUpdated",
+				"Updated Subscription %s/%s to match the Orchestrator spec", knativeOperatorNamespace, knativeSubscriptionName)
 		}
 	}
 
 	// approve install plan
-	if existingSubscription.Status.InstallPlanRef != nil && existingSubscription.Status.CurrentCSV == knativeSubscriptionStartingCSV {
+	if existingSubscription.Status.InstallPlanRef != nil && existingSubscription.Status.CurrentCSV == serverlessSubscription.Spec.StartingCSV {
 		installPlanName := existingSubscription.Status.InstallPlanRef.Name
-		if err := kube.ApproveInstallPlan(client, ctx, installPlanName, existingSubscription.Namespace); err != nil {
+		if err := kube.ApproveInstallPlan(client, ctx, recorder, orchestrator, installPlanName, existingSubscription.Namespace, installPlanPolicy); err != nil {
 			knativeLogger.Error(err, "Error occurred while approving install plan for subscription", "SubscriptionName", installPlanName)
 			return err
 		}
 	}
-	return nil
+
+	// the operands are reconciled once the operator is installed; until then the reconciliation is retried
+	return kube.CheckCSVSucceeded(ctx, olmClientSet, serverlessSubscription)
 }
 
-func handleKnativeCR(ctx context.Context, client client.Client) error {
+func handleKnativeCR(
+	ctx context.Context, client client.Client, recorder record.EventRecorder,
+	orchestrator *orchestratorv1alpha2.Orchestrator) error {
 	knativeLogger := log.FromContext(ctx)
 	knativeLogger.Info("Handling Serverless Custom Resources...")
 
@@ -117,7 +148,7 @@ func handleKnativeCR(ctx context.Context, client client.Client) error {
 		return err
 	}
 	// CRD exist; check and handle knative eventing CR
-	if err := handleKnativeEventingCR(ctx, client); err != nil {
+	if err := handleKnativeEventingCR(ctx, client, recorder, orchestrator); err != nil {
 		knativeLogger.Error(err, "Error occurred when creating Knative EventingCR", "CR-Name", knativeEventingNamespacedName)
 		return err
 	}
@@ -132,26 +163,34 @@ func handleKnativeCR(ctx context.Context, client client.Client) error {
 		return err
 	}
 	// CRD exist; check and handle knative eventing CR
-	if err := handleKnativeServingCR(ctx, client); err != nil {
+	if err := handleKnativeServingCR(ctx, client, recorder, orchestrator); err != nil {
 		knativeLogger.Error(err, "Error occurred when creating Knative ServingCR", "CR-Name", knativeServingNamespacedName)
 		return err
 	}
 	return nil
 }
 
-func handleKnativeEventingCR(ctx context.Context, client client.Client) error {
+func handleKnativeEventingCR(
+	ctx context.Context, k8client client.Client, recorder record.EventRecorder,
+	orchestrator *orchestratorv1alpha2.Orchestrator) error {
 	logger := log.FromContext(ctx)
 	logger.Info("Handling K-Native Eventing CR")
 
 	// check namespace exist; else create namespace
-	namespaceExist, _ := kube.CheckNamespaceExist(ctx, client, knativeEventingNamespacedName)
+	namespaceExist, _ := kube.CheckNamespaceExist(ctx, k8client, knativeEventingNamespacedName)
 	if !namespaceExist {
-		if err := kube.CreateNamespace(ctx, client, knativeEventingNamespacedName); err != nil {
+		if err := kube.CreateNamespace(ctx, k8client, knativeEventingNamespacedName); err != nil {
 			logger.Error(err, "Error occurred when creating namespace", "NS", knativeEventingNamespacedName)
 			return err
 		}
 	}
 
+	desiredSpec := knative.KnativeEventingSpec{}
+	if err := decodeKnativeSpec(orchestrator.Spec.ServerlessOperator.Eventing, &desiredSpec); err != nil {
+		logger.Error(err, "Error occurred when decoding the eventing spec", "CR-Name", knativeEventingNamespacedName)
+		return fmt.Errorf("invalid spec.serverless.eventing: %w", err)
+	}
+
 	desiredKnEventingCR := &knative.KnativeEventing{
 		TypeMeta: metav1.TypeMeta{
 			APIVersion: knativeAPIVersion,
@@ -162,18 +201,19 @@ func handleKnativeEventingCR(ctx context.Context, client client.Client) error {
 			Namespace: knativeEventingNamespacedName,
 			Labels:    kube.AddLabel(),
 		},
-		Spec: knative.KnativeEventingSpec{},
+		Spec: desiredSpec,
 	}
 	currentKnEventingCR := &knative.KnativeEventing{}
 
 	// check CR exists
-	err := client.Get(ctx, types.NamespacedName{Name: knativeEventingNamespacedName, Namespace: knativeEventingNamespacedName}, currentKnEventingCR)
+	err := k8client.Get(ctx, types.NamespacedName{Name: knativeEventingNamespacedName, Namespace: knativeEventingNamespacedName}, currentKnEventingCR)
 
 	if err != nil {
 		// CR does not exist. Create CR
 		if apierrors.IsNotFound(err) {
-			if err = client.Create(ctx, desiredKnEventingCR); err != nil {
+			if err = k8client.Create(ctx, desiredKnEventingCR); err != nil {
 				logger.Error(err, "Error occurred when creating CR resource", "CR-Name", desiredKnEventingCR.Name)
+				return err
 			}
 			logger.Info("Successfully created Knative Eventing resource", "CR-Name", desiredKnEventingCR.Name)
 			return nil
@@ -181,22 +221,44 @@ func handleKnativeEventingCR(ctx context.Context, client client.Client) error {
 		logger.Error(err, "Error occurred when checking CR resource exist", "CR-Name", desiredKnEventingCR.Name)
 		return err
 	}
+
+	// the live spec is only managed when the passthrough spec is set
+	if orchestrator.Spec.ServerlessOperator.Eventing == nil || equality.Semantic.DeepEqual(currentKnEventingCR.Spec, desiredSpec) {
+		return nil
+	}
+	originalKnEventingCR := currentKnEventingCR.DeepCopy()
+	currentKnEventingCR.Spec = desiredSpec
+	if err := k8client.Patch(ctx, currentKnEventingCR, client.MergeFrom(originalKnEventingCR)); err != nil {
+		logger.Error(err, "Error occurred when updating CR resource", "CR-Name", knativeEventingNamespacedName)
+		return err
+	}
+	logger.Info("Successfully updated Knative Eventing resource", "CR-Name", knativeEventingNamespacedName)
+	recorder.Eventf(orchestrator, corev1.EventTypeNormal, "KnativeEventingUpdated",
+		"Updated KnativeEventing %s/%s to match spec.serverless.eventing", knativeEventingNamespacedName, knativeEventingNamespacedName)
 	return nil
 }
 
-func handleKnativeServingCR(ctx context.Context, client client.Client) error {
+func handleKnativeServingCR(
+	ctx context.Context, k8client client.Client, recorder record.EventRecorder,
+	orchestrator *orchestratorv1alpha2.Orchestrator) error {
 	logger := log.FromContext(ctx)
 	logger.Info("Handling K-Native Serving CR")
 
 	// check namespace exist; else create namespace
-	namespaceExist, _ := kube.CheckNamespaceExist(ctx, client, knativeServingNamespacedName)
+	namespaceExist, _ := kube.CheckNamespaceExist(ctx, k8client, knativeServingNamespacedName)
 	if !namespaceExist {
-		if err := kube.CreateNamespace(ctx, client, knativeServingNamespacedName); err != nil {
-			logger.Error(err, "Error occurred when creating namespace", "NS", knativeEventingNamespacedName)
+		if err := kube.CreateNamespace(ctx, k8client, knativeServingNamespacedName); err != nil {
+			logger.Error(err, "Error occurred when creating namespace", "NS", knativeServingNamespacedName)
 			return err
 		}
 	}
 
+	desiredSpec := knative.KnativeServingSpec{}
+	if err := decodeKnativeSpec(orchestrator.Spec.ServerlessOperator.Serving, &desiredSpec); err != nil {
+		logger.Error(err, "Error occurred when decoding the serving spec", "CR-Name", knativeServingNamespacedName)
+		return fmt.Errorf("invalid spec.serverless.serving: %w", err)
+	}
+
 	desiredKnServingCR := &knative.KnativeServing{
 		TypeMeta: metav1.TypeMeta{
 			APIVersion: knativeAPIVersion,
@@ -207,42 +269,68 @@ func handleKnativeServingCR(ctx context.Context, client client.Client) error {
 			Namespace: knativeServingNamespacedName,
 			Labels:    kube.AddLabel(),
 		},
-		Spec: knative.KnativeServingSpec{},
+		Spec: desiredSpec,
 	}
 	currentKnServingCR := &knative.KnativeServing{}
 
 	// check CR exists
-	err := client.Get(ctx, types.NamespacedName{Name: knativeServingNamespacedName, Namespace: knativeServingNamespacedName}, currentKnServingCR)
+	err := k8client.Get(ctx, types.NamespacedName{Name: knativeServingNamespacedName, Namespace: knativeServingNamespacedName}, currentKnServingCR)
 
 	if err != nil {
 		// CR does not exist. Create CR
 		if apierrors.IsNotFound(err) {
-			if err = client.Create(ctx, desiredKnServingCR); err != nil {
+			if err = k8client.Create(ctx, desiredKnServingCR); err != nil {
 				logger.Error(err, "Error occurred when creating CR resource", "CR-Name", desiredKnServingCR.Name)
+				return err
 			}
-			logger.Info("Successfully created knative Eventing resource", "CR-Name", desiredKnServingCR.Name)
+			logger.Info("Successfully created knative Serving resource", "CR-Name", desiredKnServingCR.Name)
 			return nil
 		}
 		logger.Error(err, "Error occurred when checking CR resource exist", "CR-Name", desiredKnServingCR.Name)
 		return err
 	}
+
+	// the live spec is only managed when the passthrough spec is set
+	if orchestrator.Spec.ServerlessOperator.Serving == nil || equality.Semantic.DeepEqual(currentKnServingCR.Spec, desiredSpec) {
+		return nil
+	}
+	originalKnServingCR := currentKnServingCR.DeepCopy()
+	currentKnServingCR.Spec = desiredSpec
+	if err := k8client.Patch(ctx, currentKnServingCR, client.MergeFrom(originalKnServingCR)); err != nil {
+		logger.Error(err, "Error occurred when updating CR resource", "CR-Name", knativeServingNamespacedName)
+		return err
+	}
+	logger.Info("Successfully updated knative Serving resource", "CR-Name", knativeServingNamespacedName)
+	recorder.Eventf(orchestrator, corev1.EventTypeNormal, "KnativeServingUpdated",
+		"Updated KnativeServing %s/%s to match spec.serverless.serving", knativeServingNamespacedName, knativeServingNamespacedName)
 	return nil
 }
 
-func handleKnativeCleanUp(ctx context.Context, client client.Client) error {
+// decodeKnativeSpec decodes the passthrough spec into spec, rejecting unknown fields. An unset passthrough
+// leaves spec empty.
+func decodeKnativeSpec(passthrough *runtime.RawExtension, spec any) error {
+	if passthrough == nil || len(passthrough.Raw) == 0 {
+		return nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(passthrough.Raw))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(spec)
+}
+
+func handleKnativeCleanUp(ctx context.Context, inventory *kube.InventoryClient) error {
 	logger := log.FromContext(ctx)
 	// remove all namespace
-	if err := kube.CleanUpNamespace(ctx, knativeEventingNamespacedName, client); err != nil {
+	if err := kube.CleanUpNamespace(ctx, knativeEventingNamespacedName, inventory); err != nil {
 		logger.Error(err, "Error occurred when deleting namespace", "NS", knativeEventingNamespacedName)
 		return err
 	}
-	if err := kube.CleanUpNamespace(ctx, knativeServingNamespacedName, client); err != nil {
+	if err := kube.CleanUpNamespace(ctx, knativeServingNamespacedName, inventory); err != nil {
 		logger.Error(err, "Error occurred when deleting namespace", "NS", knativeServingNamespacedName)
 		return err
 	}
 
 	// remove operator namespace
-	if err := kube.CleanUpNamespace(ctx, knativeOperatorNamespace, client); err != nil {
+	if err := kube.CleanUpNamespace(ctx, knativeOperatorNamespace, inventory); err != nil {
 		logger.Error(err, "Error occurred when deleting namespace", "NS", knativeOperatorNamespace)
 		return err
 	}
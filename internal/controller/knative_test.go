@@ -0,0 +1,86 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/rhdhorchestrator/orchestrator-operator/internal/controller/kube"
+	"github.com/rhdhorchestrator/orchestrator-operator/internal/controller/util"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/record"
+	knative "knative.dev/operator/pkg/apis/operator/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestHandleKnativeServingCR(t *testing.T) {
+	ctx := context.TODO()
+	scheme := runtime.NewScheme()
+	utilruntime.Must(corev1.AddToScheme(scheme))
+	utilruntime.Must(knative.AddToScheme(scheme))
+
+	liveServingCR := &knative.KnativeServing{
+		ObjectMeta: metav1.ObjectMeta{Name: knativeServingNamespacedName, Namespace: knativeServingNamespacedName},
+	}
+	servingNamespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: knativeServingNamespacedName, Labels: kube.AddLabel()}}
+
+	testCases := []struct {
+		name             string
+		serving          string
+		expectErr        bool
+		expectedReplicas *int32
+		expectedEvents   int
+	}{
+		{
+			name:           "Unset passthrough keeps the live spec",
+			expectedEvents: 0,
+		},
+		{
+			name: "Passthrough is applied to the live spec",
+			serving: `{"high-availability": {"replicas": 2},
+				"config": {"features": {"kubernetes.podspec-init-containers": "enabled"}}}`,
+			expectedReplicas: util.MakePointer(int32(2)),
+			expectedEvents:   1,
+		},
+		{
+			name:      "Unknown fields are rejected",
+			serving:   `{"highAvailability": {"replicas": 2}}`,
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).
+				WithObjects(liveServingCR.DeepCopy(), servingNamespace.DeepCopy()).
+				Build()
+			recorder := record.NewFakeRecorder(10)
+			orchestrator := newTestOrchestrator()
+			if tc.serving != "" {
+				orchestrator.Spec.ServerlessOperator.Serving = &runtime.RawExtension{Raw: []byte(tc.serving)}
+			}
+
+			err := handleKnativeServingCR(ctx, fakeClient, recorder, orchestrator)
+			if tc.expectErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+
+			updated := &knative.KnativeServing{}
+			assert.NoError(t, fakeClient.Get(ctx,
+				types.NamespacedName{Name: knativeServingNamespacedName, Namespace: knativeServingNamespacedName}, updated))
+			if tc.expectedReplicas == nil {
+				assert.Nil(t, updated.Spec.HighAvailability)
+			} else {
+				assert.Equal(t, *tc.expectedReplicas, *updated.Spec.HighAvailability.Replicas)
+				assert.Equal(t, "enabled", updated.Spec.Config["features"]["kubernetes.podspec-init-containers"])
+			}
+			assert.Len(t, recorder.Events, tc.expectedEvents)
+		})
+	}
+}
@@ -0,0 +1,132 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/blang/semver/v4"
+	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// copiedCSVLabelKey labels the copies of a ClusterServiceVersion made by OLM in the namespaces the operator watches.
+const copiedCSVLabelKey = "olm.copiedFrom"
+
+// OperatorDetection identifies an operator used by the Orchestrator on the cluster.
+type OperatorDetection struct {
+	// Name of the operator reported in the Orchestrator status
+	Name string
+	// CRDName is a CRD owned by the operator
+	CRDName string
+	// SubscriptionName and SubscriptionNamespace locate the Subscription created by the Orchestrator
+	SubscriptionName      string
+	SubscriptionNamespace string
+}
+
+// DetectedOperator describes an operator installed on the cluster without the Subscription of the Orchestrator.
+// The fields are empty when the operator was not installed by OLM.
+type DetectedOperator struct {
+	CSV       string
+	Namespace string
+	Version   *semver.Version
+	// csv is checked for readiness by CheckReady
+	csv *operatorsv1alpha1.ClusterServiceVersion
+}
+
+// DetectOperator finds the operator of detection installed on the cluster by another mechanism than the Subscription
+// of the Orchestrator: the ClusterServiceVersion owning its CRD, or the CRD alone when OLM did not install it.
+// It returns nil when the CRD does not exist, or when the operator is installed by the Subscription of the Orchestrator.
+func DetectOperator(
+	ctx context.Context, k8client client.Client, installedCSVs *InstalledCSVs,
+	detection OperatorDetection) (*DetectedOperator, error) {
+	logger := log.FromContext(ctx)
+
+	subscription, err := installedCSVs.GetSubscription(ctx, detection.SubscriptionNamespace, detection.SubscriptionName)
+	if err != nil {
+		logger.Error(err, "Error occurred when retrieving subscription", "SubscriptionName", detection.SubscriptionName)
+		return nil, err
+	}
+	if subscription != nil && CheckLabelExist(subscription.Labels) {
+		return nil, nil
+	}
+
+	if err := CheckCRDExists(ctx, k8client, detection.CRDName); err != nil {
+		if apierrors.IsNotFound(err) {
+			logger.Info("No operator detected", "Operator", detection.Name, "CRD", detection.CRDName)
+			return nil, nil
+		}
+		logger.Error(err, "Error occurred when retrieving CRD", "CRD", detection.CRDName)
+		return nil, err
+	}
+
+	csvs, err := installedCSVs.List(ctx)
+	if err != nil {
+		// OLM is not installed on the cluster
+		if apierrors.IsNotFound(err) {
+			return &DetectedOperator{}, nil
+		}
+		logger.Error(err, "Error occurred when listing CSVs")
+		return nil, err
+	}
+	detected := &DetectedOperator{}
+	for i := range csvs {
+		csv := &csvs[i]
+		if !ownsCRD(csv, detection.CRDName) {
+			continue
+		}
+		// during an upgrade the CRD is owned by the replaced and the new CSV
+		if detected.csv == nil || csv.Status.Phase == operatorsv1alpha1.CSVPhaseSucceeded {
+			detected = &DetectedOperator{CSV: csv.Name, Namespace: csv.Namespace, csv: csv}
+			if version := csv.Spec.Version.Version; !version.Equals(semver.Version{}) {
+				detected.Version = &version
+			}
+		}
+	}
+	logger.Info("Detected operator", "Operator", detection.Name, "CSV", detected.CSV, "Namespace", detected.Namespace)
+	return detected, nil
+}
+
+func ownsCRD(csv *operatorsv1alpha1.ClusterServiceVersion, crdName string) bool {
+	for _, owned := range csv.Spec.CustomResourceDefinitions.Owned {
+		if owned.Name == crdName {
+			return true
+		}
+	}
+	return false
+}
+
+// CheckCompatible returns an error when the version of the operator is not in the supported versions, described
+// by supportedText. Operators of unknown version are accepted.
+func (d *DetectedOperator) CheckCompatible(detection OperatorDetection, supported semver.Range, supportedText string) error {
+	if d.Version == nil || supported(*d.Version) {
+		return nil
+	}
+	return fmt.Errorf("the %s operator %s is not compatible: version %s is not in the supported versions %s",
+		detection.Name, FormatObjectName(d.Namespace, d.CSV), d.Version, supportedText)
+}
+
+// CheckReady returns a NotReadyError until the ClusterServiceVersion of the operator has succeeded.
+func (d *DetectedOperator) CheckReady() error {
+	if d.csv == nil {
+		return nil
+	}
+	return checkCSVPhase(d.csv)
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube
+
+import (
+	"context"
+	"testing"
+
+	"github.com/blang/semver/v4"
+	"github.com/operator-framework/api/pkg/lib/version"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	olmclientsetfake "github.com/operator-framework/operator-lifecycle-manager/pkg/api/client/clientset/versioned/fake"
+	"github.com/stretchr/testify/assert"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestDetectOperator(t *testing.T) {
+	ctx := context.TODO()
+	scheme := runtime.NewScheme()
+	utilruntime.Must(apiextensionsv1.AddToScheme(scheme))
+
+	crdName := "operands.example.com"
+	detection := OperatorDetection{
+		Name:                  "Example",
+		CRDName:               crdName,
+		SubscriptionName:      subscriptionName,
+		SubscriptionNamespace: orchestratorNamespace,
+	}
+	supported := semver.MustParseRange("1.35.x")
+	crd := &apiextensionsv1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: crdName}}
+	newCSV := func(namespace, csvVersion string, labels map[string]string) *v1alpha1.ClusterServiceVersion {
+		return &v1alpha1.ClusterServiceVersion{
+			ObjectMeta: metav1.ObjectMeta{Name: "example-operator.v" + csvVersion, Namespace: namespace, Labels: labels},
+			Spec: v1alpha1.ClusterServiceVersionSpec{
+				Version: version.OperatorVersion{Version: semver.MustParse(csvVersion)},
+				CustomResourceDefinitions: v1alpha1.CustomResourceDefinitions{
+					Owned: []v1alpha1.CRDDescription{{Name: crdName}},
+				},
+			},
+			Status: v1alpha1.ClusterServiceVersionStatus{Phase: v1alpha1.CSVPhaseSucceeded},
+		}
+	}
+	userSubscription := subscription.DeepCopy()
+	userSubscription.Labels = nil
+
+	testCases := []struct {
+		name               string
+		objects            []client.Object
+		olmObjects         []runtime.Object
+		expectedDetected   *DetectedOperator
+		expectIncompatible bool
+	}{
+		{
+			name: "No operator installed",
+		},
+		{
+			name:       "Operator installed by the Orchestrator",
+			objects:    []client.Object{crd},
+			olmObjects: []runtime.Object{subscription, newCSV(orchestratorNamespace, "1.35.0", nil)},
+		},
+		{
+			name:             "Operator installed without OLM",
+			objects:          []client.Object{crd},
+			expectedDetected: &DetectedOperator{},
+		},
+		{
+			name:    "Operator installed by another Subscription",
+			objects: []client.Object{crd},
+			olmObjects: []runtime.Object{userSubscription, newCSV("operators", "1.35.2", nil),
+				newCSV(orchestratorNamespace, "1.35.2", map[string]string{copiedCSVLabelKey: "operators"})},
+			expectedDetected: &DetectedOperator{CSV: "example-operator.v1.35.2", Namespace: "operators"},
+		},
+		{
+			name:               "Operator version not supported",
+			objects:            []client.Object{crd},
+			olmObjects:         []runtime.Object{newCSV("operators", "1.34.2", nil)},
+			expectedDetected:   &DetectedOperator{CSV: "example-operator.v1.34.2", Namespace: "operators"},
+			expectIncompatible: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(tc.objects...).Build()
+			olmClientSet := olmclientsetfake.NewSimpleClientset(tc.olmObjects...)
+
+			detected, err := DetectOperator(ctx, fakeClient, NewInstalledCSVs(olmClientSet), detection)
+			assert.NoError(t, err)
+			if tc.expectedDetected == nil {
+				assert.Nil(t, detected)
+				return
+			}
+			assert.NotNil(t, detected)
+			assert.Equal(t, tc.expectedDetected.CSV, detected.CSV)
+			assert.Equal(t, tc.expectedDetected.Namespace, detected.Namespace)
+			assert.NoError(t, detected.CheckReady())
+			if tc.expectIncompatible {
+				assert.EqualError(t, detected.CheckCompatible(detection, supported, "1.35.x"),
+					"the Example operator operators/example-operator.v1.34.2 "+
+						"is not compatible: version 1.34.2 is not in the supported versions 1.35.x")
+			} else {
+				assert.NoError(t, detected.CheckCompatible(detection, supported, "1.35.x"))
+			}
+		})
+	}
+}
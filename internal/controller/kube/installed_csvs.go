@@ -0,0 +1,110 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube
+
+import (
+	"context"
+
+	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	olmclientset "github.com/operator-framework/operator-lifecycle-manager/pkg/api/client/clientset/versioned"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// InstalledCSVs reads the Subscriptions and ClusterServiceVersions of OLM for a single reconciliation, so that the
+// detection of the operators, their metrics and the compatibility check share the same requests.
+// The ClusterServiceVersions are listed once in all namespaces. The Subscriptions are read on first use and kept
+// once found, as the Orchestrator creates them during the reconciliation.
+type InstalledCSVs struct {
+	olmClientSet  olmclientset.Interface
+	subscriptions map[types.NamespacedName]*operatorsv1alpha1.Subscription
+	csvs          []operatorsv1alpha1.ClusterServiceVersion
+	listed        bool
+}
+
+func NewInstalledCSVs(olmClientSet olmclientset.Interface) *InstalledCSVs {
+	return &InstalledCSVs{
+		olmClientSet:  olmClientSet,
+		subscriptions: map[types.NamespacedName]*operatorsv1alpha1.Subscription{},
+	}
+}
+
+// GetSubscription returns the Subscription, or nil when it does not exist.
+func (c *InstalledCSVs) GetSubscription(ctx context.Context, namespace, name string) (*operatorsv1alpha1.Subscription, error) {
+	key := types.NamespacedName{Namespace: namespace, Name: name}
+	if subscription, found := c.subscriptions[key]; found {
+		return subscription, nil
+	}
+	subscription, err := c.olmClientSet.OperatorsV1alpha1().Subscriptions(namespace).Get(ctx, name, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	c.subscriptions[key] = subscription
+	return subscription, nil
+}
+
+// List returns the ClusterServiceVersions of all namespaces, without the copies made by OLM in the namespaces the
+// operators watch. It returns a NotFound error when OLM is not installed on the cluster.
+func (c *InstalledCSVs) List(ctx context.Context) ([]operatorsv1alpha1.ClusterServiceVersion, error) {
+	if c.listed {
+		return c.csvs, nil
+	}
+	csvList, err := c.olmClientSet.OperatorsV1alpha1().ClusterServiceVersions(metav1.NamespaceAll).List(
+		ctx, metav1.ListOptions{LabelSelector: "!" + copiedCSVLabelKey})
+	if err != nil {
+		return nil, err
+	}
+	c.csvs = csvList.Items
+	c.listed = true
+	return c.csvs, nil
+}
+
+// GetInstalledCSV returns the ClusterServiceVersion installed by the Subscription, or nil when the Subscription
+// does not exist or has not installed it yet.
+func (c *InstalledCSVs) GetInstalledCSV(
+	ctx context.Context, namespace, subscriptionName string) (*operatorsv1alpha1.ClusterServiceVersion, error) {
+	subscription, err := c.GetSubscription(ctx, namespace, subscriptionName)
+	if err != nil || subscription == nil || subscription.Status.InstalledCSV == "" {
+		return nil, err
+	}
+	csvs, err := c.List(ctx)
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	for i := range csvs {
+		if csvs[i].Namespace == namespace && csvs[i].Name == subscription.Status.InstalledCSV {
+			return &csvs[i], nil
+		}
+	}
+	// the ClusterServiceVersion was created after the list
+	csv, err := c.olmClientSet.OperatorsV1alpha1().ClusterServiceVersions(namespace).Get(
+		ctx, subscription.Status.InstalledCSV, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return csv, nil
+}
@@ -0,0 +1,36 @@
+package kube
+
+import (
+	"context"
+	"testing"
+
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	olmclientsetfake "github.com/operator-framework/operator-lifecycle-manager/pkg/api/client/clientset/versioned/fake"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestInstalledCSVs(t *testing.T) {
+	ctx := context.TODO()
+	installedSubscription := subscription.DeepCopy()
+	installedSubscription.Status.InstalledCSV = "example-operator.v1.35.0"
+	csv := &v1alpha1.ClusterServiceVersion{
+		ObjectMeta: metav1.ObjectMeta{Name: "example-operator.v1.35.0", Namespace: orchestratorNamespace},
+	}
+	olmClientSet := olmclientsetfake.NewSimpleClientset(installedSubscription, csv)
+	installedCSVs := NewInstalledCSVs(olmClientSet)
+
+	for i := 0; i < 2; i++ {
+		installed, err := installedCSVs.GetInstalledCSV(ctx, orchestratorNamespace, subscriptionName)
+		assert.NoError(t, err)
+		assert.NotNil(t, installed)
+		assert.Equal(t, csv.Name, installed.Name)
+
+		missing, err := installedCSVs.GetInstalledCSV(ctx, orchestratorNamespace, "missing")
+		assert.NoError(t, err)
+		assert.Nil(t, missing)
+	}
+	// the Subscription is read once and the ClusterServiceVersions are listed once; the missing Subscription is
+	// read again, as it may be created during the reconciliation
+	assert.Len(t, olmClientSet.Actions(), 4)
+}
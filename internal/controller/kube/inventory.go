@@ -0,0 +1,373 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	olmclientset "github.com/operator-framework/operator-lifecycle-manager/pkg/api/client/clientset/versioned"
+	orchestratorv1alpha2 "github.com/rhdhorchestrator/orchestrator-operator/api/v1alpha3"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// OwnerAnnotationKey marks the objects created for an Orchestrator, as <namespace>/<name>
+	OwnerAnnotationKey = "rhdh.redhat.com/orchestrator"
+
+	subscriptionKind = "Subscription"
+	namespaceKind    = "Namespace"
+)
+
+// InventoryClient is a client which records the objects it creates in the inventory of the Orchestrator status.
+// The created objects are annotated with the Orchestrator, and owned by it when they live in its namespace.
+// Annotated objects read through the client are recorded as well, so that an inventory lost by a failed
+// status update is rebuilt by the next reconciliation, and so are the objects labelled by earlier releases.
+// The creations and deletions are reported by Events on the Orchestrator, with the <Kind>Created and
+// <Kind>Deleted reasons.
+// The apiReader reads the objects which the cache of the operator may not hold, as it caches only the labelled
+// objects of some types.
+type InventoryClient struct {
+	client.Client
+	apiReader     client.Reader
+	olmClientSet  olmclientset.Interface
+	installedCSVs *InstalledCSVs
+	recorder      record.EventRecorder
+	owner         *orchestratorv1alpha2.Orchestrator
+}
+
+func NewInventoryClient(
+	k8client client.Client, apiReader client.Reader, olmClientSet olmclientset.Interface, recorder record.EventRecorder,
+	owner *orchestratorv1alpha2.Orchestrator) *InventoryClient {
+	return &InventoryClient{Client: k8client, apiReader: apiReader, olmClientSet: olmClientSet,
+		installedCSVs: NewInstalledCSVs(olmClientSet), recorder: recorder, owner: owner}
+}
+
+// NotManagedError reports an object which exists without the label and the annotation of the operator, so that the
+// operator neither sees it in its cache nor creates its own object in its place.
+type NotManagedError struct {
+	Kind string
+	Name string
+}
+
+func (e *NotManagedError) Error() string {
+	return fmt.Sprintf("%s %s already exists and is not managed by the operator: delete it, or label it %s=%s",
+		e.Kind, e.Name, CreatedByLabelKey, CreatedByLabelValue)
+}
+
+func IsNotManaged(err error) bool {
+	var notManaged *NotManagedError
+	return errors.As(err, &notManaged)
+}
+
+// InstalledCSVs returns the Subscriptions and ClusterServiceVersions read during the reconciliation of the Orchestrator.
+func (c *InventoryClient) InstalledCSVs() *InstalledCSVs {
+	return c.installedCSVs
+}
+
+// FormatObjectName returns namespace/name, or name for cluster scoped objects.
+func FormatObjectName(namespace, name string) string {
+	return strings.TrimPrefix(namespace+"/"+name, "/")
+}
+
+func (c *InventoryClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	if err := c.Client.Get(ctx, key, obj, opts...); err != nil {
+		return err
+	}
+	return c.Adopt(ctx, obj)
+}
+
+// Adopt records obj when it was created for the Orchestrator. The objects labelled by an earlier release of the
+// operator, which did not annotate them, are annotated first, so that existing installations migrate into the
+// inventory.
+func (c *InventoryClient) Adopt(ctx context.Context, obj client.Object) error {
+	if _, annotated := obj.GetAnnotations()[OwnerAnnotationKey]; !annotated && CheckLabelExist(obj.GetLabels()) {
+		original := obj.DeepCopyObject().(client.Object)
+		if err := c.Prepare(obj); err != nil {
+			return err
+		}
+		if err := c.Client.Patch(ctx, obj, client.MergeFrom(original)); err != nil {
+			return err
+		}
+		log.FromContext(ctx).Info("Adopted object labelled by an earlier release", "Object",
+			FormatObjectName(obj.GetNamespace(), obj.GetName()))
+	}
+	if obj.GetAnnotations()[OwnerAnnotationKey] == c.ownerKey() {
+		c.record(obj)
+	}
+	return nil
+}
+
+func (c *InventoryClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	if err := c.Prepare(obj); err != nil {
+		return err
+	}
+	if err := c.Client.Create(ctx, obj, opts...); err != nil {
+		if apierrors.IsAlreadyExists(err) {
+			return c.checkManaged(ctx, obj, err)
+		}
+		return err
+	}
+	c.Record(obj)
+	return nil
+}
+
+// checkManaged returns a NotManagedError when the object which already exists was not created by the operator.
+// Otherwise the cache has not caught up with the creation yet, and err is returned.
+func (c *InventoryClient) checkManaged(ctx context.Context, obj client.Object, err error) error {
+	gvk, gvkErr := apiutil.GVKForObject(obj, c.Scheme())
+	if gvkErr != nil {
+		return err
+	}
+	existing := &metav1.PartialObjectMetadata{}
+	existing.SetGroupVersionKind(gvk)
+	if getErr := c.apiReader.Get(ctx, client.ObjectKeyFromObject(obj), existing); getErr != nil {
+		return err
+	}
+	if CheckLabelExist(existing.GetLabels()) || IsOwnedBy(existing, c.owner) {
+		return err
+	}
+	return &NotManagedError{Kind: gvk.Kind, Name: FormatObjectName(obj.GetNamespace(), obj.GetName())}
+}
+
+func (c *InventoryClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	err := c.Client.Delete(ctx, obj, opts...)
+	if err == nil || apierrors.IsNotFound(err) {
+		c.remove(obj)
+	}
+	if err == nil {
+		if entry, ok := c.entryFor(obj); ok {
+			c.eventFor(entry, "Deleted")
+		}
+	}
+	return err
+}
+
+// Prepare annotates obj with the Orchestrator before its creation, and sets the Orchestrator as its owner
+// when obj lives in the namespace of the Orchestrator.
+func (c *InventoryClient) Prepare(obj client.Object) error {
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[OwnerAnnotationKey] = c.ownerKey()
+	obj.SetAnnotations(annotations)
+
+	if obj.GetNamespace() != "" && obj.GetNamespace() == c.owner.Namespace {
+		return controllerutil.SetOwnerReference(c.owner, obj, c.Scheme())
+	}
+	return nil
+}
+
+// Record adds obj to the inventory, and reports its creation; it is used for the objects created through
+// other clients.
+func (c *InventoryClient) Record(obj client.Object) {
+	if entry, ok := c.record(obj); ok {
+		c.eventFor(entry, "Created")
+	}
+}
+
+func (c *InventoryClient) record(obj client.Object) (orchestratorv1alpha2.InventoryEntry, bool) {
+	entry, ok := c.entryFor(obj)
+	if ok && !slices.Contains(c.owner.Status.Inventory, entry) {
+		c.owner.Status.Inventory = append(c.owner.Status.Inventory, entry)
+	}
+	return entry, ok
+}
+
+// eventFor reports the action on the object of the entry, with the <Kind><action> reason.
+func (c *InventoryClient) eventFor(entry orchestratorv1alpha2.InventoryEntry, action string) {
+	if c.recorder == nil {
+		return
+	}
+	c.recorder.Eventf(c.owner, corev1.EventTypeNormal, entry.Kind+action, "%s %s %s",
+		action, entry.Kind, FormatObjectName(entry.Namespace, entry.Name))
+}
+
+func (c *InventoryClient) remove(obj client.Object) {
+	if entry, ok := c.entryFor(obj); ok {
+		c.removeEntry(entry)
+	}
+}
+
+func (c *InventoryClient) removeEntry(entry orchestratorv1alpha2.InventoryEntry) {
+	c.owner.Status.Inventory = slices.DeleteFunc(c.owner.Status.Inventory, func(e orchestratorv1alpha2.InventoryEntry) bool {
+		return e == entry
+	})
+}
+
+func (c *InventoryClient) entryFor(obj client.Object) (orchestratorv1alpha2.InventoryEntry, bool) {
+	gvk, err := apiutil.GVKForObject(obj, c.Scheme())
+	if err != nil {
+		return orchestratorv1alpha2.InventoryEntry{}, false
+	}
+	return orchestratorv1alpha2.InventoryEntry{
+		APIVersion: gvk.GroupVersion().String(),
+		Kind:       gvk.Kind,
+		Namespace:  obj.GetNamespace(),
+		Name:       obj.GetName(),
+	}, true
+}
+
+func (c *InventoryClient) ownerKey() string {
+	return getOwnerKey(c.owner)
+}
+
+// IsOwnedBy reports whether obj was created for the Orchestrator owner, from its owner annotation.
+func IsOwnedBy(obj client.Object, owner *orchestratorv1alpha2.Orchestrator) bool {
+	return obj.GetAnnotations()[OwnerAnnotationKey] == getOwnerKey(owner)
+}
+
+// GetOwner returns the Orchestrator obj was created for, from its owner annotation.
+func GetOwner(obj client.Object) (types.NamespacedName, bool) {
+	namespace, name, found := strings.Cut(obj.GetAnnotations()[OwnerAnnotationKey], "/")
+	if !found || name == "" {
+		return types.NamespacedName{}, false
+	}
+	return types.NamespacedName{Namespace: namespace, Name: name}, true
+}
+
+func getOwnerKey(owner *orchestratorv1alpha2.Orchestrator) string {
+	return owner.Namespace + "/" + owner.Name
+}
+
+// SelectInventory returns the inventory entries matching selected, in the order DeleteInventory deletes them:
+// the reverse order of their creation, with the namespaces after the other objects.
+func (c *InventoryClient) SelectInventory(selected func(entry orchestratorv1alpha2.InventoryEntry) bool) []orchestratorv1alpha2.InventoryEntry {
+	var entries, namespaces []orchestratorv1alpha2.InventoryEntry
+	inventory := c.owner.Status.Inventory
+	for i := len(inventory) - 1; i >= 0; i-- {
+		entry := inventory[i]
+		if !selected(entry) {
+			continue
+		}
+		if IsNamespaceEntry(entry) {
+			namespaces = append(namespaces, entry)
+		} else {
+			entries = append(entries, entry)
+		}
+	}
+	return append(entries, namespaces...)
+}
+
+// IsNamespaceEntry reports whether the inventory entry is a Namespace.
+func IsNamespaceEntry(entry orchestratorv1alpha2.InventoryEntry) bool {
+	return entry.Kind == namespaceKind && entry.APIVersion == "v1"
+}
+
+// ForgetNamespace drops the namespace and the objects in it from the inventory, so that they are never deleted.
+// It is used for the namespaces of the operators adopted by the Orchestrator.
+func (c *InventoryClient) ForgetNamespace(namespace string) {
+	for _, entry := range c.SelectInventory(func(entry orchestratorv1alpha2.InventoryEntry) bool {
+		return entry.Namespace == namespace || (IsNamespaceEntry(entry) && entry.Name == namespace)
+	}) {
+		c.removeEntry(entry)
+	}
+}
+
+// DeleteInventory deletes the inventory entries matching selected, in the order of SelectInventory.
+// The operator installed by a Subscription is removed with its ClusterServiceVersion. The entries are dropped
+// from the inventory once their object is gone, or its type is no longer served; the entries whose object is
+// still terminating are returned as pending.
+func (c *InventoryClient) DeleteInventory(
+	ctx context.Context, selected func(entry orchestratorv1alpha2.InventoryEntry) bool) ([]orchestratorv1alpha2.InventoryEntry, error) {
+	logger := log.FromContext(ctx)
+
+	var pending []orchestratorv1alpha2.InventoryEntry
+	for _, entry := range c.SelectInventory(selected) {
+		obj := &unstructured.Unstructured{}
+		obj.SetGroupVersionKind(schema.FromAPIVersionAndKind(entry.APIVersion, entry.Kind))
+		obj.SetNamespace(entry.Namespace)
+		obj.SetName(entry.Name)
+
+		// the namespaces without the label of the operator were created or adopted by the user
+		if IsNamespaceEntry(entry) {
+			if err := c.Client.Get(ctx, client.ObjectKeyFromObject(obj), obj); err == nil && !CheckLabelExist(obj.GetLabels()) {
+				logger.Info("Keeping namespace which is not labelled by the operator", "NS", entry.Name)
+				c.removeEntry(entry)
+				continue
+			}
+		}
+
+		var err error
+		if entry.Kind == subscriptionKind && obj.GroupVersionKind().Group == operatorsv1alpha1.GroupName {
+			err = CleanUpSubscriptionAndCSV(ctx, c.olmClientSet, &operatorsv1alpha1.Subscription{
+				ObjectMeta: metav1.ObjectMeta{Name: entry.Name, Namespace: entry.Namespace}})
+		} else {
+			err = c.Client.Delete(ctx, obj)
+		}
+		if err != nil && !apierrors.IsNotFound(err) && !meta.IsNoMatchError(err) {
+			logger.Error(err, "Error occurred when deleting inventory object", "Kind", entry.Kind, "NS", entry.Namespace, "Name", entry.Name)
+			return nil, err
+		}
+
+		if err := c.Client.Get(ctx, client.ObjectKeyFromObject(obj), obj); err == nil {
+			pending = append(pending, entry)
+			continue
+		} else if !apierrors.IsNotFound(err) && !meta.IsNoMatchError(err) {
+			return nil, err
+		}
+		logger.Info("Successfully deleted inventory object", "Kind", entry.Kind, "NS", entry.Namespace, "Name", entry.Name)
+		c.removeEntry(entry)
+		c.eventFor(entry, "Deleted")
+	}
+	return pending, nil
+}
+
+// OrphanInventory removes the Orchestrator from the ownerReferences of the inventory entries matching selected,
+// so that the garbage collector keeps them once the Orchestrator is deleted.
+func (c *InventoryClient) OrphanInventory(ctx context.Context, selected func(entry orchestratorv1alpha2.InventoryEntry) bool) error {
+	for _, entry := range c.owner.Status.Inventory {
+		// only the objects in the namespace of the Orchestrator are owned by it
+		if !selected(entry) || entry.Namespace != c.owner.Namespace {
+			continue
+		}
+		obj := &unstructured.Unstructured{}
+		obj.SetGroupVersionKind(schema.FromAPIVersionAndKind(entry.APIVersion, entry.Kind))
+		if err := c.Client.Get(ctx, types.NamespacedName{Name: entry.Name, Namespace: entry.Namespace}, obj); err != nil {
+			if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+				continue
+			}
+			return err
+		}
+		original := obj.DeepCopy()
+		if err := controllerutil.RemoveOwnerReference(c.owner, obj, c.Scheme()); err != nil {
+			// not owned by the Orchestrator
+			continue
+		}
+		if err := c.Client.Patch(ctx, obj, client.MergeFrom(original)); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+		log.FromContext(ctx).Info("Successfully orphaned inventory object", "Kind", entry.Kind, "NS", entry.Namespace, "Name", entry.Name)
+	}
+	return nil
+}
@@ -0,0 +1,252 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube
+
+import (
+	"context"
+	"testing"
+
+	operatorsv1 "github.com/operator-framework/api/pkg/operators/v1"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	olmclientsetfake "github.com/operator-framework/operator-lifecycle-manager/pkg/api/client/clientset/versioned/fake"
+	orchestratorv1alpha2 "github.com/rhdhorchestrator/orchestrator-operator/api/v1alpha3"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func newTestInventoryClient(
+	olmClientSet *olmclientsetfake.Clientset, objects ...runtime.Object) (*InventoryClient, *orchestratorv1alpha2.Orchestrator) {
+	scheme := runtime.NewScheme()
+	utilruntime.Must(corev1.AddToScheme(scheme))
+	utilruntime.Must(v1alpha1.AddToScheme(scheme))
+	utilruntime.Must(operatorsv1.AddToScheme(scheme))
+	utilruntime.Must(orchestratorv1alpha2.AddToScheme(scheme))
+
+	orchestrator := &orchestratorv1alpha2.Orchestrator{
+		ObjectMeta: metav1.ObjectMeta{Name: "orchestrator", Namespace: orchestratorNamespace, UID: "orchestrator-uid"},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithRuntimeObjects(objects...).Build()
+	return NewInventoryClient(fakeClient, fakeClient, olmClientSet, nil, orchestrator), orchestrator
+}
+
+func TestInventoryClientRecordsObjects(t *testing.T) {
+	ctx := context.TODO()
+	inventory, orchestrator := newTestInventoryClient(olmclientsetfake.NewSimpleClientset())
+
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "created-namespace", Labels: AddLabel()}}
+	configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "created", Namespace: orchestratorNamespace}}
+	assert.NoError(t, inventory.Create(ctx, namespace))
+	assert.NoError(t, inventory.Create(ctx, configMap))
+
+	assert.Equal(t, []orchestratorv1alpha2.InventoryEntry{
+		{APIVersion: "v1", Kind: "Namespace", Name: namespace.Name},
+		{APIVersion: "v1", Kind: "ConfigMap", Namespace: orchestratorNamespace, Name: configMap.Name},
+	}, orchestrator.Status.Inventory)
+	assert.Equal(t, "orchestrator-namespace/orchestrator", configMap.Annotations[OwnerAnnotationKey])
+	assert.Empty(t, namespace.OwnerReferences, "Cluster scoped objects cannot be owned by the Orchestrator")
+	assert.Len(t, configMap.OwnerReferences, 1)
+	assert.Equal(t, orchestrator.Name, configMap.OwnerReferences[0].Name)
+
+	// an inventory lost by a failed status update is rebuilt from the annotated objects
+	orchestrator.Status.Inventory = nil
+	assert.NoError(t, inventory.Get(ctx, types.NamespacedName{Name: configMap.Name, Namespace: orchestratorNamespace}, &corev1.ConfigMap{}))
+	assert.Len(t, orchestrator.Status.Inventory, 1)
+
+	assert.NoError(t, inventory.Delete(ctx, configMap))
+	assert.Empty(t, orchestrator.Status.Inventory)
+}
+
+func TestInventoryClientAdoptsLabelledObjects(t *testing.T) {
+	ctx := context.TODO()
+	// objects created by an earlier release are labelled, without the annotation of the Orchestrator
+	labelledConfigMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Name: "labelled", Namespace: orchestratorNamespace, Labels: AddLabel()}}
+	userConfigMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "user", Namespace: orchestratorNamespace}}
+	otherConfigMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Name: "other", Namespace: orchestratorNamespace, Labels: AddLabel(),
+		Annotations: map[string]string{OwnerAnnotationKey: "other-namespace/other"}}}
+	inventory, orchestrator := newTestInventoryClient(olmclientsetfake.NewSimpleClientset(),
+		labelledConfigMap, userConfigMap, otherConfigMap)
+
+	for _, name := range []string{labelledConfigMap.Name, userConfigMap.Name, otherConfigMap.Name} {
+		assert.NoError(t, inventory.Get(ctx, types.NamespacedName{Name: name, Namespace: orchestratorNamespace}, &corev1.ConfigMap{}))
+	}
+	assert.Equal(t, []orchestratorv1alpha2.InventoryEntry{
+		{APIVersion: "v1", Kind: "ConfigMap", Namespace: orchestratorNamespace, Name: labelledConfigMap.Name},
+	}, orchestrator.Status.Inventory, "Only the labelled objects without owner are adopted")
+
+	adopted := &corev1.ConfigMap{}
+	assert.NoError(t, inventory.Client.Get(ctx, client.ObjectKeyFromObject(labelledConfigMap), adopted))
+	assert.Equal(t, "orchestrator-namespace/orchestrator", adopted.Annotations[OwnerAnnotationKey])
+	assert.Len(t, adopted.OwnerReferences, 1)
+	user := &corev1.ConfigMap{}
+	assert.NoError(t, inventory.Client.Get(ctx, client.ObjectKeyFromObject(userConfigMap), user))
+	assert.Empty(t, user.Annotations)
+}
+
+func TestInventoryClientReportsObjectsNotManaged(t *testing.T) {
+	ctx := context.TODO()
+	userConfigMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "user", Namespace: orchestratorNamespace}}
+	inventory, orchestrator := newTestInventoryClient(olmclientsetfake.NewSimpleClientset(), userConfigMap)
+	// the cache holds only the labelled objects
+	inventory.Client = interceptor.NewClient(inventory.Client.(client.WithWatch), interceptor.Funcs{
+		Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+			if err := c.Get(ctx, key, obj, opts...); err != nil {
+				return err
+			}
+			if !CheckLabelExist(obj.GetLabels()) {
+				return apierrors.NewNotFound(schema.GroupResource{Resource: "configmaps"}, key.Name)
+			}
+			return nil
+		},
+	})
+
+	configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: userConfigMap.Name, Namespace: orchestratorNamespace, Labels: AddLabel()}}
+	assert.True(t, apierrors.IsNotFound(inventory.Get(ctx, client.ObjectKeyFromObject(configMap), &corev1.ConfigMap{})))
+	err := inventory.Create(ctx, configMap)
+	assert.True(t, IsNotManaged(err))
+	assert.EqualError(t, err, "ConfigMap orchestrator-namespace/user already exists and is not managed by the operator: "+
+		"delete it, or label it rhdh.redhat.com/created-by=orchestrator")
+	assert.Empty(t, orchestrator.Status.Inventory)
+
+	// the objects created by the operator, which the cache has not caught up with yet, are retried
+	newConfigMap := func() *corev1.ConfigMap {
+		return &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "created", Namespace: orchestratorNamespace, Labels: AddLabel()}}
+	}
+	assert.NoError(t, inventory.Create(ctx, newConfigMap()))
+	err = inventory.Create(ctx, newConfigMap())
+	assert.True(t, apierrors.IsAlreadyExists(err))
+	assert.False(t, IsNotManaged(err))
+}
+
+func TestInventoryClientEvents(t *testing.T) {
+	ctx := context.TODO()
+	inventory, _ := newTestInventoryClient(olmclientsetfake.NewSimpleClientset())
+	recorder := record.NewFakeRecorder(10)
+	inventory.recorder = recorder
+
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "created-namespace", Labels: AddLabel()}}
+	assert.NoError(t, inventory.Create(ctx, namespace))
+	assert.Equal(t, "Normal NamespaceCreated Created Namespace created-namespace", <-recorder.Events)
+
+	// objects read through the client are recorded without any Event
+	assert.NoError(t, inventory.Get(ctx, types.NamespacedName{Name: namespace.Name}, &corev1.Namespace{}))
+	assert.Empty(t, recorder.Events)
+
+	_, err := inventory.DeleteInventory(ctx, func(orchestratorv1alpha2.InventoryEntry) bool { return true })
+	assert.NoError(t, err)
+	assert.Equal(t, "Normal NamespaceDeleted Deleted Namespace created-namespace", <-recorder.Events)
+}
+
+func TestInventoryClientRecordsSubscription(t *testing.T) {
+	ctx := context.TODO()
+	inventory, orchestrator := newTestInventoryClient(olmclientsetfake.NewSimpleClientset())
+
+	err := InstallSubscriptionAndOperatorGroup(
+		ctx, inventory, olmclientsetfake.NewSimpleClientset(), orchestratorOperatorGroup, subscription.DeepCopy())
+	assert.NoError(t, err)
+	assert.Equal(t, []orchestratorv1alpha2.InventoryEntry{
+		{APIVersion: "operators.coreos.com/v1", Kind: "OperatorGroup", Namespace: orchestratorNamespace, Name: orchestratorOperatorGroup},
+		{APIVersion: "operators.coreos.com/v1alpha1", Kind: "Subscription", Namespace: orchestratorNamespace, Name: subscriptionName},
+	}, orchestrator.Status.Inventory)
+}
+
+func TestDeleteInventory(t *testing.T) {
+	ctx := context.TODO()
+	installedSubscription := subscription.DeepCopy()
+	installedSubscription.Status.InstalledCSV = "operator.v1.0.0"
+	csv := &v1alpha1.ClusterServiceVersion{ObjectMeta: metav1.ObjectMeta{Name: "operator.v1.0.0", Namespace: orchestratorNamespace}}
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: orchestratorNamespace, Labels: AddLabel()}}
+	userConfigMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "user", Namespace: orchestratorNamespace}}
+	// the Subscription and its ClusterServiceVersion are served through the OLM clientset
+	olmClientSet := olmclientsetfake.NewSimpleClientset(installedSubscription, csv)
+	terminatingConfigMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+		Name: "terminating", Namespace: orchestratorNamespace, Finalizers: []string{"example.com/finalizer"}}}
+	inventory, orchestrator := newTestInventoryClient(olmClientSet, namespace, userConfigMap, terminatingConfigMap)
+
+	orchestrator.Status.Inventory = []orchestratorv1alpha2.InventoryEntry{
+		{APIVersion: "v1", Kind: "Namespace", Name: orchestratorNamespace},
+		{APIVersion: "operators.coreos.com/v1alpha1", Kind: "Subscription", Namespace: orchestratorNamespace, Name: subscriptionName},
+		{APIVersion: "v1", Kind: "ConfigMap", Namespace: orchestratorNamespace, Name: "deleted-by-the-user"},
+		{APIVersion: "v1", Kind: "ConfigMap", Namespace: orchestratorNamespace, Name: terminatingConfigMap.Name},
+		{APIVersion: "example.com/v1", Kind: "Unserved", Name: "crd-removed"},
+	}
+
+	selectNamespaced := func(entry orchestratorv1alpha2.InventoryEntry) bool { return entry.Namespace != "" }
+	pending, err := inventory.DeleteInventory(ctx, selectNamespaced)
+	assert.NoError(t, err)
+	assert.Equal(t, []orchestratorv1alpha2.InventoryEntry{
+		{APIVersion: "v1", Kind: "ConfigMap", Namespace: orchestratorNamespace, Name: terminatingConfigMap.Name},
+	}, pending, "The objects held by a finalizer are pending")
+	_, err = olmClientSet.OperatorsV1alpha1().Subscriptions(orchestratorNamespace).Get(ctx, subscriptionName, metav1.GetOptions{})
+	assert.True(t, apierrors.IsNotFound(err))
+	_, err = olmClientSet.OperatorsV1alpha1().ClusterServiceVersions(orchestratorNamespace).Get(ctx, csv.Name, metav1.GetOptions{})
+	assert.True(t, apierrors.IsNotFound(err), "The operator installed by the Subscription is removed")
+	assert.NoError(t, inventory.Get(ctx, types.NamespacedName{Name: orchestratorNamespace}, &corev1.Namespace{}))
+	assert.NoError(t, inventory.Get(ctx, types.NamespacedName{Name: userConfigMap.Name, Namespace: orchestratorNamespace}, &corev1.ConfigMap{}))
+	assert.Len(t, orchestrator.Status.Inventory, 3)
+
+	assert.NoError(t, inventory.Get(ctx, types.NamespacedName{Name: terminatingConfigMap.Name, Namespace: orchestratorNamespace}, terminatingConfigMap))
+	terminatingConfigMap.Finalizers = nil
+	assert.NoError(t, inventory.Update(ctx, terminatingConfigMap))
+	pending, err = inventory.DeleteInventory(ctx, func(orchestratorv1alpha2.InventoryEntry) bool { return true })
+	assert.NoError(t, err)
+	assert.Empty(t, pending)
+	err = inventory.Get(ctx, types.NamespacedName{Name: orchestratorNamespace}, &corev1.Namespace{})
+	assert.True(t, apierrors.IsNotFound(err))
+	assert.Empty(t, orchestrator.Status.Inventory)
+}
+
+func TestDeleteInventoryKeepsUnlabelledNamespaces(t *testing.T) {
+	ctx := context.TODO()
+	// the label was removed by the user to keep the namespace
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: orchestratorNamespace}}
+	inventory, orchestrator := newTestInventoryClient(olmclientsetfake.NewSimpleClientset(), namespace)
+	orchestrator.Status.Inventory = []orchestratorv1alpha2.InventoryEntry{
+		{APIVersion: "v1", Kind: "Namespace", Name: orchestratorNamespace},
+	}
+
+	pending, err := inventory.DeleteInventory(ctx, func(orchestratorv1alpha2.InventoryEntry) bool { return true })
+	assert.NoError(t, err)
+	assert.Empty(t, pending)
+	assert.NoError(t, inventory.Get(ctx, types.NamespacedName{Name: orchestratorNamespace}, &corev1.Namespace{}))
+	assert.Empty(t, orchestrator.Status.Inventory)
+}
+
+func TestForgetNamespace(t *testing.T) {
+	inventory, orchestrator := newTestInventoryClient(olmclientsetfake.NewSimpleClientset())
+	orchestrator.Status.Inventory = []orchestratorv1alpha2.InventoryEntry{
+		{APIVersion: "v1", Kind: "Namespace", Name: orchestratorNamespace},
+		{APIVersion: "operators.coreos.com/v1", Kind: "OperatorGroup", Namespace: orchestratorNamespace, Name: orchestratorOperatorGroup},
+		{APIVersion: "v1", Kind: "Namespace", Name: "other-namespace"},
+	}
+
+	inventory.ForgetNamespace(orchestratorNamespace)
+	assert.Equal(t, []orchestratorv1alpha2.InventoryEntry{
+		{APIVersion: "v1", Kind: "Namespace", Name: "other-namespace"},
+	}, orchestrator.Status.Inventory)
+}
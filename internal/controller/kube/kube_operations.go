@@ -23,11 +23,13 @@ import (
 	"github.com/operator-framework/api/pkg/operators/v1alpha1"
 	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
 	olmclientset "github.com/operator-framework/operator-lifecycle-manager/pkg/api/client/clientset/versioned"
+	orchestratorv1alpha2 "github.com/rhdhorchestrator/orchestrator-operator/api/v1alpha3"
 	corev1 "k8s.io/api/core/v1"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
@@ -47,14 +49,7 @@ func CheckNamespaceExist(ctx context.Context, client client.Client, namespace st
 	if err := client.Get(ctx, types.NamespacedName{Name: namespace}, namespaceObj); err != nil {
 		return false, err
 	}
-	// check and update missing labels
-	labelExist := CheckLabelExist(namespaceObj.Labels)
-	if !labelExist {
-		// update namespace label
-		if err := updateNamespaceLabel(namespaceObj, ctx, client); err != nil {
-			nsLogger.Error(err, "Error occurred when updating namespace label", "NS", namespace)
-		}
-	}
+	// the namespaces created by the user are not labelled, so that they are never deleted
 	return true, nil
 }
 
@@ -71,8 +66,9 @@ func CreateNamespace(ctx context.Context, client client.Client, namespace string
 	return nil
 }
 
+// InstallSubscriptionAndOperatorGroup creates the subscription with the OLM client, and records it in the inventory.
 func InstallSubscriptionAndOperatorGroup(
-	ctx context.Context, client client.Client,
+	ctx context.Context, inventory *InventoryClient,
 	olmClientSet olmclientset.Interface,
 	operatorGroupName string,
 	subscription *v1alpha1.Subscription) error {
@@ -84,11 +80,15 @@ func InstallSubscriptionAndOperatorGroup(
 	namespace := subscription.Namespace
 
 	// check operator group exists
-	err := getOperatorGroup(ctx, client, namespace, operatorGroupName)
+	err := getOperatorGroup(ctx, inventory, namespace, operatorGroupName)
 	if err != nil {
 		logger.Error(err, "Error occurred when checking operator group resource", "OperatorGroup", operatorGroupName)
 		return err
 	}
+	// the subscription is created with the OLM client, outside of the inventory client
+	if err := inventory.Prepare(subscription); err != nil {
+		return err
+	}
 	// create subscription
 	if _, err := olmClientSet.OperatorsV1alpha1().
 		Subscriptions(namespace).
@@ -96,10 +96,18 @@ func InstallSubscriptionAndOperatorGroup(
 		logger.Error(err, "Error occurred while creating Subscription", "SubscriptionName", subscriptionName)
 		return err
 	}
+	inventory.Record(subscription)
 	return nil
 }
 
-func ApproveInstallPlan(client client.Client, ctx context.Context, installPlanName, namespace string) error {
+// InstallPlanPolicy returns an error when the ClusterServiceVersions of an install plan must not be installed.
+type InstallPlanPolicy func(csvNames []string) error
+
+// ApproveInstallPlan approves the install plan, unless its ClusterServiceVersions are refused by the policy.
+// A nil policy accepts every install plan. The approval and the refusal are reported by Events on the Orchestrator.
+func ApproveInstallPlan(
+	client client.Client, ctx context.Context, recorder record.EventRecorder, orchestrator *orchestratorv1alpha2.Orchestrator,
+	installPlanName, namespace string, policy InstallPlanPolicy) error {
 	logger := log.FromContext(ctx)
 	logger.Info("Starting approval for InstallPlan...")
 
@@ -115,6 +123,14 @@ func ApproveInstallPlan(client client.Client, ctx context.Context, installPlanNa
 
 	// Approve the InstallPlan if manual approval is needed
 	if !installPlan.Spec.Approved {
+		if policy != nil {
+			if err := policy(installPlan.Spec.ClusterServiceVersionNames); err != nil {
+				logger.Info("InstallPlan refused by the policy", "InstallPlanName", installPlan.Name, "Reason", err.Error())
+				recorder.Eventf(orchestrator, corev1.EventTypeWarning, "InstallPlanRefused", "Refused InstallPlan %s/%s: %s",
+					namespace, installPlanName, err)
+				return fmt.Errorf("InstallPlan %s/%s is not approved: %w", namespace, installPlanName, err)
+			}
+		}
 		logger.Info("Approving InstallPlan", "InstallPlanName", installPlan.Name)
 		installPlan.Spec.Approved = true
 		if err := client.Update(ctx, installPlan); err != nil {
@@ -122,35 +138,11 @@ func ApproveInstallPlan(client client.Client, ctx context.Context, installPlanNa
 			return err
 		}
 		logger.Info("Successfully approved InstallPlan", "InstallPlanName", installPlan.Name)
+		recorder.Eventf(orchestrator, corev1.EventTypeNormal, "InstallPlanApproved", "Approved InstallPlan %s/%s", namespace, installPlanName)
 	}
 	return nil
 }
 
-func CheckCSVExists(ctx context.Context, client client.Client, installedSubscription *v1alpha1.Subscription) (bool, error) {
-	logger := log.FromContext(ctx)
-
-	installedCSV := installedSubscription.Status.InstalledCSV
-
-	// Check the Subscription's status after installation
-	if installedCSV == "" {
-		logger.Info("Subscription has no installed CSV: CSV not ready or Incorrectly installed subscription", "Subscription", installedSubscription.Name)
-		return false, fmt.Errorf("subscription not ready yet")
-	}
-	// Get the ClusterServiceVersion (CSV) for the Subscription installed
-	sfcsv := &operatorsv1alpha1.ClusterServiceVersion{}
-	if err := client.Get(ctx, types.NamespacedName{Name: installedCSV, Namespace: installedSubscription.Namespace}, sfcsv); err != nil {
-		logger.Error(err, "Error occurred when retrieving CSV", "ClusterServiceVersion", installedCSV)
-		return false, err
-	}
-	// Check if the CSV's phase is Succeeded
-	if sfcsv != nil && sfcsv.Status.Phase == operatorsv1alpha1.CSVPhaseSucceeded {
-		logger.Info("Successfully installed Operator Via Subscription", "SubscriptionName", installedSubscription.Name)
-		return true, nil
-	}
-	return false, fmt.Errorf("failed to install operator via subscription")
-
-}
-
 func getOperatorGroup(ctx context.Context, client client.Client,
 	namespace, operatorGroupName string) error {
 	logger := log.FromContext(ctx)
@@ -174,7 +166,11 @@ func getOperatorGroup(ctx context.Context, client client.Client,
 	return nil
 }
 
-func CreateSubscriptionObject(subscriptionName, namespace, channel, startingCSV string) *v1alpha1.Subscription {
+// CreateSubscriptionObject builds the subscription of an operator. Values set in the subscription config
+// take precedence over the default channel and starting CSV, and over the default catalog source.
+func CreateSubscriptionObject(
+	subscriptionName, namespace, channel, startingCSV string,
+	subscriptionConfig orchestratorv1alpha2.SubscriptionConfig) *v1alpha1.Subscription {
 	logger := log.Log.WithName("subscriptionObject")
 	logger.Info("Creating subscription object")
 
@@ -185,17 +181,24 @@ func CreateSubscriptionObject(subscriptionName, namespace, channel, startingCSV
 			Labels:    AddLabel(),
 		},
 		Spec: &v1alpha1.SubscriptionSpec{
-			Channel:                channel,
-			InstallPlanApproval:    v1alpha1.ApprovalManual,
-			CatalogSource:          CatalogSourceName,
-			StartingCSV:            startingCSV,
-			CatalogSourceNamespace: CatalogSourceNamespace,
+			Channel:                valueOrDefault(subscriptionConfig.Channel, channel),
+			InstallPlanApproval:    v1alpha1.Approval(valueOrDefault(subscriptionConfig.InstallPlanApproval, string(v1alpha1.ApprovalManual))),
+			CatalogSource:          valueOrDefault(subscriptionConfig.Source, CatalogSourceName),
+			StartingCSV:            valueOrDefault(subscriptionConfig.StartingCSV, startingCSV),
+			CatalogSourceNamespace: valueOrDefault(subscriptionConfig.SourceNamespace, CatalogSourceNamespace),
 			Package:                subscriptionName,
 		},
 	}
 	return subscriptionObject
 }
 
+func valueOrDefault(value, defaultValue string) string {
+	if value == "" {
+		return defaultValue
+	}
+	return value
+}
+
 func CheckSubscriptionExists(
 	ctx context.Context, olmClientSet olmclientset.Interface,
 	existingSubscription *v1alpha1.Subscription) (bool, *v1alpha1.Subscription, error) {
@@ -226,75 +229,57 @@ func CheckCRDExists(ctx context.Context, client client.Client, name string) erro
 	return nil
 }
 
-func CleanUpNamespace(ctx context.Context, namespaceName string, client client.Client) error {
+// CleanUpNamespace deletes the inventory objects in the namespace, and the namespace itself when it was created
+// by the operator. Namespaces and objects created by the user are kept.
+func CleanUpNamespace(ctx context.Context, namespaceName string, inventory *InventoryClient) error {
 	logger := log.FromContext(ctx)
-
-	// check namespace exist
-	logger.Info("Checking namespace exist", "Namespace", namespaceName)
-	namespaceObj := &corev1.Namespace{}
-	if err := client.Get(ctx, types.NamespacedName{Name: namespaceName}, namespaceObj); err != nil {
-		if apierrors.IsNotFound(err) {
-			logger.Info("Namespace does not exist", "Namespace", namespaceName)
-			return nil
-		}
-		return err
-	}
-
-	// check label exist
-	labelExist := CheckLabelExist(namespaceObj.Labels)
-
-	if labelExist {
-		// delete namespace
-		if err := client.Delete(ctx, namespaceObj); err != nil && !apierrors.IsNotFound(err) {
-			return err
-		}
-		logger.Info("Successfully deleted Namespace", "Namespace", namespaceName)
-	}
-	return nil
+	logger.Info("Cleaning up namespace", "Namespace", namespaceName)
+	// the objects still terminating stay in the inventory, and are checked again by the next clean up
+	_, err := inventory.DeleteInventory(ctx, func(entry orchestratorv1alpha2.InventoryEntry) bool {
+		return entry.Namespace == namespaceName || (entry.Kind == namespaceKind && entry.Name == namespaceName)
+	})
+	return err
 }
 
+// CleanUpSubscriptionAndCSV deletes the subscription and the ClusterServiceVersion it installed, which OLM keeps
+// when the subscription is deleted.
 func CleanUpSubscriptionAndCSV(ctx context.Context, olmClientSet olmclientset.Interface, subscription *v1alpha1.Subscription) error {
 	logger := log.FromContext(ctx)
 
 	subscriptionName := subscription.Name
 	subscriptionNamespace := subscription.Namespace
 
-	subscriptionExists, _, err := CheckSubscriptionExists(ctx, olmClientSet, subscription)
+	subscriptionExists, existingSubscription, err := CheckSubscriptionExists(ctx, olmClientSet, subscription)
 	if err != nil {
 		logger.Error(err, "Error occurred when checking subscription exists", "SubscriptionName", subscriptionName)
 		return err
 	}
-	if subscriptionExists {
-		// get name of csv before deletion
-		csvName := subscription.Status.InstalledCSV
-
-		// deleting subscription resource
-		err = olmClientSet.OperatorsV1alpha1().Subscriptions(subscriptionNamespace).Delete(ctx, subscriptionName, metav1.DeleteOptions{})
-		if err != nil {
-			logger.Error(err, "Error occurred while deleting Subscription", "SubscriptionName", subscriptionName, "Namespace", subscriptionNamespace)
-			return err
-		}
-		logger.Info("Successfully deleted Subscription", "SubscriptionName", subscriptionName)
-
-		// cleanup csv
-		// TODO verify CSV deletion happens. refactor code to use csvName instead of namespace
-		csv, err := olmClientSet.OperatorsV1alpha1().ClusterServiceVersions(subscriptionNamespace).Get(ctx, csvName, metav1.GetOptions{})
-		if err != nil {
-			if apierrors.IsNotFound(err) {
-				// ensure csvName is not empty: Do a check for csvName
-				logger.Info("CSV resource not found", "CSV", csvName)
-				return nil
-			}
-			logger.Error(err, "Error occurred when getting CSV", "CSV", csvName)
-			return err
-		}
-		if err := olmClientSet.OperatorsV1alpha1().ClusterServiceVersions(csv.Namespace).Delete(ctx, csv.Name, metav1.DeleteOptions{}); err != nil && !apierrors.IsNotFound(err) {
-			return err
-		}
-		logger.Info("Successfully deleted CSV", "CSV", csvName)
+	if !subscriptionExists {
 		return nil
 	}
-	return err
+	// get name of csv before deletion
+	csvName := existingSubscription.Status.InstalledCSV
+
+	// deleting subscription resource
+	err = olmClientSet.OperatorsV1alpha1().Subscriptions(subscriptionNamespace).Delete(ctx, subscriptionName, metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		logger.Error(err, "Error occurred while deleting Subscription", "SubscriptionName", subscriptionName, "Namespace", subscriptionNamespace)
+		return err
+	}
+	logger.Info("Successfully deleted Subscription", "SubscriptionName", subscriptionName)
+
+	// cleanup csv
+	if csvName == "" {
+		logger.Info("Subscription has no installed CSV", "SubscriptionName", subscriptionName)
+		return nil
+	}
+	err = olmClientSet.OperatorsV1alpha1().ClusterServiceVersions(subscriptionNamespace).Delete(ctx, csvName, metav1.DeleteOptions{})
+	if err != nil && !apierrors.IsNotFound(err) {
+		logger.Error(err, "Error occurred when deleting CSV", "CSV", csvName)
+		return err
+	}
+	logger.Info("Successfully deleted CSV", "CSV", csvName)
+	return nil
 }
 
 func AddLabel() map[string]string {
@@ -310,20 +295,3 @@ func CheckLabelExist(labels map[string]string) bool {
 	}
 	return labelValue == CreatedByLabelValue
 }
-
-// updateNamespaceLabel adds a new label to namespace and updates the namespace object.
-func updateNamespaceLabel(namespace *corev1.Namespace, ctx context.Context, client client.Client) error {
-	nsLogger := log.FromContext(ctx)
-
-	namespaceName := namespace.Name
-	nsLogger.Info("Updating namespace with new label", "NS", namespaceName)
-
-	// add new label to namespace label map
-	namespace.Labels[CreatedByLabelKey] = CreatedByLabelValue
-	if err := client.Update(ctx, namespace); err != nil {
-		nsLogger.Info("Error occurred when updating namespace with new label", "NS", namespaceName)
-		return err
-	}
-	nsLogger.Info("Successfully updated namespace with new label", "NS", namespaceName)
-	return nil
-}
@@ -18,9 +18,11 @@ package kube
 
 import (
 	"context"
+	"errors"
 	operatorsv1 "github.com/operator-framework/api/pkg/operators/v1"
 	"github.com/operator-framework/api/pkg/operators/v1alpha1"
 	olmclientsetfake "github.com/operator-framework/operator-lifecycle-manager/pkg/api/client/clientset/versioned/fake"
+	orchestratorv1alpha2 "github.com/rhdhorchestrator/orchestrator-operator/api/v1alpha3"
 	"github.com/stretchr/testify/assert"
 	corev1 "k8s.io/api/core/v1"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
@@ -30,6 +32,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"testing"
 )
@@ -76,6 +79,12 @@ func TestCheckNamespaceExist(t *testing.T) {
 			namespaceObj:   &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: orchestratorNamespace, Labels: existingLabelMap}},
 			expectedExists: true,
 		},
+		{
+			name:           "Namespace created by the user",
+			namespace:      "user-namespace",
+			namespaceObj:   &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "user-namespace"}},
+			expectedExists: true,
+		},
 		{
 			name:           "Namespace does not exist",
 			namespace:      "fake-namespace",
@@ -89,6 +98,11 @@ func TestCheckNamespaceExist(t *testing.T) {
 			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(tc.namespaceObj).Build()
 			exists, _ := CheckNamespaceExist(ctx, fakeClient, tc.namespace)
 			assert.Equal(t, tc.expectedExists, exists)
+			if exists {
+				namespace := &corev1.Namespace{}
+				assert.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: tc.namespace}, namespace))
+				assert.Equal(t, tc.namespaceObj.Labels, namespace.Labels, "The labels of the namespace are unchanged")
+			}
 		})
 	}
 }
@@ -129,34 +143,31 @@ func TestCreateNamespace(t *testing.T) {
 
 func TestInstallSubscriptionAndOperatorGroup(t *testing.T) {
 	ctx := context.TODO()
-	scheme := runtime.NewScheme()
-	utilruntime.Must(v1alpha1.AddToScheme(scheme))
-	utilruntime.Must(operatorsv1.AddToScheme(scheme))
 
 	expectedError := apierrors.NewAlreadyExists(schema.GroupResource{}, "fake-subscription")
 
 	t.Run("Install subscription and operator group no error", func(t *testing.T) {
-		fakeClient := fake.NewClientBuilder().WithScheme(scheme).Build()
+		inventory, _ := newTestInventoryClient(olmclientsetfake.NewSimpleClientset())
 		fakeOLMClientSet := olmclientsetfake.NewSimpleClientset()
 		err := InstallSubscriptionAndOperatorGroup(
-			ctx, fakeClient,
+			ctx, inventory,
 			fakeOLMClientSet,
 			orchestratorOperatorGroup,
-			subscription)
+			subscription.DeepCopy())
 		assert.Equal(t, nil, err)
 	})
 
 	t.Run("Install subscription and operator group with error", func(t *testing.T) {
-		fakeClient := fake.NewClientBuilder().WithScheme(scheme).Build()
+		inventory, _ := newTestInventoryClient(olmclientsetfake.NewSimpleClientset())
 		fakeOLMClientSetWithSubscription := olmclientsetfake.NewSimpleClientset()
 		fakeOLMClientSetWithSubscription.OperatorsV1alpha1().
 			Subscriptions(orchestratorNamespace).
 			Create(ctx, subscription, metav1.CreateOptions{})
 		err := InstallSubscriptionAndOperatorGroup(
-			ctx, fakeClient,
+			ctx, inventory,
 			fakeOLMClientSetWithSubscription,
 			orchestratorOperatorGroup,
-			subscription)
+			subscription.DeepCopy())
 		assert.Error(t, err, "Expected error when subscription already exists")
 		assert.Equal(t, apierrors.IsAlreadyExists(expectedError), apierrors.IsAlreadyExists(err))
 	})
@@ -177,12 +188,15 @@ func TestApproveInstallPlan(t *testing.T) {
 			Approved: false,
 		},
 	}
+	orchestrator := &orchestratorv1alpha2.Orchestrator{ObjectMeta: metav1.ObjectMeta{Name: "orchestrator", Namespace: orchestratorNamespace}}
 
 	// Test with approve InstallPlan with no errors
 	t.Run("Approve install plan", func(t *testing.T) {
 		fakeClientWithInstallPlan := fake.NewClientBuilder().WithScheme(scheme).WithObjects(installPlan).Build()
-		err := ApproveInstallPlan(fakeClientWithInstallPlan, ctx, installPlan.Name, orchestratorNamespace)
+		recorder := record.NewFakeRecorder(1)
+		err := ApproveInstallPlan(fakeClientWithInstallPlan, ctx, recorder, orchestrator, installPlan.Name, orchestratorNamespace, nil)
 		assert.NoError(t, err, "Expected no error")
+		assert.Equal(t, "Normal InstallPlanApproved Approved InstallPlan orchestrator-namespace/install-plan", <-recorder.Events)
 
 		// Verify InstallPlan is approved
 		updatedInstallPlan := &v1alpha1.InstallPlan{}
@@ -190,10 +204,29 @@ func TestApproveInstallPlan(t *testing.T) {
 		assert.Equal(t, true, updatedInstallPlan.Spec.Approved)
 	})
 
+	// Test InstallPlan refused by the policy
+	t.Run("Refuse install plan", func(t *testing.T) {
+		fakeClientWithInstallPlan := fake.NewClientBuilder().WithScheme(scheme).WithObjects(installPlan).Build()
+		recorder := record.NewFakeRecorder(1)
+		err := ApproveInstallPlan(fakeClientWithInstallPlan, ctx, recorder, orchestrator, installPlan.Name, orchestratorNamespace,
+			func([]string) error {
+				return errors.New("unsupported version")
+			})
+		assert.ErrorContains(t, err, "is not approved: unsupported version")
+		assert.Equal(t, "Warning InstallPlanRefused Refused InstallPlan orchestrator-namespace/install-plan: unsupported version",
+			<-recorder.Events)
+
+		// Verify InstallPlan is not approved
+		updatedInstallPlan := &v1alpha1.InstallPlan{}
+		_ = fakeClientWithInstallPlan.Get(ctx, types.NamespacedName{Name: installPlan.Name, Namespace: installPlan.Namespace}, updatedInstallPlan)
+		assert.Equal(t, false, updatedInstallPlan.Spec.Approved)
+	})
+
 	// Test approve InstallPlan with error
 	t.Run("Approve install plan with error", func(t *testing.T) {
 		fakeClientWithoutInstallPlan := fake.NewClientBuilder().WithScheme(scheme).Build()
-		err := ApproveInstallPlan(fakeClientWithoutInstallPlan, ctx, installPlan.Name, orchestratorNamespace)
+		err := ApproveInstallPlan(fakeClientWithoutInstallPlan, ctx, record.NewFakeRecorder(1), orchestrator,
+			installPlan.Name, orchestratorNamespace, nil)
 		assert.Error(t, err, "Expected error")
 		assert.True(t, apierrors.IsNotFound(err))
 	})
@@ -240,10 +273,34 @@ func TestCreateSubscriptionObject(t *testing.T) {
 		orchestratorNamespace,
 		subscription.Spec.Channel,
 		subscription.Spec.StartingCSV,
+		orchestratorv1alpha2.SubscriptionConfig{},
 	)
 	assert.Equal(t, subscription, actualSubscription)
 }
 
+func TestCreateSubscriptionObjectWithOverrides(t *testing.T) {
+	subscriptionConfig := orchestratorv1alpha2.SubscriptionConfig{
+		Channel:             "fast",
+		StartingCSV:         "starting-csv.v2",
+		Source:              "mirrored-operators",
+		SourceNamespace:     "mirror-marketplace",
+		InstallPlanApproval: string(v1alpha1.ApprovalAutomatic),
+	}
+	actualSubscription := CreateSubscriptionObject(
+		subscriptionName,
+		orchestratorNamespace,
+		subscription.Spec.Channel,
+		subscription.Spec.StartingCSV,
+		subscriptionConfig,
+	)
+	assert.Equal(t, "fast", actualSubscription.Spec.Channel)
+	assert.Equal(t, "starting-csv.v2", actualSubscription.Spec.StartingCSV)
+	assert.Equal(t, "mirrored-operators", actualSubscription.Spec.CatalogSource)
+	assert.Equal(t, "mirror-marketplace", actualSubscription.Spec.CatalogSourceNamespace)
+	assert.Equal(t, v1alpha1.ApprovalAutomatic, actualSubscription.Spec.InstallPlanApproval)
+	assert.Equal(t, subscriptionName, actualSubscription.Spec.Package)
+}
+
 func TestCheckSubscriptionExists(t *testing.T) {
 	ctx := context.TODO()
 	scheme := runtime.NewScheme()
@@ -291,15 +348,42 @@ func TestCleanUpNamespace(t *testing.T) {
 	ctx := context.TODO()
 	scheme := runtime.NewScheme()
 	utilruntime.Must(corev1.AddToScheme(scheme))
+	utilruntime.Must(orchestratorv1alpha2.AddToScheme(scheme))
 
-	ns := &corev1.Namespace{
+	createdNamespace := &corev1.Namespace{
 		ObjectMeta: metav1.ObjectMeta{Name: orchestratorNamespace, Labels: AddLabel()},
 	}
-	t.Run("Clean up namespace with no error", func(t *testing.T) {
-		fakeClientWithoutNS := fake.NewClientBuilder().WithScheme(scheme).WithObjects(ns).Build()
-		err := CleanUpNamespace(ctx, orchestratorNamespace, fakeClientWithoutNS)
+	// labelled by a previous version of the operator, but created by the user
+	userNamespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{Name: "user-namespace", Labels: AddLabel()},
+	}
+	createdConfigMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "created", Namespace: userNamespace.Name}}
+	userConfigMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "user", Namespace: userNamespace.Name}}
+
+	orchestrator := &orchestratorv1alpha2.Orchestrator{ObjectMeta: metav1.ObjectMeta{Name: "orchestrator", Namespace: "default"}}
+	orchestrator.Status.Inventory = []orchestratorv1alpha2.InventoryEntry{
+		{APIVersion: "v1", Kind: "Namespace", Name: orchestratorNamespace},
+		{APIVersion: "v1", Kind: "ConfigMap", Namespace: userNamespace.Name, Name: createdConfigMap.Name},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(createdNamespace, userNamespace, createdConfigMap, userConfigMap).Build()
+	inventory := NewInventoryClient(fakeClient, fakeClient, olmclientsetfake.NewSimpleClientset(), nil, orchestrator)
+
+	t.Run("Clean up namespace created by the operator", func(t *testing.T) {
+		err := CleanUpNamespace(ctx, orchestratorNamespace, inventory)
+		assert.NoError(t, err, "Expected no error")
+		err = fakeClient.Get(ctx, types.NamespacedName{Name: orchestratorNamespace}, &corev1.Namespace{})
+		assert.True(t, apierrors.IsNotFound(err))
+	})
+	t.Run("Clean up namespace created by the user", func(t *testing.T) {
+		err := CleanUpNamespace(ctx, userNamespace.Name, inventory)
 		assert.NoError(t, err, "Expected no error")
+		assert.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: userNamespace.Name}, &corev1.Namespace{}))
+		assert.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: userConfigMap.Name, Namespace: userNamespace.Name}, &corev1.ConfigMap{}))
+		err = fakeClient.Get(ctx, types.NamespacedName{Name: createdConfigMap.Name, Namespace: userNamespace.Name}, &corev1.ConfigMap{})
+		assert.True(t, apierrors.IsNotFound(err))
 	})
+	assert.Empty(t, orchestrator.Status.Inventory)
 }
 
 func TestAddLabel(t *testing.T) {
@@ -342,28 +426,3 @@ func TestCheckLabelExists(t *testing.T) {
 		})
 	}
 }
-
-func TestUpdateNamespaceLabel(t *testing.T) {
-	ctx := context.TODO()
-	scheme := runtime.NewScheme()
-	utilruntime.Must(corev1.AddToScheme(scheme))
-
-	ns := &corev1.Namespace{
-		ObjectMeta: metav1.ObjectMeta{Name: orchestratorNamespace, Labels: map[string]string{
-			"app.kubernetes.io/created-by": "kustomize",
-		}},
-	}
-
-	t.Run("Update namespace label with no error", func(t *testing.T) {
-		fakeClientWithNS := fake.NewClientBuilder().WithScheme(scheme).WithObjects(ns).Build()
-		err := updateNamespaceLabel(ns, ctx, fakeClientWithNS)
-		_, exists := ns.Labels[CreatedByLabelKey]
-		assert.NoError(t, err, "Expected no error")
-		assert.True(t, exists, "Expected label created")
-	})
-	t.Run("Update namespace label with no error", func(t *testing.T) {
-		fakeClientWithoutNS := fake.NewClientBuilder().WithScheme(scheme).WithObjects(&corev1.Namespace{}).Build()
-		err := updateNamespaceLabel(ns, ctx, fakeClientWithoutNS)
-		assert.Error(t, err, "Expected error")
-	})
-}
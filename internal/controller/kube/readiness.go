@@ -0,0 +1,115 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	olmclientset "github.com/operator-framework/operator-lifecycle-manager/pkg/api/client/clientset/versioned"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// NotReadyError reports a resource which exists but is not ready yet; the reconciliation is retried later.
+type NotReadyError struct {
+	Kind string
+	Name string
+	// Reason details the state of the resource, when known
+	Reason string
+}
+
+func (e *NotReadyError) Error() string {
+	if e.Reason != "" {
+		return fmt.Sprintf("%s %s is not ready: %s", e.Kind, e.Name, e.Reason)
+	}
+	return fmt.Sprintf("%s %s is not ready", e.Kind, e.Name)
+}
+
+func IsNotReady(err error) bool {
+	var notReady *NotReadyError
+	return errors.As(err, &notReady)
+}
+
+// CheckCSVSucceeded returns a NotReadyError until the ClusterServiceVersion installed by the subscription has
+// succeeded. The subscription is read again, as its status is updated by OLM during the installation.
+func CheckCSVSucceeded(ctx context.Context, olmClientSet olmclientset.Interface, subscription *operatorsv1alpha1.Subscription) error {
+	logger := log.FromContext(ctx)
+
+	installedSubscription, err := olmClientSet.OperatorsV1alpha1().Subscriptions(subscription.Namespace).Get(ctx, subscription.Name, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return &NotReadyError{Kind: subscriptionKind, Name: FormatObjectName(subscription.Namespace, subscription.Name)}
+		}
+		logger.Error(err, "Error occurred when retrieving subscription", "SubscriptionName", subscription.Name)
+		return err
+	}
+	installedCSV := installedSubscription.Status.InstalledCSV
+	if installedCSV == "" {
+		logger.Info("Subscription has no installed CSV yet", "SubscriptionName", subscription.Name)
+		return &NotReadyError{Kind: subscriptionKind, Name: FormatObjectName(subscription.Namespace, subscription.Name),
+			Reason: "no ClusterServiceVersion is installed yet"}
+	}
+
+	csv, err := olmClientSet.OperatorsV1alpha1().ClusterServiceVersions(subscription.Namespace).Get(ctx, installedCSV, metav1.GetOptions{})
+	if err != nil {
+		if apierrors.IsNotFound(err) {
+			return &NotReadyError{Kind: "ClusterServiceVersion", Name: FormatObjectName(subscription.Namespace, installedCSV)}
+		}
+		logger.Error(err, "Error occurred when retrieving CSV", "ClusterServiceVersion", installedCSV)
+		return err
+	}
+	if err := checkCSVPhase(csv); err != nil {
+		logger.Info("CSV has not succeeded yet", "ClusterServiceVersion", installedCSV, "Phase", csv.Status.Phase)
+		return err
+	}
+	return nil
+}
+
+// checkCSVPhase returns a NotReadyError until the ClusterServiceVersion has succeeded.
+func checkCSVPhase(csv *operatorsv1alpha1.ClusterServiceVersion) error {
+	if csv.Status.Phase == operatorsv1alpha1.CSVPhaseSucceeded {
+		return nil
+	}
+	reason := fmt.Sprintf("phase is %q", csv.Status.Phase)
+	if csv.Status.Phase == "" {
+		reason = "phase is not reported yet"
+	}
+	return &NotReadyError{Kind: "ClusterServiceVersion", Name: FormatObjectName(csv.Namespace, csv.Name), Reason: reason}
+}
+
+// CheckDeploymentAvailable returns a NotReadyError until the deployment reports the Available condition.
+func CheckDeploymentAvailable(ctx context.Context, k8client client.Client, namespace, name string) error {
+	deployment := &appsv1.Deployment{}
+	if err := k8client.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, deployment); err != nil {
+		return err
+	}
+	for _, condition := range deployment.Status.Conditions {
+		if condition.Type == appsv1.DeploymentAvailable && condition.Status == corev1.ConditionTrue {
+			return nil
+		}
+	}
+	return &NotReadyError{Kind: "Deployment", Name: FormatObjectName(namespace, name),
+		Reason: fmt.Sprintf("%d/%d replicas are available", deployment.Status.AvailableReplicas, deployment.Status.Replicas)}
+}
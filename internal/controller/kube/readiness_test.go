@@ -0,0 +1,129 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kube
+
+import (
+	"context"
+	"testing"
+
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	olmclientsetfake "github.com/operator-framework/operator-lifecycle-manager/pkg/api/client/clientset/versioned/fake"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestCheckCSVSucceeded(t *testing.T) {
+	ctx := context.TODO()
+	csvName := "orchestrator-operator.v1.0.0"
+
+	newSubscription := func(installedCSV string) *v1alpha1.Subscription {
+		installedSubscription := subscription.DeepCopy()
+		installedSubscription.Status.InstalledCSV = installedCSV
+		return installedSubscription
+	}
+	newCSV := func(phase v1alpha1.ClusterServiceVersionPhase) *v1alpha1.ClusterServiceVersion {
+		return &v1alpha1.ClusterServiceVersion{
+			ObjectMeta: metav1.ObjectMeta{Name: csvName, Namespace: orchestratorNamespace},
+			Status:     v1alpha1.ClusterServiceVersionStatus{Phase: phase},
+		}
+	}
+
+	testCases := []struct {
+		name             string
+		objects          []runtime.Object
+		expectedNotReady bool
+		expectedMessage  string
+	}{
+		{
+			name:             "Subscription not found",
+			expectedNotReady: true,
+			expectedMessage:  "Subscription orchestrator-namespace/orchestrator-subscription is not ready",
+		},
+		{
+			name:             "No installed CSV",
+			objects:          []runtime.Object{newSubscription("")},
+			expectedNotReady: true,
+			expectedMessage:  "no ClusterServiceVersion is installed yet",
+		},
+		{
+			name:             "CSV not found",
+			objects:          []runtime.Object{newSubscription(csvName)},
+			expectedNotReady: true,
+			expectedMessage:  "ClusterServiceVersion orchestrator-namespace/orchestrator-operator.v1.0.0 is not ready",
+		},
+		{
+			name:             "CSV installing",
+			objects:          []runtime.Object{newSubscription(csvName), newCSV(v1alpha1.CSVPhaseInstalling)},
+			expectedNotReady: true,
+			expectedMessage:  `phase is "Installing"`,
+		},
+		{
+			name:    "CSV succeeded",
+			objects: []runtime.Object{newSubscription(csvName), newCSV(v1alpha1.CSVPhaseSucceeded)},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			olmClientSet := olmclientsetfake.NewSimpleClientset(tc.objects...)
+			err := CheckCSVSucceeded(ctx, olmClientSet, subscription)
+			if !tc.expectedNotReady {
+				assert.NoError(t, err)
+				return
+			}
+			assert.True(t, IsNotReady(err))
+			assert.Contains(t, err.Error(), tc.expectedMessage)
+		})
+	}
+}
+
+func TestCheckDeploymentAvailable(t *testing.T) {
+	ctx := context.TODO()
+	scheme := runtime.NewScheme()
+	utilruntime.Must(appsv1.AddToScheme(scheme))
+
+	newDeployment := func(available corev1.ConditionStatus) *appsv1.Deployment {
+		return &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{Name: "deployment", Namespace: orchestratorNamespace},
+			Status: appsv1.DeploymentStatus{
+				Replicas: 1,
+				Conditions: []appsv1.DeploymentCondition{
+					{Type: appsv1.DeploymentProgressing, Status: corev1.ConditionTrue},
+					{Type: appsv1.DeploymentAvailable, Status: available},
+				},
+			},
+		}
+	}
+
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).Build()
+	err := CheckDeploymentAvailable(ctx, fakeClient, orchestratorNamespace, "deployment")
+	assert.True(t, apierrors.IsNotFound(err))
+
+	fakeClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(newDeployment(corev1.ConditionFalse)).Build()
+	err = CheckDeploymentAvailable(ctx, fakeClient, orchestratorNamespace, "deployment")
+	assert.True(t, IsNotReady(err))
+	assert.EqualError(t, err, "Deployment orchestrator-namespace/deployment is not ready: 0/1 replicas are available")
+
+	fakeClient = fake.NewClientBuilder().WithScheme(scheme).WithObjects(newDeployment(corev1.ConditionTrue)).Build()
+	assert.NoError(t, CheckDeploymentAvailable(ctx, fakeClient, orchestratorNamespace, "deployment"))
+}
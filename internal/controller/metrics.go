@@ -0,0 +1,118 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	"github.com/prometheus/client_golang/prometheus"
+	orchestratorv1alpha2 "github.com/rhdhorchestrator/orchestrator-operator/api/v1alpha3"
+	"github.com/rhdhorchestrator/orchestrator-operator/internal/controller/kube"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	componentReconcileDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name: "orchestrator_component_reconcile_duration_seconds",
+		Help: "Duration of the reconciliation of each Orchestrator component.",
+	}, []string{"component"})
+	componentReconcileErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "orchestrator_component_reconcile_errors_total",
+		Help: "Number of failed reconciliations of each Orchestrator component.",
+	}, []string{"component"})
+	componentReady = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "orchestrator_component_ready",
+		Help: "Whether each enabled component of an Orchestrator is ready (1) or not (0).",
+	}, []string{"namespace", "name", "component"})
+	operatorCSVInfo = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "orchestrator_operator_csv_info",
+		Help: "The ClusterServiceVersion installed by each operator Subscription, with its version.",
+	}, []string{"namespace", "subscription", "csv", "version"})
+	operatorInstallDuration = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "orchestrator_operator_install_duration_seconds",
+		Help: "Time from the creation of the ClusterServiceVersion of each operator Subscription to its first Succeeded phase.",
+	}, []string{"namespace", "subscription", "csv"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(
+		componentReconcileDuration, componentReconcileErrors, componentReady, operatorCSVInfo, operatorInstallDuration)
+}
+
+// setComponentReadyMetric reports the readiness of the component; disabled components are not reported.
+func setComponentReadyMetric(orchestrator *orchestratorv1alpha2.Orchestrator, component orchestratorComponent, ready bool) {
+	labels := prometheus.Labels{"namespace": orchestrator.Namespace, "name": orchestrator.Name, "component": component.name}
+	if !component.enabled(orchestrator.Spec) {
+		componentReady.Delete(labels)
+		return
+	}
+	value := 0.0
+	if ready {
+		value = 1
+	}
+	componentReady.With(labels).Set(value)
+}
+
+// deleteOrchestratorMetrics removes the series of a deleted Orchestrator.
+func deleteOrchestratorMetrics(orchestrator *orchestratorv1alpha2.Orchestrator) {
+	componentReady.DeletePartialMatch(prometheus.Labels{"namespace": orchestrator.Namespace, "name": orchestrator.Name})
+}
+
+// recordOperatorMetrics reports the ClusterServiceVersions installed by the Subscriptions of the inventory,
+// and the time they took to succeed.
+func recordOperatorMetrics(
+	ctx context.Context, installedCSVs *kube.InstalledCSVs, orchestrator *orchestratorv1alpha2.Orchestrator) error {
+	for _, entry := range orchestrator.Status.Inventory {
+		if entry.Kind != "Subscription" || !isOperatorEntry(entry) {
+			continue
+		}
+		csv, err := installedCSVs.GetInstalledCSV(ctx, entry.Namespace, entry.Name)
+		if err != nil {
+			return err
+		}
+		if csv == nil {
+			continue
+		}
+
+		// the series of the previous ClusterServiceVersion is replaced on upgrades
+		subscriptionLabels := prometheus.Labels{"namespace": entry.Namespace, "subscription": entry.Name}
+		operatorCSVInfo.DeletePartialMatch(subscriptionLabels)
+		operatorCSVInfo.WithLabelValues(entry.Namespace, entry.Name, csv.Name, csv.Spec.Version.String()).Set(1)
+		if succeeded := getFirstSucceededTime(csv); succeeded != nil {
+			operatorInstallDuration.DeletePartialMatch(subscriptionLabels)
+			operatorInstallDuration.WithLabelValues(entry.Namespace, entry.Name, csv.Name).
+				Set(succeeded.Sub(csv.CreationTimestamp.Time).Seconds())
+		}
+	}
+	return nil
+}
+
+// getFirstSucceededTime returns when the ClusterServiceVersion first reached the Succeeded phase, or nil when it is
+// not succeeded. Later transitions, such as a reinstall after a failure, do not move it.
+func getFirstSucceededTime(csv *operatorsv1alpha1.ClusterServiceVersion) *metav1.Time {
+	if csv.Status.Phase != operatorsv1alpha1.CSVPhaseSucceeded {
+		return nil
+	}
+	for _, condition := range csv.Status.Conditions {
+		if condition.Phase == operatorsv1alpha1.CSVPhaseSucceeded && condition.LastTransitionTime != nil {
+			return condition.LastTransitionTime
+		}
+	}
+	return csv.Status.LastTransitionTime
+}
@@ -0,0 +1,81 @@
+package controller
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/blang/semver/v4"
+	"github.com/operator-framework/api/pkg/lib/version"
+	operatorsv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
+	olmclientsetfake "github.com/operator-framework/operator-lifecycle-manager/pkg/api/client/clientset/versioned/fake"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	orchestratorv1alpha2 "github.com/rhdhorchestrator/orchestrator-operator/api/v1alpha3"
+	"github.com/rhdhorchestrator/orchestrator-operator/internal/controller/kube"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestSetComponentReadyMetric(t *testing.T) {
+	reconciler := &OrchestratorReconciler{}
+	orchestrator := newTestOrchestrator()
+	orchestrator.Name = "metrics-orchestrator"
+
+	for _, component := range reconciler.components() {
+		setComponentReadyMetric(orchestrator, component, component.conditionType != TypeRHDHReady)
+	}
+	assert.Equal(t, 1.0, testutil.ToFloat64(componentReady.WithLabelValues(testNamespace, orchestrator.Name, "Serverless Logic")))
+	assert.Equal(t, 0.0, testutil.ToFloat64(componentReady.WithLabelValues(testNamespace, orchestrator.Name, "RHDH")))
+
+	// the K-Native component is disabled
+	for _, component := range reconciler.components() {
+		if component.conditionType == TypeKnativeReady {
+			setComponentReadyMetric(orchestrator, component, true)
+		}
+	}
+	assert.False(t, componentReady.Delete(map[string]string{
+		"namespace": testNamespace, "name": orchestrator.Name, "component": "K-Native"}), "Disabled components are not reported")
+
+	deleteOrchestratorMetrics(orchestrator)
+	assert.False(t, componentReady.Delete(map[string]string{
+		"namespace": testNamespace, "name": orchestrator.Name, "component": "RHDH"}))
+}
+
+func TestRecordOperatorMetrics(t *testing.T) {
+	ctx := context.TODO()
+	created := time.Now().Add(-time.Hour)
+	subscription := &operatorsv1alpha1.Subscription{
+		ObjectMeta: metav1.ObjectMeta{Name: "metrics-operator", Namespace: "metrics-namespace", CreationTimestamp: metav1.NewTime(created)},
+		Status:     operatorsv1alpha1.SubscriptionStatus{InstalledCSV: "metrics-operator.v1.2.0"},
+	}
+	// the ClusterServiceVersion of an upgrade is created long after the Subscription, and succeeded again later
+	csvCreated := created.Add(30 * time.Minute)
+	succeeded := metav1.NewTime(csvCreated.Add(90 * time.Second))
+	reinstalled := metav1.NewTime(csvCreated.Add(20 * time.Minute))
+	csv := &operatorsv1alpha1.ClusterServiceVersion{
+		ObjectMeta: metav1.ObjectMeta{Name: "metrics-operator.v1.2.0", Namespace: "metrics-namespace", CreationTimestamp: metav1.NewTime(csvCreated)},
+		Spec:       operatorsv1alpha1.ClusterServiceVersionSpec{Version: version.OperatorVersion{Version: semver.MustParse("1.2.0")}},
+		Status: operatorsv1alpha1.ClusterServiceVersionStatus{
+			Phase:              operatorsv1alpha1.CSVPhaseSucceeded,
+			LastTransitionTime: &reinstalled,
+			Conditions: []operatorsv1alpha1.ClusterServiceVersionCondition{
+				{Phase: operatorsv1alpha1.CSVPhaseInstalling, LastTransitionTime: &metav1.Time{Time: csvCreated}},
+				{Phase: operatorsv1alpha1.CSVPhaseSucceeded, LastTransitionTime: &succeeded},
+				{Phase: operatorsv1alpha1.CSVPhaseFailed, LastTransitionTime: &succeeded},
+				{Phase: operatorsv1alpha1.CSVPhaseSucceeded, LastTransitionTime: &reinstalled},
+			},
+		},
+	}
+	orchestrator := newTestOrchestrator()
+	orchestrator.Status.Inventory = []orchestratorv1alpha2.InventoryEntry{
+		{APIVersion: "operators.coreos.com/v1alpha1", Kind: "Subscription", Namespace: "metrics-namespace", Name: "metrics-operator"},
+		{APIVersion: "operators.coreos.com/v1alpha1", Kind: "Subscription", Namespace: "metrics-namespace", Name: "deleted"},
+	}
+	installedCSVs := kube.NewInstalledCSVs(olmclientsetfake.NewSimpleClientset(subscription, csv))
+
+	assert.NoError(t, recordOperatorMetrics(ctx, installedCSVs, orchestrator))
+	assert.Equal(t, 1.0, testutil.ToFloat64(
+		operatorCSVInfo.WithLabelValues("metrics-namespace", "metrics-operator", "metrics-operator.v1.2.0", "1.2.0")))
+	assert.InDelta(t, 90.0, testutil.ToFloat64(
+		operatorInstallDuration.WithLabelValues("metrics-namespace", "metrics-operator", "metrics-operator.v1.2.0")), 1)
+}
@@ -4,12 +4,16 @@ import (
 	"context"
 	"reflect"
 
+	orchestratorv1alpha2 "github.com/rhdhorchestrator/orchestrator-operator/api/v1alpha3"
 	kubeoperations "github.com/rhdhorchestrator/orchestrator-operator/internal/controller/kube"
 
+	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 	apierrros "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 )
@@ -20,6 +24,7 @@ const (
 	allowRHDHToSonataflowWorkflows       = "allow-rhdh-to-sonataflow-and-workflows"
 	allowIntraNamespace                  = "allow-intra-namespace"
 	allowMonitoringToSonataflowWorkflows = "allow-monitoring-to-sonataflow-and-workflows"
+	allowOperatorToDatabase              = "allow-operator-to-database"
 )
 
 var (
@@ -28,14 +33,16 @@ var (
 		allowIntraNamespace,
 		allowMonitoringToSonataflowWorkflows,
 	}
-	allErrors = make(map[string]error)
 )
 
 // handleNetworkPolicy performs the retrieval, creation and reconciling of network policy.
+// The pods of additionalNamespaces are allowed to reach the workflow namespace as well.
 // It returns an error if any occurs during retrieval, creation or reconciliation.
 func handleNetworkPolicy(client client.Client, ctx context.Context,
-	networkAndServerlessWorkflowNamespace, rhdhNamespace, databaseNamespace string, monitoringFlag bool) map[string]error {
-	npLogger := log.FromContext(ctx)
+	recorder record.EventRecorder, orchestrator *orchestratorv1alpha2.Orchestrator,
+	networkAndServerlessWorkflowNamespace, rhdhNamespace, databaseNamespace string, additionalNamespaces []string,
+	monitoringFlag bool) map[string]error {
+	allErrors := make(map[string]error)
 
 	for _, NetworkPolicyName := range NetworkPoliciesList {
 
@@ -57,47 +64,80 @@ func handleNetworkPolicy(client client.Client, ctx context.Context,
 					// This policy concerns traffic coming into the pods
 					networkingv1.PolicyTypeIngress,
 				},
-				Ingress: createIngress(NetworkPolicyName, networkAndServerlessWorkflowNamespace, rhdhNamespace, databaseNamespace),
+				Ingress: createIngress(NetworkPolicyName, networkAndServerlessWorkflowNamespace, rhdhNamespace, databaseNamespace, additionalNamespaces),
 			},
 		}
 
-		existingNP := &networkingv1.NetworkPolicy{}
-		// get existing the networkPolicy
-		err := client.Get(ctx, types.NamespacedName{Name: desiredNP.Name, Namespace: desiredNP.Namespace}, existingNP)
-		if err != nil {
-			if apierrros.IsNotFound(err) {
-				// create network policy
-				if err := client.Create(ctx, desiredNP); err != nil {
-					npLogger.Error(err, "Error occurred when creating NetworkPolicy", "NP", NetworkPolicyName)
-					allErrors[NetworkPolicyName] = err
-				}
-			} else {
-				// Pass along only actual errors
-				allErrors[NetworkPolicyName] = err
-			}
-
-			continue
+		if err := applyNetworkPolicy(client, ctx, recorder, orchestrator, desiredNP); err != nil {
+			allErrors[NetworkPolicyName] = err
 		}
+	}
 
-		// Compare the current and desired state
-		if !reflect.DeepEqual(desiredNP.Spec, existingNP.Spec) {
-			existingNP.Spec = desiredNP.Spec
-			if err := client.Update(ctx, existingNP); err != nil {
-				npLogger.Error(err, "Error occurred when updating NetworkPolicy", "NP", NetworkPolicyName)
-				allErrors[NetworkPolicyName] = err
+	return allErrors
+}
+
+// handleDatabaseNetworkPolicy allows the database preflight of the operator to reach the database pods, selected
+// by databaseSelector, in the workflow namespace hosting them. Only the PostgreSQL port is opened.
+func handleDatabaseNetworkPolicy(client client.Client, ctx context.Context,
+	recorder record.EventRecorder, orchestrator *orchestratorv1alpha2.Orchestrator,
+	databaseNamespace, operatorNamespace string, databaseSelector map[string]string) error {
+	desiredNP := &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      allowOperatorToDatabase,
+			Namespace: databaseNamespace,
+			Labels:    kubeoperations.AddLabel(),
+		},
+		Spec: networkingv1.NetworkPolicySpec{
+			PodSelector: metav1.LabelSelector{MatchLabels: databaseSelector},
+			PolicyTypes: []networkingv1.PolicyType{
+				networkingv1.PolicyTypeIngress,
+			},
+			Ingress: createIngressOperatorDatabase(operatorNamespace),
+		},
+	}
+	return applyNetworkPolicy(client, ctx, recorder, orchestrator, desiredNP)
+}
+
+// applyNetworkPolicy creates desiredNP, or updates the spec of the existing NetworkPolicy when it differs.
+func applyNetworkPolicy(client client.Client, ctx context.Context,
+	recorder record.EventRecorder, orchestrator *orchestratorv1alpha2.Orchestrator, desiredNP *networkingv1.NetworkPolicy) error {
+	npLogger := log.FromContext(ctx)
+
+	existingNP := &networkingv1.NetworkPolicy{}
+	// get existing the networkPolicy
+	err := client.Get(ctx, types.NamespacedName{Name: desiredNP.Name, Namespace: desiredNP.Namespace}, existingNP)
+	if err != nil {
+		if apierrros.IsNotFound(err) {
+			// create network policy
+			if err := client.Create(ctx, desiredNP); err != nil {
+				npLogger.Error(err, "Error occurred when creating NetworkPolicy", "NP", desiredNP.Name)
+				return err
 			}
+			return nil
 		}
+		// Pass along only actual errors
+		return err
 	}
 
-	return allErrors
+	// Compare the current and desired state
+	if !reflect.DeepEqual(desiredNP.Spec, existingNP.Spec) {
+		existingNP.Spec = desiredNP.Spec
+		if err := client.Update(ctx, existingNP); err != nil {
+			npLogger.Error(err, "Error occurred when updating NetworkPolicy", "NP", desiredNP.Name)
+			return err
+		}
+		recorder.Eventf(orchestrator, corev1.EventTypeNormal, "NetworkPolicyUpdated",
+			"Updated NetworkPolicy %s/%s to match the Orchestrator spec", existingNP.Namespace, existingNP.Name)
+	}
+	return nil
 }
 
 // A switch to create an Ingress for each network policy.
-func createIngress(networkPolicyName string, networkAndServerlessWorkflowNamespace, rhdhNamespace, databaseNamespace string) []networkingv1.NetworkPolicyIngressRule {
+func createIngress(networkPolicyName string, networkAndServerlessWorkflowNamespace, rhdhNamespace, databaseNamespace string, additionalNamespaces []string) []networkingv1.NetworkPolicyIngressRule {
 
 	switch networkPolicyName {
 	case allowRHDHToSonataflowWorkflows:
-		return createIngressRHDHSonataflowWorkflows(networkAndServerlessWorkflowNamespace, rhdhNamespace, databaseNamespace)
+		return createIngressRHDHSonataflowWorkflows(networkAndServerlessWorkflowNamespace, rhdhNamespace, databaseNamespace, additionalNamespaces)
 	case allowIntraNamespace:
 		return createIngressIntraNamespaces()
 	case allowMonitoringToSonataflowWorkflows:
@@ -106,7 +146,7 @@ func createIngress(networkPolicyName string, networkAndServerlessWorkflowNamespa
 		return []networkingv1.NetworkPolicyIngressRule{}
 	}
 }
-func createIngressRHDHSonataflowWorkflows(networkAndServerlessWorkflowNamespace, rhdhNamespace, databaseNamespace string) []networkingv1.NetworkPolicyIngressRule {
+func createIngressRHDHSonataflowWorkflows(networkAndServerlessWorkflowNamespace, rhdhNamespace, databaseNamespace string, additionalNamespaces []string) []networkingv1.NetworkPolicyIngressRule {
 	Ingress := []networkingv1.NetworkPolicyIngressRule{
 		{
 			From: []networkingv1.NetworkPolicyPeer{
@@ -153,6 +193,39 @@ func createIngressRHDHSonataflowWorkflows(networkAndServerlessWorkflowNamespace,
 			},
 		},
 	}
+	for _, additionalNamespace := range additionalNamespaces {
+		// Allows traffic from pods in the other workflow namespaces sharing the platform services
+		Ingress[0].From = append(Ingress[0].From, networkingv1.NetworkPolicyPeer{
+			NamespaceSelector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{
+					metaDataNameLabel: additionalNamespace,
+				},
+			},
+		})
+	}
+	return Ingress
+}
+
+func createIngressOperatorDatabase(operatorNamespace string) []networkingv1.NetworkPolicyIngressRule {
+	port := intstr.FromInt32(postgresPort)
+	protocol := corev1.ProtocolTCP
+	Ingress := []networkingv1.NetworkPolicyIngressRule{
+		{
+			From: []networkingv1.NetworkPolicyPeer{
+				{
+					// Allows traffic from pods in the operator namespace
+					NamespaceSelector: &metav1.LabelSelector{
+						MatchLabels: map[string]string{
+							metaDataNameLabel: operatorNamespace,
+						},
+					},
+				},
+			},
+			Ports: []networkingv1.NetworkPolicyPort{
+				{Protocol: &protocol, Port: &port},
+			},
+		},
+	}
 	return Ingress
 }
 
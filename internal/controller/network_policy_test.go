@@ -6,11 +6,14 @@ import (
 
 	kubeoperations "github.com/rhdhorchestrator/orchestrator-operator/internal/controller/kube"
 	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
 	networkingv1 "k8s.io/api/networking/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 )
@@ -19,6 +22,7 @@ const (
 	testNamespace         = "test-namespace"
 	testRHDHNamespace     = "test-rhdh-namespace"
 	testDatabaseNamespace = "test-db-namespace"
+	testOperatorNamespace = "test-operator-namespace"
 )
 
 var objects []client.Object
@@ -90,7 +94,8 @@ func TestHandleNetworkPolicy(t *testing.T) {
 				fakeClient := fake.NewClientBuilder().WithScheme(scheme).Build()
 
 				// Call handler to Create the Network Policies
-				errors := handleNetworkPolicy(fakeClient, ctx, testNamespace, testRHDHNamespace, testDatabaseNamespace, tc.monitoringFlag)
+				errors := handleNetworkPolicy(fakeClient, ctx, record.NewFakeRecorder(10), newTestOrchestrator(),
+					testNamespace, testRHDHNamespace, testDatabaseNamespace, []string{testOperatorNamespace}, tc.monitoringFlag)
 
 				// Verify that the fake client is populated with policies after calling the handler
 				err := fakeClient.Get(ctx, types.NamespacedName{Name: allowRHDHToSonataflowWorkflows, Namespace: testNamespace}, existingNP)
@@ -120,8 +125,11 @@ func TestHandleNetworkPolicy(t *testing.T) {
 				assert.NoError(t, err)
 
 				// Call handler to update the Ingress
-				errors := handleNetworkPolicy(fakeClient, ctx, testNamespace, testRHDHNamespace, testDatabaseNamespace, tc.monitoringFlag)
+				recorder := record.NewFakeRecorder(10)
+				errors := handleNetworkPolicy(fakeClient, ctx, recorder, newTestOrchestrator(),
+					testNamespace, testRHDHNamespace, testDatabaseNamespace, []string{testOperatorNamespace}, tc.monitoringFlag)
 				assert.Equal(t, tc.errorMap, errors)
+				assert.Contains(t, <-recorder.Events, "NetworkPolicyUpdated")
 				err = fakeClient.Get(ctx, types.NamespacedName{Name: allowRHDHToSonataflowWorkflows, Namespace: testNamespace}, existingNP)
 				assert.NoError(t, err)
 				assert.NotEqual(t, tc.existingPolicies[len(tc.existingPolicies)-1].Spec.Ingress, existingNP)
@@ -143,7 +151,7 @@ func TestCreateIngressSwitch(t *testing.T) {
 		{
 			name:            "Create RHDH Ingress",
 			npName:          allowRHDHToSonataflowWorkflows,
-			expectedIngress: createIngressRHDHSonataflowWorkflows(testNamespace, testRHDHNamespace, testDatabaseNamespace),
+			expectedIngress: createIngressRHDHSonataflowWorkflows(testNamespace, testRHDHNamespace, testDatabaseNamespace, []string{testOperatorNamespace}),
 		},
 		{
 			name:            "Create Intra Ingress",
@@ -164,9 +172,32 @@ func TestCreateIngressSwitch(t *testing.T) {
 
 	for _, tc := range testCases {
 		t.Run(tc.name, func(t *testing.T) {
-			ingress := createIngress(tc.npName, testNamespace, testRHDHNamespace, testDatabaseNamespace)
+			ingress := createIngress(tc.npName, testNamespace, testRHDHNamespace, testDatabaseNamespace, []string{testOperatorNamespace})
 			assert.Equal(t, tc.expectedIngress, ingress)
 		})
 	}
 
 }
+
+func TestHandleDatabaseNetworkPolicy(t *testing.T) {
+	ctx := context.TODO()
+	scheme := runtime.NewScheme()
+	utilruntime.Must(networkingv1.AddToScheme(scheme))
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).Build()
+
+	databaseSelector := map[string]string{"app": "sonataflow-psql-postgresql"}
+	err := handleDatabaseNetworkPolicy(fakeClient, ctx, record.NewFakeRecorder(1), newTestOrchestrator(),
+		testDatabaseNamespace, testOperatorNamespace, databaseSelector)
+	assert.NoError(t, err)
+
+	networkPolicy := &networkingv1.NetworkPolicy{}
+	err = fakeClient.Get(ctx, types.NamespacedName{Name: allowOperatorToDatabase, Namespace: testDatabaseNamespace}, networkPolicy)
+	assert.NoError(t, err)
+	assert.Equal(t, databaseSelector, networkPolicy.Spec.PodSelector.MatchLabels)
+	assert.Len(t, networkPolicy.Spec.Ingress, 1)
+	assert.Equal(t, map[string]string{metaDataNameLabel: testOperatorNamespace},
+		networkPolicy.Spec.Ingress[0].From[0].NamespaceSelector.MatchLabels)
+	port := intstr.FromInt32(5432)
+	protocol := corev1.ProtocolTCP
+	assert.Equal(t, []networkingv1.NetworkPolicyPort{{Protocol: &protocol, Port: &port}}, networkPolicy.Spec.Ingress[0].Ports)
+}
@@ -0,0 +1,142 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	orchestratorv1alpha2 "github.com/rhdhorchestrator/orchestrator-operator/api/v1alpha3"
+	"github.com/rhdhorchestrator/orchestrator-operator/internal/controller/kube"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	// TypeOperatorSources reports which operators are installed by the Orchestrator and which are external.
+	TypeOperatorSources     string = "OperatorSources"
+	ReasonManagedOperators         = "ManagedOperators"
+	ReasonExternalOperators        = "ExternalOperators"
+)
+
+// adoptOperator detects the operator installed on the cluster when the install mode is Detect. It returns true when
+// an external operator is adopted, in which case no Subscription is created for it.
+func (r *OrchestratorReconciler) adoptOperator(
+	ctx context.Context, k8client *kube.InventoryClient, orchestrator *orchestratorv1alpha2.Orchestrator,
+	mode orchestratorv1alpha2.OperatorInstallMode, detection kube.OperatorDetection) (bool, error) {
+	managed := orchestratorv1alpha2.OperatorStatus{Name: detection.Name, Source: orchestratorv1alpha2.OperatorSourceManaged}
+	if mode != orchestratorv1alpha2.OperatorInstallModeDetect {
+		setOperatorStatus(orchestrator, managed)
+		return false, nil
+	}
+
+	detected, err := kube.DetectOperator(ctx, k8client, k8client.InstalledCSVs(), detection)
+	if err != nil {
+		return false, err
+	}
+	if detected == nil {
+		setOperatorStatus(orchestrator, managed)
+		return false, nil
+	}
+	if compatibility, found := getOperatorCompatibility(detection.Name); found {
+		if err := detected.CheckCompatible(detection, compatibility.supported.versions, compatibility.supported.text); err != nil {
+			return false, err
+		}
+	}
+
+	status := orchestratorv1alpha2.OperatorStatus{
+		Name:      detection.Name,
+		Source:    orchestratorv1alpha2.OperatorSourceExternal,
+		CSV:       detected.CSV,
+		Namespace: detected.Namespace,
+	}
+	if detected.Version != nil {
+		status.Version = detected.Version.String()
+	}
+	setOperatorStatus(orchestrator, status)
+	// the namespace of an adopted operator is never deleted, even when it was created for a previous Subscription
+	if detected.Namespace != "" {
+		k8client.ForgetNamespace(detected.Namespace)
+	}
+	log.FromContext(ctx).Info("Adopted external operator", "Operator", detection.Name, "CSV", detected.CSV)
+	return true, detected.CheckReady()
+}
+
+// setOperatorStatus reports how the operator is provided in the Orchestrator status.
+func setOperatorStatus(orchestrator *orchestratorv1alpha2.Orchestrator, status orchestratorv1alpha2.OperatorStatus) {
+	index := slices.IndexFunc(orchestrator.Status.Operators, func(operator orchestratorv1alpha2.OperatorStatus) bool {
+		return operator.Name == status.Name
+	})
+	if index < 0 {
+		orchestrator.Status.Operators = append(orchestrator.Status.Operators, status)
+	} else {
+		orchestrator.Status.Operators[index] = status
+	}
+	setOperatorSourcesCondition(orchestrator)
+}
+
+// removeOperatorStatus removes the operator which is no longer used by the Orchestrator from its status.
+func removeOperatorStatus(orchestrator *orchestratorv1alpha2.Orchestrator, name string) {
+	orchestrator.Status.Operators = slices.DeleteFunc(orchestrator.Status.Operators, func(operator orchestratorv1alpha2.OperatorStatus) bool {
+		return operator.Name == name
+	})
+	setOperatorSourcesCondition(orchestrator)
+}
+
+// setOperatorSourcesCondition lists the managed and external operators in the OperatorSources condition.
+func setOperatorSourcesCondition(orchestrator *orchestratorv1alpha2.Orchestrator) {
+	if len(orchestrator.Status.Operators) == 0 {
+		meta.RemoveStatusCondition(&orchestrator.Status.Conditions, TypeOperatorSources)
+		return
+	}
+	var managed, external []string
+	for _, operator := range orchestrator.Status.Operators {
+		if operator.Source == orchestratorv1alpha2.OperatorSourceManaged {
+			managed = append(managed, operator.Name)
+			continue
+		}
+		switch {
+		case operator.CSV == "":
+			external = append(external, fmt.Sprintf("%s (not installed by OLM)", operator.Name))
+		case operator.Version == "":
+			external = append(external, fmt.Sprintf("%s (%s)", operator.Name, kube.FormatObjectName(operator.Namespace, operator.CSV)))
+		default:
+			external = append(external, fmt.Sprintf("%s (%s, version %s)",
+				operator.Name, kube.FormatObjectName(operator.Namespace, operator.CSV), operator.Version))
+		}
+	}
+
+	condition := metav1.Condition{
+		Type:               TypeOperatorSources,
+		Status:             metav1.ConditionTrue,
+		Reason:             ReasonManagedOperators,
+		ObservedGeneration: orchestrator.Generation,
+	}
+	var parts []string
+	if len(managed) > 0 {
+		parts = append(parts, "Managed: "+strings.Join(managed, ", "))
+	}
+	if len(external) > 0 {
+		condition.Reason = ReasonExternalOperators
+		parts = append(parts, "External: "+strings.Join(external, ", "))
+	}
+	condition.Message = strings.Join(parts, "; ")
+	meta.SetStatusCondition(&orchestrator.Status.Conditions, condition)
+}
@@ -0,0 +1,159 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	"github.com/blang/semver/v4"
+	"github.com/operator-framework/api/pkg/lib/version"
+	"github.com/operator-framework/api/pkg/operators/v1alpha1"
+	olmclientsetfake "github.com/operator-framework/operator-lifecycle-manager/pkg/api/client/clientset/versioned/fake"
+	orchestratorv1alpha2 "github.com/rhdhorchestrator/orchestrator-operator/api/v1alpha3"
+	"github.com/rhdhorchestrator/orchestrator-operator/internal/controller/kube"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestAdoptOperator(t *testing.T) {
+	ctx := context.TODO()
+	scheme := runtime.NewScheme()
+	utilruntime.Must(corev1.AddToScheme(scheme))
+	utilruntime.Must(apiextensionsv1.AddToScheme(scheme))
+	utilruntime.Must(orchestratorv1alpha2.AddToScheme(scheme))
+
+	crd := &apiextensionsv1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: knativeServingCRDName}}
+	newCSV := func(csvVersion string, phase v1alpha1.ClusterServiceVersionPhase) *v1alpha1.ClusterServiceVersion {
+		return &v1alpha1.ClusterServiceVersion{
+			ObjectMeta: metav1.ObjectMeta{Name: "serverless-operator.v" + csvVersion, Namespace: knativeOperatorNamespace},
+			Spec: v1alpha1.ClusterServiceVersionSpec{
+				Version: version.OperatorVersion{Version: semver.MustParse(csvVersion)},
+				CustomResourceDefinitions: v1alpha1.CustomResourceDefinitions{
+					Owned: []v1alpha1.CRDDescription{{Name: knativeServingCRDName}},
+				},
+			},
+			Status: v1alpha1.ClusterServiceVersionStatus{Phase: phase},
+		}
+	}
+
+	testCases := []struct {
+		name            string
+		mode            orchestratorv1alpha2.OperatorInstallMode
+		objects         []client.Object
+		olmObjects      []runtime.Object
+		expectAdopted   bool
+		expectNotReady  bool
+		expectErr       bool
+		expectedStatus  orchestratorv1alpha2.OperatorStatus
+		expectedReason  string
+		expectedMessage string
+	}{
+		{
+			name:            "Managed mode installs the operator",
+			mode:            orchestratorv1alpha2.OperatorInstallModeManaged,
+			objects:         []client.Object{crd},
+			olmObjects:      []runtime.Object{newCSV("1.35.0", v1alpha1.CSVPhaseSucceeded)},
+			expectedStatus:  orchestratorv1alpha2.OperatorStatus{Name: "Knative", Source: orchestratorv1alpha2.OperatorSourceManaged},
+			expectedReason:  ReasonManagedOperators,
+			expectedMessage: "Managed: Knative",
+		},
+		{
+			name:            "Detect mode installs a missing operator",
+			mode:            orchestratorv1alpha2.OperatorInstallModeDetect,
+			expectedStatus:  orchestratorv1alpha2.OperatorStatus{Name: "Knative", Source: orchestratorv1alpha2.OperatorSourceManaged},
+			expectedReason:  ReasonManagedOperators,
+			expectedMessage: "Managed: Knative",
+		},
+		{
+			name:          "Detect mode adopts an installed operator",
+			mode:          orchestratorv1alpha2.OperatorInstallModeDetect,
+			objects:       []client.Object{crd},
+			olmObjects:    []runtime.Object{newCSV("1.35.2", v1alpha1.CSVPhaseSucceeded)},
+			expectAdopted: true,
+			expectedStatus: orchestratorv1alpha2.OperatorStatus{Name: "Knative", Source: orchestratorv1alpha2.OperatorSourceExternal,
+				CSV: "serverless-operator.v1.35.2", Namespace: knativeOperatorNamespace, Version: "1.35.2"},
+			expectedReason:  ReasonExternalOperators,
+			expectedMessage: "External: Knative (openshift-serverless/serverless-operator.v1.35.2, version 1.35.2)",
+		},
+		{
+			name:           "Detect mode awaits an installing operator",
+			mode:           orchestratorv1alpha2.OperatorInstallModeDetect,
+			objects:        []client.Object{crd},
+			olmObjects:     []runtime.Object{newCSV("1.35.2", v1alpha1.CSVPhaseInstalling)},
+			expectAdopted:  true,
+			expectNotReady: true,
+			expectedStatus: orchestratorv1alpha2.OperatorStatus{Name: "Knative", Source: orchestratorv1alpha2.OperatorSourceExternal,
+				CSV: "serverless-operator.v1.35.2", Namespace: knativeOperatorNamespace, Version: "1.35.2"},
+			expectedReason: ReasonExternalOperators,
+		},
+		{
+			name:       "Detect mode rejects an unsupported operator",
+			mode:       orchestratorv1alpha2.OperatorInstallModeDetect,
+			objects:    []client.Object{crd},
+			olmObjects: []runtime.Object{newCSV("1.33.0", v1alpha1.CSVPhaseSucceeded)},
+			expectErr:  true,
+		},
+		{
+			name:       "Detect mode rejects a newer unsupported operator",
+			mode:       orchestratorv1alpha2.OperatorInstallModeDetect,
+			objects:    []client.Object{crd},
+			olmObjects: []runtime.Object{newCSV("1.36.0", v1alpha1.CSVPhaseSucceeded)},
+			expectErr:  true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			orchestrator := newTestOrchestrator()
+			// the operator namespace was created for a previous Subscription
+			orchestrator.Status.Inventory = []orchestratorv1alpha2.InventoryEntry{
+				{APIVersion: "v1", Kind: "Namespace", Name: knativeOperatorNamespace}}
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(tc.objects...).Build()
+			r := &OrchestratorReconciler{Client: fakeClient, Scheme: scheme, OLMClient: olmclientsetfake.NewSimpleClientset(tc.olmObjects...)}
+			k8client := kube.NewInventoryClient(fakeClient, fakeClient, r.OLMClient, nil, orchestrator)
+
+			adopted, err := r.adoptOperator(ctx, k8client, orchestrator, tc.mode, knativeOperatorDetection)
+			if tc.expectErr {
+				assert.ErrorContains(t, err, "is not compatible")
+				assert.Empty(t, orchestrator.Status.Operators)
+				return
+			}
+			if tc.expectNotReady {
+				assert.True(t, kube.IsNotReady(err))
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.Equal(t, tc.expectAdopted, adopted)
+			assert.Equal(t, []orchestratorv1alpha2.OperatorStatus{tc.expectedStatus}, orchestrator.Status.Operators)
+			assert.Equal(t, tc.expectAdopted, len(orchestrator.Status.Inventory) == 0,
+				"The namespace of an adopted operator is never deleted")
+
+			condition := meta.FindStatusCondition(orchestrator.Status.Conditions, TypeOperatorSources)
+			assert.NotNil(t, condition)
+			assert.Equal(t, tc.expectedReason, condition.Reason)
+			assert.Contains(t, condition.Message, tc.expectedMessage)
+		})
+	}
+}
+
+func TestSetOperatorSourcesCondition(t *testing.T) {
+	orchestrator := newTestOrchestrator()
+	setOperatorStatus(orchestrator, orchestratorv1alpha2.OperatorStatus{Name: "Knative", Source: orchestratorv1alpha2.OperatorSourceManaged})
+	setOperatorStatus(orchestrator, orchestratorv1alpha2.OperatorStatus{Name: "RHDH", Source: orchestratorv1alpha2.OperatorSourceExternal})
+	condition := meta.FindStatusCondition(orchestrator.Status.Conditions, TypeOperatorSources)
+	assert.Equal(t, "Managed: Knative; External: RHDH (not installed by OLM)", condition.Message)
+
+	removeOperatorStatus(orchestrator, "RHDH")
+	condition = meta.FindStatusCondition(orchestrator.Status.Conditions, TypeOperatorSources)
+	assert.Equal(t, ReasonManagedOperators, condition.Reason)
+	assert.Equal(t, "Managed: Knative", condition.Message)
+
+	removeOperatorStatus(orchestrator, "Knative")
+	assert.Nil(t, meta.FindStatusCondition(orchestrator.Status.Conditions, TypeOperatorSources))
+}
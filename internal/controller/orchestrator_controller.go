@@ -18,15 +18,21 @@ package controller
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	olmv1alpha1 "github.com/operator-framework/api/pkg/operators/v1alpha1"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/rhdhorchestrator/orchestrator-operator/internal/controller/kube"
 	"github.com/rhdhorchestrator/orchestrator-operator/internal/controller/rhdh"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/types"
@@ -35,6 +41,8 @@ import (
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 
 	configv1 "github.com/openshift/api/config/v1"
@@ -53,32 +61,65 @@ const (
 	TypeCompleted string = "Completed"
 	TypeDegrading string = "Degrading"
 
+	// Definition of the per component conditions.
+	TypeServerlessLogicReady string = "ServerlessLogicReady"
+	TypeKnativeReady         string = "KnativeReady"
+	TypeRHDHReady            string = "RHDHReady"
+	TypeNetworkPoliciesReady string = "NetworkPoliciesReady"
+	TypeGitOpsReady          string = "GitOpsReady"
+	TypePostgresReady        string = "PostgresReady"
+
+	// Reasons shared by the per component conditions.
+	ReasonReconciling = "Reconciling"
+	ReasonReconciled  = "Reconciled"
+	ReasonDisabled    = "Disabled"
+	// ReasonNotManaged reports an object of the component which exists without being created by the operator.
+	ReasonNotManaged = "ResourceNotManaged"
+
+	// ReasonInstalling reports on the Completed condition the components which are not ready yet.
+	ReasonInstalling = "Installing"
+
 	// Finalizer Definition
 	FinalizerCRCleanup = "rhdh.redhat.com/orchestrator-cleanup"
 
 	RequeueAfterTime = 1 * time.Minute
+	// InstallingRequeueAfterTime is the interval at which the readiness of the components is checked
+	InstallingRequeueAfterTime = 15 * time.Second
 )
 
 // OrchestratorReconciler reconciles an Orchestrator object
 type OrchestratorReconciler struct {
 	client.Client
+	// APIReader reads the objects excluded from the cache by CacheOptions
+	APIReader client.Reader
 	OLMClient olmclientset.Interface
 	Scheme    *runtime.Scheme
 	Recorder  record.EventRecorder
+	// OperatorNamespace is the namespace the operator runs in; empty when running outside the cluster
+	OperatorNamespace string
+
+	// controller and cache start the watches on the types of the optional CRDs
+	controller     controller.Controller
+	cache          cache.Cache
+	watchesMutex   sync.Mutex
+	startedWatches map[string]bool
 }
 
 //+kubebuilder:rbac:groups=rhdh.redhat.com,resources=orchestrators,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=rhdh.redhat.com,resources=orchestrators/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=rhdh.redhat.com,resources=orchestrators/finalizers,verbs=update
-//+kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=apps,resources=deployments;statefulsets,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=core,resources=services;persistentvolumeclaims,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=core,resources=secrets;configmaps;namespaces;events,verbs=list;get;create;delete;patch;watch;update
 //+kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions,verbs=get;list;watch
 //+kubebuilder:rbac:groups=operators.coreos.com,resources=subscriptions;operatorgroups;clusterserviceversions;catalogsources;installplans,verbs=get;list;watch;create;delete;patch;update
 //+kubebuilder:rbac:groups=sonataflow.org,resources=sonataflows;sonataflowclusterplatforms;sonataflowplatforms,verbs=get;list;watch;create;delete;patch;update
 //+kubebuilder:rbac:groups=operator.knative.dev,resources=knativeeventings;knativeservings,verbs=get;list;watch;create;delete;patch;update
+//+kubebuilder:rbac:groups=eventing.knative.dev,resources=brokers,verbs=get;list;watch;create;delete;patch;update
 //+kubebuilder:rbac:groups=rhdh.redhat.com,resources=backstages,verbs=get;list;create;delete;patch;watch
 //+kubebuilder:rbac:groups=config.openshift.io,resources=ingresses,verbs=get;list;watch
 // +kubebuilder:rbac:groups=networking.k8s.io,resources=ingresses;networkpolicies,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=gateway.networking.k8s.io,resources=httproutes,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=tekton.dev,resources=pipelines,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=tekton.dev,resources=tasks,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=argoproj.io,resources=appprojects,verbs=get;list;watch;create;update;patch;delete
@@ -114,17 +155,54 @@ func (r *OrchestratorReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		return ctrl.Result{RequeueAfter: RequeueAfterTime}, err
 	}
 
+	if isReconcilePaused(orchestrator) {
+		// neither the components nor the finalizer are handled until the annotation is removed
+		logger.Info("Reconciliation is paused", "Annotation", PauseReconcileAnnotation)
+		if err := r.reportPaused(ctx, orchestrator); err != nil {
+			return ctrl.Result{RequeueAfter: RequeueAfterTime}, err
+		}
+		return ctrl.Result{}, nil
+	}
+	r.resumeReconcile(orchestrator)
+
 	if !orchestrator.DeletionTimestamp.IsZero() {
-		err := r.handleCleanUp(ctx, orchestrator)
+		if orchestrator.Annotations[DeletionPreviewAnnotation] == "true" {
+			// the finalizer holds the deletion until the annotation is removed
+			if err := r.previewCleanUp(ctx, orchestrator); err != nil {
+				return ctrl.Result{RequeueAfter: RequeueAfterTime}, err
+			}
+			return ctrl.Result{}, nil
+		}
+		condition, err := r.handleCleanUp(ctx, orchestrator)
 		if err != nil {
+			logger.Error(err, "Error occurred when cleaning up the Orchestrator resources")
+			r.Recorder.Eventf(orchestrator, corev1.EventTypeWarning, "CleanUpFailed",
+				"Failed to clean up the Orchestrator resources: %s", err)
+			_ = r.UpdateStatus(ctx, orchestrator, orchestratorv1alpha2.DeletingPhase, metav1.Condition{
+				Type:    TypeDeleting,
+				Status:  metav1.ConditionFalse,
+				Reason:  "CleanUpFailed",
+				Message: err.Error(),
+			})
 			return ctrl.Result{RequeueAfter: RequeueAfterTime}, err
 		}
+		if condition != nil {
+			// the status keeps the inventory of the objects still to delete
+			if err := r.UpdateStatus(ctx, orchestrator, orchestratorv1alpha2.DeletingPhase, *condition); err != nil {
+				return ctrl.Result{RequeueAfter: RequeueAfterTime}, err
+			}
+			return ctrl.Result{RequeueAfter: DeletionRequeueAfterTime}, nil
+		}
 		// Remove the finalizer to complete deletion
 		controllerutil.RemoveFinalizer(orchestrator, FinalizerCRCleanup)
 		if err := r.Update(ctx, orchestrator); err != nil {
 			return ctrl.Result{}, err
 		}
+		deleteOrchestratorMetrics(orchestrator)
+		r.Recorder.Eventf(orchestrator, corev1.EventTypeNormal, "CleanUpCompleted",
+			"Cleaned up the Orchestrator resources with the %s deletion policy", getDeletionPolicy(orchestrator))
 		logger.Info("Successfully removed Orchestrator Custom Resource")
+		return ctrl.Result{}, nil
 	}
 
 	// Add finalizer if not present
@@ -150,105 +228,185 @@ func (r *OrchestratorReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		}
 	}
 
-	argoCDEnabled := orchestrator.Spec.ArgoCd.Enabled
-	tektonEnabled := orchestrator.Spec.Tekton.Enabled
-	serverlessWorkflowNamespace := orchestrator.Spec.PlatformConfig.Namespace
-
-	// handle serverless logic
-	if err := r.reconcileServerlessLogic(ctx, orchestrator); err != nil {
-		if apierrors.IsNotFound(err) {
-			return ctrl.Result{Requeue: true, RequeueAfter: RequeueAfterTime}, nil
-		}
-		logger.Error(err, "Error occurred when installing Serverless Logic resources")
-		_ = r.UpdateStatus(ctx, orchestrator, orchestratorv1alpha2.FailedPhase, metav1.Condition{
-			Type:               TypeDegrading,
-			Status:             metav1.ConditionFalse,
-			Reason:             "ReconcilingOSLResourcesFailed",
-			Message:            err.Error(),
-			LastTransitionTime: metav1.Now(),
-		})
+	// the objects created by the components are recorded in the inventory of the orchestrator status
+	k8client := kube.NewInventoryClient(r.Client, r.APIReader, r.OLMClient, r.Recorder, orchestrator)
+	platform, err := r.detectPlatform()
+	if err != nil {
+		logger.Error(err, "Error occurred when detecting the cluster platform")
 		return ctrl.Result{RequeueAfter: RequeueAfterTime}, err
 	}
-
-	// handle knative
-	serverlessOperator := orchestrator.Spec.ServerlessOperator
-	if err := r.reconcileKnative(ctx, serverlessOperator); err != nil {
-		if apierrors.IsNotFound(err) {
-			return ctrl.Result{Requeue: true, RequeueAfter: RequeueAfterTime}, nil
+	setPlatformStatus(orchestrator, platform)
+	components := r.components()
+	for i, component := range components {
+		start := time.Now()
+		err := component.reconcile(ctx, k8client, orchestrator, platform)
+		componentReconcileDuration.WithLabelValues(component.name).Observe(time.Since(start).Seconds())
+		if err != nil {
+			setComponentReadyMetric(orchestrator, component, false)
+			r.checkInstalledOperators(ctx, k8client, orchestrator)
+			if apierrors.IsNotFound(err) || kube.IsNotReady(err) {
+				// the operators or their operands are not ready yet; report the installation progress and retry later
+				_ = r.UpdateStatus(ctx, orchestrator, orchestratorv1alpha2.InstallingPhase, metav1.Condition{
+					Type:    component.conditionType,
+					Status:  metav1.ConditionFalse,
+					Reason:  ReasonReconciling,
+					Message: err.Error(),
+				}, metav1.Condition{
+					Type:   TypeCompleted,
+					Status: metav1.ConditionFalse,
+					Reason: ReasonInstalling,
+					// the components are reconciled in order, so the previous ones are ready
+					Message: fmt.Sprintf("%d/%d components are ready; waiting for %s: %s",
+						i, len(components), component.name, err),
+				})
+				return ctrl.Result{RequeueAfter: InstallingRequeueAfterTime}, nil
+			}
+			logger.Error(err, "Error occurred when reconciling component", "Component", component.conditionType)
+			componentReconcileErrors.WithLabelValues(component.name).Inc()
+			r.Recorder.Eventf(orchestrator, corev1.EventTypeWarning, component.failedReason,
+				"Failed to reconcile %s: %s", component.name, err)
+			reason := component.failedReason
+			if kube.IsNotManaged(err) {
+				reason = ReasonNotManaged
+			}
+			_ = r.UpdateStatus(ctx, orchestrator, orchestratorv1alpha2.FailedPhase, metav1.Condition{
+				Type:    component.conditionType,
+				Status:  metav1.ConditionFalse,
+				Reason:  reason,
+				Message: err.Error(),
+			})
+			return ctrl.Result{RequeueAfter: RequeueAfterTime}, err
 		}
-		logger.Error(err, "Error occurred when installing K-Native resources")
-		_ = r.UpdateStatus(ctx, orchestrator, orchestratorv1alpha2.FailedPhase, metav1.Condition{
-			Type:               TypeDegrading,
-			Status:             metav1.ConditionFalse,
-			Reason:             "ReconcilingKNativeResourcesFailed",
-			Message:            err.Error(),
-			LastTransitionTime: metav1.Now(),
-		})
-		return ctrl.Result{RequeueAfter: RequeueAfterTime}, err
+		setComponentCondition(orchestrator, component)
+		setComponentReadyMetric(orchestrator, component, true)
 	}
 
-	// handle RHDH
-	rhdhConfig := orchestrator.Spec.RHDHConfig
-	if err := r.reconcileRHDH(ctx, serverlessWorkflowNamespace, argoCDEnabled, tektonEnabled, rhdhConfig); err != nil {
-		if apierrors.IsNotFound(err) {
-			return ctrl.Result{Requeue: true, RequeueAfter: RequeueAfterTime}, nil
+	if orchestrator.Spec.Disconnected.Enabled {
+		condition, err := getDisconnectedCondition(orchestrator, platform)
+		if err != nil {
+			logger.Error(err, "Error occurred when checking the rendered configuration for external URLs")
+			return ctrl.Result{RequeueAfter: RequeueAfterTime}, err
 		}
-		logger.Error(err, "Error occurred when creating RHDH resources")
-		_ = r.UpdateStatus(ctx, orchestrator, orchestratorv1alpha2.FailedPhase, metav1.Condition{
-			Type:               TypeDegrading,
-			Status:             metav1.ConditionFalse,
-			Reason:             "ReconcilingRHDHResourcesFailed",
-			Message:            err.Error(),
-			LastTransitionTime: metav1.Now(),
-		})
-		return ctrl.Result{Requeue: true, RequeueAfter: RequeueAfterTime}, err
+		condition.ObservedGeneration = orchestrator.Generation
+		meta.SetStatusCondition(&orchestrator.Status.Conditions, condition)
+	} else {
+		meta.RemoveStatusCondition(&orchestrator.Status.Conditions, TypeDisconnectedReady)
 	}
+	r.checkInstalledOperators(ctx, k8client, orchestrator)
 
-	// handle network policies
-	if err := r.reconcileNetworkPolicy(ctx, orchestrator); err != nil {
-		if apierrors.IsNotFound(err) {
-			return ctrl.Result{Requeue: true, RequeueAfter: RequeueAfterTime}, nil
-		}
-		logger.Error(err, "Error occurred when installing NetworkPolicy")
-		_ = r.UpdateStatus(ctx, orchestrator, orchestratorv1alpha2.FailedPhase, metav1.Condition{
-			Type:               TypeDegrading,
-			Status:             metav1.ConditionFalse,
-			Reason:             "ReconcilingNetworkPolicyFailed",
-			Message:            err.Error(),
-			LastTransitionTime: metav1.Now(),
-		})
-		return ctrl.Result{Requeue: true, RequeueAfter: RequeueAfterTime}, err
+	_ = r.UpdateStatus(ctx, orchestrator, orchestratorv1alpha2.CompletedPhase, metav1.Condition{
+		Type:    TypeCompleted,
+		Status:  metav1.ConditionTrue,
+		Reason:  "ReconciliationCompleted",
+		Message: "Reconciliation has completed",
+	})
+	return ctrl.Result{}, nil
+}
+
+// checkInstalledOperators records the metrics of the installed operators and checks their versions against the
+// compatibility matrix. It runs after the components, which detect and install the operators of the status.
+func (r *OrchestratorReconciler) checkInstalledOperators(
+	ctx context.Context, k8client *kube.InventoryClient, orchestrator *orchestratorv1alpha2.Orchestrator) {
+	logger := log.FromContext(ctx)
+	if err := recordOperatorMetrics(ctx, k8client.InstalledCSVs(), orchestrator); err != nil {
+		logger.Error(err, "Error occurred when recording the metrics of the installed operators")
+	}
+	if err := r.checkCompatibility(ctx, k8client.InstalledCSVs(), orchestrator); err != nil {
+		logger.Error(err, "Error occurred when checking the installed versions against the compatibility matrix")
 	}
+}
 
-	// handle gitops
-	if err := r.reconcileGitOps(ctx, orchestrator); err != nil {
-		if apierrors.IsNotFound(err) {
-			return ctrl.Result{Requeue: true, RequeueAfter: RequeueAfterTime}, nil
-		}
-		logger.Error(err, "Error occurred when installing GitOps")
-		_ = r.UpdateStatus(ctx, orchestrator, orchestratorv1alpha2.FailedPhase, metav1.Condition{
-			Type:               TypeDegrading,
-			Status:             metav1.ConditionFalse,
-			Reason:             "ReconcilingGitOpsFailed",
-			Message:            err.Error(),
-			LastTransitionTime: metav1.Now(),
-		})
-		return ctrl.Result{Requeue: true, RequeueAfter: RequeueAfterTime}, err
+// orchestratorComponent describes a component managed by the Orchestrator and the condition reporting its state.
+type orchestratorComponent struct {
+	name          string
+	conditionType string
+	failedReason  string
+	enabled       func(spec orchestratorv1alpha2.OrchestratorSpec) bool
+	// reconcile is given the platform detected once per reconciliation
+	reconcile func(ctx context.Context, k8client *kube.InventoryClient, orchestrator *orchestratorv1alpha2.Orchestrator,
+		platform clusterPlatform) error
+}
+
+// components returns the Orchestrator components in the order they are reconciled.
+func (r *OrchestratorReconciler) components() []orchestratorComponent {
+	return []orchestratorComponent{
+		{
+			name:          "PostgreSQL",
+			conditionType: TypePostgresReady,
+			failedReason:  "ReconcilingPostgresResourcesFailed",
+			enabled: func(spec orchestratorv1alpha2.OrchestratorSpec) bool {
+				return spec.PostgresConfig.Provision
+			},
+			reconcile: r.reconcilePostgres,
+		},
+		{
+			name:          "K-Native",
+			conditionType: TypeKnativeReady,
+			failedReason:  "ReconcilingKNativeResourcesFailed",
+			enabled: func(spec orchestratorv1alpha2.OrchestratorSpec) bool {
+				return spec.ServerlessOperator.InstallOperator
+			},
+			reconcile: r.reconcileKnative,
+		},
+		{
+			name:          "Serverless Logic",
+			conditionType: TypeServerlessLogicReady,
+			failedReason:  "ReconcilingOSLResourcesFailed",
+			enabled: func(spec orchestratorv1alpha2.OrchestratorSpec) bool {
+				return spec.ServerlessLogicOperator.InstallOperator
+			},
+			reconcile: r.reconcileServerlessLogic,
+		},
+		{
+			name:          "RHDH",
+			conditionType: TypeRHDHReady,
+			failedReason:  "ReconcilingRHDHResourcesFailed",
+			enabled: func(spec orchestratorv1alpha2.OrchestratorSpec) bool {
+				return spec.RHDHConfig.InstallOperator
+			},
+			reconcile: r.reconcileRHDH,
+		},
+		{
+			name:          "Network Policies",
+			conditionType: TypeNetworkPoliciesReady,
+			failedReason:  "ReconcilingNetworkPolicyFailed",
+			enabled: func(spec orchestratorv1alpha2.OrchestratorSpec) bool {
+				return true
+			},
+			reconcile: r.reconcileNetworkPolicy,
+		},
+		{
+			name:          "GitOps",
+			conditionType: TypeGitOpsReady,
+			failedReason:  "ReconcilingGitOpsFailed",
+			enabled: func(spec orchestratorv1alpha2.OrchestratorSpec) bool {
+				return spec.ArgoCd.Enabled && spec.Tekton.Enabled
+			},
+			reconcile: r.reconcileGitOps,
+		},
 	}
+}
 
-	_ = r.UpdateStatus(ctx, orchestrator, orchestratorv1alpha2.CompletedPhase, metav1.Condition{
-		Type:               TypeCompleted,
+// setComponentCondition records a successful reconciliation of the component in the orchestrator status.
+func setComponentCondition(orchestrator *orchestratorv1alpha2.Orchestrator, component orchestratorComponent) {
+	condition := metav1.Condition{
+		Type:               component.conditionType,
 		Status:             metav1.ConditionTrue,
-		Reason:             "ReconciliationCompleted",
-		Message:            "Reconciliation has completed",
-		LastTransitionTime: metav1.Now(),
-	})
-	return ctrl.Result{}, nil
+		Reason:             ReasonReconciled,
+		Message:            fmt.Sprintf("%s resources are reconciled", component.name),
+		ObservedGeneration: orchestrator.Generation,
+	}
+	if !component.enabled(orchestrator.Spec) {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = ReasonDisabled
+		condition.Message = fmt.Sprintf("%s is disabled in the Orchestrator spec", component.name)
+	}
+	meta.SetStatusCondition(&orchestrator.Status.Conditions, condition)
 }
 
 func (r *OrchestratorReconciler) reconcileServerlessLogic(
-	ctx context.Context,
-	orchestrator *orchestratorv1alpha2.Orchestrator) error {
+	ctx context.Context, k8client *kube.InventoryClient, orchestrator *orchestratorv1alpha2.Orchestrator,
+	_ clusterPlatform) error {
 
 	sfLogger := log.FromContext(ctx)
 	sfLogger.Info("Starting reconciliation for Serverless Logic")
@@ -259,14 +417,16 @@ func (r *OrchestratorReconciler) reconcileServerlessLogic(
 	// check if install operator is disabled and handle clean up if necessary
 	if !serverlessLogicOperator.InstallOperator {
 		sfLogger.Info("Operator is disabled. Handle Clean up process if necessary")
+		meta.RemoveStatusCondition(&orchestrator.Status.Conditions, TypeDatabaseReachable)
+		removeOperatorStatus(orchestrator, serverlessLogicOperatorDetection.Name)
 		// handle clean up
-		return handleServerlessLogicCleanUp(ctx, r.Client, serverlessWorkflowNamespace)
+		return handleServerlessLogicCleanUp(ctx, k8client, getWorkflowNamespaces(orchestrator.Spec.PlatformConfig))
 	}
 	// Subscription is enabled; check namespace exist
-	if _, err := kube.CheckNamespaceExist(ctx, r.Client, serverlessWorkflowNamespace); err != nil {
+	if _, err := kube.CheckNamespaceExist(ctx, k8client, serverlessWorkflowNamespace); err != nil {
 		if apierrors.IsNotFound(err) {
 			sfLogger.Info("Creating namespace", "NS", serverlessWorkflowNamespace)
-			if err := kube.CreateNamespace(ctx, r.Client, serverlessWorkflowNamespace); err != nil {
+			if err := kube.CreateNamespace(ctx, k8client, serverlessWorkflowNamespace); err != nil {
 				sfLogger.Error(err, "Error occurred when creating namespace", "NS", serverlessWorkflowNamespace)
 				return err
 			}
@@ -275,14 +435,22 @@ func (r *OrchestratorReconciler) reconcileServerlessLogic(
 		return err
 	}
 
-	if err := handleServerlessLogicOperatorInstallation(ctx, r.Client, r.OLMClient); err != nil {
-		sfLogger.Error(err, "Error occurred when installing OSL Operator resources")
+	adopted, err := r.adoptOperator(ctx, k8client, orchestrator, serverlessLogicOperator.InstallMode, serverlessLogicOperatorDetection)
+	if err != nil {
 		return err
 	}
+	if !adopted {
+		if err := handleServerlessLogicOperatorInstallation(
+			ctx, k8client, r.Recorder, orchestrator, r.OLMClient, orchestrator.Spec.ServerlessLogicOperator.Subscription,
+			getInstallPlanPolicy(orchestrator, serverlessLogicOperatorDetection)); err != nil {
+			sfLogger.Error(err, "Error occurred when installing OSL Operator resources")
+			return err
+		}
+	}
 
 	// subscription exists; check if CRD exists;
 	sonataFlowClusterPlatformCRD := &apiextensionsv1.CustomResourceDefinition{}
-	if err := r.Get(ctx, types.NamespacedName{Name: sonataFlowClusterPlatformCRDName, Namespace: serverlessWorkflowNamespace}, sonataFlowClusterPlatformCRD); err != nil {
+	if err := k8client.Get(ctx, types.NamespacedName{Name: sonataFlowClusterPlatformCRDName, Namespace: serverlessWorkflowNamespace}, sonataFlowClusterPlatformCRD); err != nil {
 		if apierrors.IsNotFound(err) {
 			// CRD does not exist
 			sfLogger.Info("CRD resource not found or ready.", "SubscriptionName", serverlessLogicSubscriptionName, "Namespace", serverlessWorkflowNamespace)
@@ -292,91 +460,158 @@ func (r *OrchestratorReconciler) reconcileServerlessLogic(
 		return err
 	}
 
+	// preflight the database used by Data Index and Job Service; a failure is reported but does not block the platform
+	postgresConfig := orchestrator.Spec.PostgresConfig
+	databaseCondition := getDatabaseCondition(ctx, k8client, postgresConfig, getDatabaseHost(postgresConfig), postgresPort)
+	if databaseCondition.Status != metav1.ConditionTrue {
+		sfLogger.Info("Database preflight failed", "Reason", databaseCondition.Reason, "Message", databaseCondition.Message)
+	}
+	databaseCondition.ObservedGeneration = orchestrator.Generation
+	meta.SetStatusCondition(&orchestrator.Status.Conditions, databaseCondition)
+
 	// handle serverless logic CRs
-	if err := handleServerlessLogicCR(ctx, r.Client, orchestrator); err != nil {
+	if err := handleServerlessLogicCR(ctx, k8client, r.Recorder, orchestrator); err != nil {
+		return err
+	}
+	if err := handleAdditionalWorkflowNamespaces(ctx, k8client, r.Recorder, orchestrator); err != nil {
+		return err
+	}
+	if err := checkSonataFlowPlatformReady(ctx, k8client, serverlessWorkflowNamespace); err != nil {
 		return err
 	}
 	sfLogger.Info("Successfully created ServerlessLogic Resources")
 	return nil
 }
 
-func (r *OrchestratorReconciler) reconcileKnative(ctx context.Context, serverlessOperator orchestratorv1alpha2.ServerlessOperator) error {
+func (r *OrchestratorReconciler) reconcileKnative(
+	ctx context.Context, k8client *kube.InventoryClient, orchestrator *orchestratorv1alpha2.Orchestrator,
+	_ clusterPlatform) error {
 	knativeLogger := log.FromContext(ctx)
 	knativeLogger.Info("Starting Reconciliation for K-Native Serverless")
 
+	serverlessOperator := orchestrator.Spec.ServerlessOperator
 	// if subscription is disabled; check if subscription exists and handle delete
 	if !serverlessOperator.InstallOperator {
+		removeOperatorStatus(orchestrator, knativeOperatorDetection.Name)
 		// handle cleanup
-		if err := handleKnativeCleanUp(ctx, r.Client); err != nil {
+		if err := handleKnativeCleanUp(ctx, k8client); err != nil {
 			return err
 		}
-		return nil
+		return r.reconcileBroker(ctx, k8client, orchestrator)
 	}
 
-	// Subscription is enabled;
-	if err := handleKNativeOperatorInstallation(ctx, r.Client, r.OLMClient); err != nil {
-		knativeLogger.Error(err, "Error occurred when installing Knative Operator resources")
+	// Subscription is enabled, unless the operator installed on the cluster is adopted
+	adopted, err := r.adoptOperator(ctx, k8client, orchestrator, serverlessOperator.InstallMode, knativeOperatorDetection)
+	if err != nil {
 		return err
 	}
+	if !adopted {
+		if err := handleKNativeOperatorInstallation(ctx, k8client, r.Recorder, orchestrator, r.OLMClient, serverlessOperator.Subscription,
+			getInstallPlanPolicy(orchestrator, knativeOperatorDetection)); err != nil {
+			knativeLogger.Error(err, "Error occurred when installing Knative Operator resources")
+			return err
+		}
+	}
 
 	// handle knative CRs
-	if err := handleKnativeCR(ctx, r.Client); err != nil {
+	if err := handleKnativeCR(ctx, k8client, r.Recorder, orchestrator); err != nil {
 		knativeLogger.Error(err, "Error occurred when handling Knative Custom Resources")
 		return err
 	}
 	knativeLogger.Info("Successfully created Knative Custom Resources")
+
+	// the broker is served once Knative Eventing is ready
+	if err := checkKnativeReady(ctx, k8client); err != nil {
+		return err
+	}
+	return r.reconcileBroker(ctx, k8client, orchestrator)
+}
+
+func (r *OrchestratorReconciler) reconcilePostgres(
+	ctx context.Context, k8client *kube.InventoryClient, orchestrator *orchestratorv1alpha2.Orchestrator,
+	_ clusterPlatform) error {
+	if err := handlePostgres(ctx, k8client, r.Recorder, orchestrator); err != nil {
+		log.FromContext(ctx).Error(err, "Error occurred when handling provisioned PostgreSQL")
+		return err
+	}
 	return nil
 }
 
-func (r *OrchestratorReconciler) reconcileRHDH(
-	ctx context.Context, serverlessWorkflowNamespace string,
-	argoCDEnabled, tektonEnabled bool,
-	rhdhConfig orchestratorv1alpha2.RHDHConfig) error {
+func (r *OrchestratorReconciler) reconcileBroker(
+	ctx context.Context, k8client *kube.InventoryClient, orchestrator *orchestratorv1alpha2.Orchestrator) error {
+	if err := handleBrokerCleanUp(ctx, k8client, orchestrator.Spec.PlatformConfig.Eventing.Broker); err != nil {
+		return err
+	}
+	brokerURL, err := handleBroker(ctx, k8client, orchestrator)
+	orchestrator.Status.BrokerURL = brokerURL
+	if err != nil {
+		log.FromContext(ctx).Error(err, "Error occurred when handling Knative Broker")
+		return err
+	}
+	return nil
+}
 
+func (r *OrchestratorReconciler) reconcileRHDH(
+	ctx context.Context, k8client *kube.InventoryClient, orchestrator *orchestratorv1alpha2.Orchestrator,
+	platform clusterPlatform) error {
 	logger := log.FromContext(ctx)
 	logger.Info("Starting Reconciliation for RHDH")
 
+	rhdhConfig := orchestrator.Spec.RHDHConfig
 	subscriptionName := rhdhConfig.Name
 	namespace := rhdhConfig.Namespace
 
 	// if install operator is disabled; handle clean up
 	if !rhdhConfig.InstallOperator {
-		if err := rhdh.HandleRHDHCleanUp(ctx, r.Client, namespace); err != nil {
+		removeOperatorStatus(orchestrator, rhdh.RHDHOperatorDetection.Name)
+		if err := rhdh.HandleRHDHCleanUp(ctx, k8client, namespace); err != nil {
 			logger.Error(err, "Error occurred when cleaning up RHDH", "SubscriptionName", subscriptionName)
 			return err
 		}
 		return nil
 	}
 
-	if err := rhdh.HandleRHDHOperatorInstallation(ctx, r.Client, r.OLMClient); err != nil {
-		logger.Error(err, "Error occurred when installing RHDH Operator resources")
+	adopted, err := r.adoptOperator(ctx, k8client, orchestrator, rhdhConfig.InstallMode, rhdh.RHDHOperatorDetection)
+	if err != nil {
 		return err
 	}
+	if !adopted {
+		if err := rhdh.HandleRHDHOperatorInstallation(ctx, k8client, r.Recorder, orchestrator, r.OLMClient, rhdhConfig.Subscription,
+			getInstallPlanPolicy(orchestrator, rhdh.RHDHOperatorDetection)); err != nil {
+			logger.Error(err, "Error occurred when installing RHDH Operator resources")
+			return err
+		}
+	}
 
-	// get cluster domain name
-	clusterDomain, err := r.getClusterDomain(ctx)
+	baseURL, err := r.getRHDHBaseURL(ctx, orchestrator, platform)
 	if err != nil {
 		return err
 	}
 
 	// create secret
-	if err := rhdh.CreateRHDHSecret(namespace, ctx, r.Client); err != nil {
+	if err := rhdh.CreateRHDHSecret(namespace, orchestrator.Spec.Disconnected, ctx, k8client); err != nil {
 		return err
 	}
 
-	// create configmap
-	logger.Info("Creating configmap for RHDH CR...")
-	bsConfigMapList, err := rhdh.GetOrCreateConfigMaps(ctx, r.Client, clusterDomain, serverlessWorkflowNamespace, argoCDEnabled, tektonEnabled, rhdhConfig)
+	// create or update configmap
+	logger.Info("Reconciling configmap for RHDH CR...")
+	bsConfigMapList, err := rhdh.ReconcileConfigMaps(ctx, k8client, r.Recorder, orchestrator, baseURL)
 	if err != nil {
 		return err
 	}
 	logger.Info("Configmap list", "CM-List", bsConfigMapList)
 
 	// handle RHDH CR
-	if err := rhdh.HandleRHDHCR(rhdhConfig, bsConfigMapList, ctx, r.Client); err != nil {
+	if err := rhdh.HandleRHDHCR(rhdhConfig, bsConfigMapList, ctx, k8client); err != nil {
 		return err
 	}
-	return nil
+	// the RHDH operator creates a Route on OpenShift only
+	if !platform.openShift {
+		if err := rhdh.HandleRHDHIngress(ctx, k8client, r.Recorder, orchestrator, rhdhConfig, baseURL); err != nil {
+			return err
+		}
+	}
+	return rhdh.CheckRHDHReady(ctx, k8client, rhdhConfig)
 }
 
 // getClusterDomain retrieves the OpenShift cluster domain from the Ingress resource
@@ -408,28 +643,16 @@ func (r *OrchestratorReconciler) addFinalizers(ctx context.Context, orchestrator
 	return nil
 }
 
-func (r *OrchestratorReconciler) handleCleanUp(ctx context.Context, orchestrator *orchestratorv1alpha2.Orchestrator) error {
-	// cleanup Knative
-	if err := handleKnativeCleanUp(ctx, r.Client); err != nil {
-		return err
-	}
-	// cleanup Serverless Logic
-	if err := handleServerlessLogicCleanUp(ctx, r.Client, orchestrator.Spec.PlatformConfig.Namespace); err != nil {
-		return err
-	}
-	// cleanup RHDH
-	if err := rhdh.HandleRHDHCleanUp(ctx, r.Client, orchestrator.Spec.RHDHConfig.Namespace); err != nil {
-		return err
-	}
-	return nil
-}
-
-// UpdateStatus sets the status of orchestrator.
-func (r *OrchestratorReconciler) UpdateStatus(ctx context.Context, orchestrator *orchestratorv1alpha2.Orchestrator, phase orchestratorv1alpha2.OrchestratorPhase, condition metav1.Condition) error {
+// UpdateStatus sets the phase and conditions of orchestrator, stamped with the observed generation.
+func (r *OrchestratorReconciler) UpdateStatus(ctx context.Context, orchestrator *orchestratorv1alpha2.Orchestrator, phase orchestratorv1alpha2.OrchestratorPhase, conditions ...metav1.Condition) error {
 	logger := log.FromContext(ctx)
 
 	orchestrator.Status.Phase = phase
-	meta.SetStatusCondition(&orchestrator.Status.Conditions, condition)
+	orchestrator.Status.ObservedGeneration = orchestrator.Generation
+	for _, condition := range conditions {
+		condition.ObservedGeneration = orchestrator.Generation
+		meta.SetStatusCondition(&orchestrator.Status.Conditions, condition)
+	}
 
 	err := r.Status().Update(ctx, orchestrator)
 	if err != nil {
@@ -439,33 +662,77 @@ func (r *OrchestratorReconciler) UpdateStatus(ctx context.Context, orchestrator
 	return nil
 }
 
-func (r *OrchestratorReconciler) reconcileNetworkPolicy(ctx context.Context, orchestrator *orchestratorv1alpha2.Orchestrator) error {
+func (r *OrchestratorReconciler) reconcileNetworkPolicy(
+	ctx context.Context, k8client *kube.InventoryClient, orchestrator *orchestratorv1alpha2.Orchestrator,
+	platform clusterPlatform) error {
 	logger := log.FromContext(ctx)
 	logger.Info("Reconciling Network Policies...")
 
 	namespace := orchestrator.Spec.PlatformConfig.Namespace
-	namespaceExist, err := kube.CheckNamespaceExist(ctx, r.Client, namespace)
+	namespaceExist, err := kube.CheckNamespaceExist(ctx, k8client, namespace)
 	if err != nil || !namespaceExist {
 		logger.Error(err, "Ensure namespace already exist", "Namespace", namespace)
 		return err
 	}
 
-	monitoringFlag := orchestrator.Spec.PlatformConfig.Monitoring.Enabled
-	networkPolicyErrors := handleNetworkPolicy(r.Client, ctx, namespace, orchestrator.Spec.RHDHConfig.Namespace, orchestrator.Spec.PostgresConfig.Namespace, monitoringFlag)
+	// the monitoring policy allows the OpenShift user workload monitoring
+	monitoringFlag := orchestrator.Spec.PlatformConfig.Monitoring.Enabled && platform.openShift
+	workflowNamespaces := getWorkflowNamespaces(orchestrator.Spec.PlatformConfig)
+	networkPolicyErrors := make(map[string]error)
+	for _, workflowNamespace := range workflowNamespaces {
+		if workflowNamespace != namespace {
+			if err := k8client.Get(ctx, types.NamespacedName{Name: workflowNamespace}, &corev1.Namespace{}); err != nil {
+				logger.Error(err, "Ensure namespace already exist", "Namespace", workflowNamespace)
+				return err
+			}
+		}
+		// the other workflow namespaces reach the shared platform services, which call back their workflows
+		var additionalNamespaces []string
+		for _, peerNamespace := range workflowNamespaces {
+			if peerNamespace != workflowNamespace {
+				additionalNamespaces = append(additionalNamespaces, peerNamespace)
+			}
+		}
+		errs := handleNetworkPolicy(k8client, ctx, r.Recorder, orchestrator, workflowNamespace, orchestrator.Spec.RHDHConfig.Namespace,
+			orchestrator.Spec.PostgresConfig.Namespace, additionalNamespaces, monitoringFlag)
+		for networkPolicyName, err := range errs {
+			networkPolicyErrors[fmt.Sprintf("%s/%s", workflowNamespace, networkPolicyName)] = err
+		}
+	}
+
+	// the database preflight reaches the database pods when they run in a workflow namespace
+	postgresConfig := orchestrator.Spec.PostgresConfig
+	if r.OperatorNamespace != "" && slices.Contains(workflowNamespaces, postgresConfig.Namespace) {
+		databaseSelector, err := getDatabaseSelector(ctx, r.APIReader, postgresConfig)
+		if err != nil {
+			networkPolicyErrors[fmt.Sprintf("%s/%s", postgresConfig.Namespace, allowOperatorToDatabase)] = err
+		} else if len(databaseSelector) > 0 {
+			if err := handleDatabaseNetworkPolicy(k8client, ctx, r.Recorder, orchestrator, postgresConfig.Namespace, r.OperatorNamespace,
+				databaseSelector); err != nil {
+				networkPolicyErrors[fmt.Sprintf("%s/%s", postgresConfig.Namespace, allowOperatorToDatabase)] = err
+			}
+		}
+	}
 
 	if len(networkPolicyErrors) > 0 {
 		var networkPolicyNames []string
+		var errs []error
 		for networkPolicyName, err := range networkPolicyErrors {
 			logger.Error(err, "Error occurred when reconciling Network Policy", "NP", networkPolicyName)
 			networkPolicyNames = append(networkPolicyNames, networkPolicyName)
+			errs = append(errs, err)
 		}
-		return fmt.Errorf("error occurred when reconciling the following Network Policies: %s ", strings.Join(networkPolicyNames, ", "))
+		// the errors are wrapped, so that the NetworkPolicies which are not managed by the operator are reported
+		return fmt.Errorf("error occurred when reconciling the following Network Policies: %s: %w",
+			strings.Join(networkPolicyNames, ", "), errors.Join(errs...))
 	}
 
 	return nil
 }
 
-func (r *OrchestratorReconciler) reconcileGitOps(ctx context.Context, orchestrator *orchestratorv1alpha2.Orchestrator) error {
+func (r *OrchestratorReconciler) reconcileGitOps(
+	ctx context.Context, k8client *kube.InventoryClient, orchestrator *orchestratorv1alpha2.Orchestrator,
+	platform clusterPlatform) error {
 	logger := log.FromContext(ctx)
 	logger.Info("Reconciling GitOps...")
 
@@ -473,7 +740,7 @@ func (r *OrchestratorReconciler) reconcileGitOps(ctx context.Context, orchestrat
 		logger.Info("Handling clean up  for GitOps...")
 
 		// handle argocd clean up
-		err := orchestratorgitops.HandleGitOpsCleanUp(r.Client, ctx, orchestrator.Spec.ArgoCd.Namespace)
+		err := orchestratorgitops.HandleGitOpsCleanUp(k8client, ctx, orchestrator.Spec.ArgoCd.Namespace)
 		if err != nil {
 			return err
 		}
@@ -483,7 +750,8 @@ func (r *OrchestratorReconciler) reconcileGitOps(ctx context.Context, orchestrat
 	}
 
 	logger.Info("Handling for GitOps...")
-	if err := orchestratorgitops.HandleGitOps(r.Client, ctx, orchestrator.Spec.ArgoCd.Namespace); err != nil {
+	if err := orchestratorgitops.HandleGitOps(
+		k8client, ctx, orchestrator.Spec.ArgoCd.Namespace, orchestrator.Spec.Disconnected, platform.openShift); err != nil {
 		return err
 	}
 
@@ -491,28 +759,9 @@ func (r *OrchestratorReconciler) reconcileGitOps(ctx context.Context, orchestrat
 }
 
 func (r *OrchestratorReconciler) reconcileSubscription(ctx context.Context, object client.Object) []reconcile.Request {
-	logger := log.FromContext(ctx)
-	logger.Info("Reconciling Operator's Subscription...")
-	subscriptionObject := object.(*olmv1alpha1.Subscription)
-
-	if subscriptionObject != nil && kube.CheckLabelExist(subscriptionObject.Labels) {
-		if (subscriptionObject.Namespace == serverlessLogicOperatorNamespace) && (subscriptionObject.Name == serverlessLogicSubscriptionName) {
-			err := handleServerlessLogicOperatorInstallation(ctx, r.Client, r.OLMClient)
-			if err != nil && !apierrors.IsNotFound(err) {
-				logger.Error(err, "Error occurred when reconciling ServerlessLogic Operator's Subscription resource")
-				return nil
-			}
-		}
-		if (subscriptionObject.Namespace == knativeOperatorNamespace) && (subscriptionObject.Name == knativeSubscriptionName) {
-			err := handleKNativeOperatorInstallation(ctx, r.Client, r.OLMClient)
-			if err != nil && !apierrors.IsNotFound(err) {
-				logger.Error(err, "Error occurred when reconciling Serverless(K-Native) Operator's Subscription resource")
-				return nil
-			}
-		}
-
-	}
-	return nil
+	// the subscription settings are read from the Orchestrator CRs
+	log.FromContext(ctx).Info("Operator's Subscription changed", "Subscription", object.GetName(), "Namespace", object.GetNamespace())
+	return r.mapToOrchestrators(ctx, object)
 }
 
 // SetupWithManager sets up the controller with the Manager.
@@ -525,12 +774,32 @@ func (r *OrchestratorReconciler) SetupWithManager(mgr ctrl.Manager) error {
 		return err
 	}
 	r.OLMClient = olmClient
+	r.APIReader = mgr.GetAPIReader()
 
-	o := ctrl.NewControllerManagedBy(mgr).
+	// the watches on the types of optional CRDs are started by reconcileOptionalCRD once the CRDs are established
+	c, err := ctrl.NewControllerManagedBy(mgr).
 		For(&orchestratorv1alpha2.Orchestrator{}).
-		Watches(&olmv1alpha1.Subscription{}, handler.EnqueueRequestsFromMapFunc(r.reconcileSubscription)).
+		Watches(&olmv1alpha1.Subscription{}, handler.EnqueueRequestsFromMapFunc(r.reconcileSubscription),
+			builder.WithPredicates(createdByPredicate)).
+		Watches(&corev1.ConfigMap{}, handler.EnqueueRequestsFromMapFunc(r.mapToOrchestrators),
+			builder.WithPredicates(createdByPredicate)).
+		Watches(&networkingv1.NetworkPolicy{}, handler.EnqueueRequestsFromMapFunc(r.mapToOrchestrators),
+			builder.WithPredicates(createdByPredicate)).
+		Watches(&networkingv1.Ingress{}, handler.EnqueueRequestsFromMapFunc(r.mapToOrchestrators),
+			builder.WithPredicates(createdByPredicate, specChangedPredicate)).
+		Watches(&appsv1.StatefulSet{}, handler.EnqueueRequestsFromMapFunc(r.mapToOrchestrators),
+			builder.WithPredicates(createdByPredicate)).
+		Watches(&corev1.Service{}, handler.EnqueueRequestsFromMapFunc(r.mapToOrchestrators),
+			builder.WithPredicates(createdByPredicate)).
+		Watches(&apiextensionsv1.CustomResourceDefinition{}, handler.EnqueueRequestsFromMapFunc(r.reconcileOptionalCRD),
+			builder.WithPredicates(optionalCRDPredicate)).
 		Owns(&orchestratorv1alpha2.Orchestrator{}).
-		WithOptions(controller.Options{MaxConcurrentReconciles: 2})
-
-	return o.Complete(r)
+		WithOptions(controller.Options{MaxConcurrentReconciles: 2}).
+		Build(r)
+	if err != nil {
+		return err
+	}
+	r.controller = c
+	r.cache = mgr.GetCache()
+	return nil
 }
@@ -0,0 +1,112 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	orchestratorv1alpha2 "github.com/rhdhorchestrator/orchestrator-operator/api/v1alpha3"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newTestOrchestrator() *orchestratorv1alpha2.Orchestrator {
+	return &orchestratorv1alpha2.Orchestrator{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       "orchestrator-sample",
+			Namespace:  testNamespace,
+			Generation: 3,
+		},
+		Spec: orchestratorv1alpha2.OrchestratorSpec{
+			ServerlessLogicOperator: orchestratorv1alpha2.ServerlessLogicOperator{InstallOperator: true},
+			ServerlessOperator:      orchestratorv1alpha2.ServerlessOperator{InstallOperator: false},
+			RHDHConfig:              orchestratorv1alpha2.RHDHConfig{Name: "my-rhdh", Namespace: testRHDHNamespace},
+			PlatformConfig:          orchestratorv1alpha2.PlatformConfig{Namespace: testNamespace},
+		},
+	}
+}
+
+func TestSetComponentCondition(t *testing.T) {
+	reconciler := &OrchestratorReconciler{}
+	orchestrator := newTestOrchestrator()
+
+	testCases := []struct {
+		name           string
+		conditionType  string
+		expectedStatus metav1.ConditionStatus
+		expectedReason string
+	}{
+		{
+			name:           "Enabled component is ready",
+			conditionType:  TypeServerlessLogicReady,
+			expectedStatus: metav1.ConditionTrue,
+			expectedReason: ReasonReconciled,
+		},
+		{
+			name:           "Disabled component is reported as disabled",
+			conditionType:  TypeKnativeReady,
+			expectedStatus: metav1.ConditionFalse,
+			expectedReason: ReasonDisabled,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, component := range reconciler.components() {
+				if component.conditionType == tc.conditionType {
+					setComponentCondition(orchestrator, component)
+				}
+			}
+			condition := meta.FindStatusCondition(orchestrator.Status.Conditions, tc.conditionType)
+			assert.NotNil(t, condition)
+			assert.Equal(t, tc.expectedStatus, condition.Status)
+			assert.Equal(t, tc.expectedReason, condition.Reason)
+			assert.Equal(t, orchestrator.Generation, condition.ObservedGeneration)
+		})
+	}
+}
+
+func TestComponentsHaveDistinctConditions(t *testing.T) {
+	reconciler := &OrchestratorReconciler{}
+	conditionTypes := map[string]bool{}
+	for _, component := range reconciler.components() {
+		assert.False(t, conditionTypes[component.conditionType], "Duplicated condition %s", component.conditionType)
+		conditionTypes[component.conditionType] = true
+	}
+	assert.Len(t, conditionTypes, 6)
+}
+
+func TestUpdateStatus(t *testing.T) {
+	ctx := context.TODO()
+	scheme := runtime.NewScheme()
+	utilruntime.Must(orchestratorv1alpha2.AddToScheme(scheme))
+
+	orchestrator := newTestOrchestrator()
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(orchestrator).
+		WithStatusSubresource(orchestrator).
+		Build()
+	reconciler := &OrchestratorReconciler{Client: fakeClient, Scheme: scheme}
+
+	err := reconciler.UpdateStatus(ctx, orchestrator, orchestratorv1alpha2.FailedPhase, metav1.Condition{
+		Type:    TypeRHDHReady,
+		Status:  metav1.ConditionFalse,
+		Reason:  "ReconcilingRHDHResourcesFailed",
+		Message: "failed",
+	})
+	assert.NoError(t, err)
+
+	updated := &orchestratorv1alpha2.Orchestrator{}
+	assert.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: orchestrator.Name, Namespace: orchestrator.Namespace}, updated))
+	assert.Equal(t, orchestratorv1alpha2.FailedPhase, updated.Status.Phase)
+	assert.Equal(t, orchestrator.Generation, updated.Status.ObservedGeneration)
+
+	condition := meta.FindStatusCondition(updated.Status.Conditions, TypeRHDHReady)
+	assert.NotNil(t, condition)
+	assert.Equal(t, orchestrator.Generation, condition.ObservedGeneration)
+	assert.Equal(t, "ReconcilingRHDHResourcesFailed", condition.Reason)
+}
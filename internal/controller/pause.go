@@ -0,0 +1,63 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	orchestratorv1alpha2 "github.com/rhdhorchestrator/orchestrator-operator/api/v1alpha3"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// PauseReconcileAnnotation freezes the changes of the operator to the resources of the Orchestrator
+	PauseReconcileAnnotation = "rhdh.redhat.com/pause-reconcile"
+
+	TypePaused string = "Paused"
+)
+
+func isReconcilePaused(orchestrator *orchestratorv1alpha2.Orchestrator) bool {
+	return orchestrator.Annotations[PauseReconcileAnnotation] == "true"
+}
+
+// reportPaused sets the Paused phase and condition, once: the status is the only change made while paused.
+func (r *OrchestratorReconciler) reportPaused(ctx context.Context, orchestrator *orchestratorv1alpha2.Orchestrator) error {
+	if orchestrator.Status.Phase == orchestratorv1alpha2.PausedPhase &&
+		meta.IsStatusConditionTrue(orchestrator.Status.Conditions, TypePaused) {
+		return nil
+	}
+	r.Recorder.Eventf(orchestrator, corev1.EventTypeNormal, "Paused",
+		"Reconciliation is paused by the %s annotation", PauseReconcileAnnotation)
+	return r.UpdateStatus(ctx, orchestrator, orchestratorv1alpha2.PausedPhase, metav1.Condition{
+		Type:    TypePaused,
+		Status:  metav1.ConditionTrue,
+		Reason:  "PauseRequested",
+		Message: "Reconciliation is paused; remove the " + PauseReconcileAnnotation + " annotation to resume",
+	})
+}
+
+// resumeReconcile removes the Paused condition once the annotation is removed; the phase is set by the
+// reconciliation which follows.
+func (r *OrchestratorReconciler) resumeReconcile(orchestrator *orchestratorv1alpha2.Orchestrator) {
+	if meta.FindStatusCondition(orchestrator.Status.Conditions, TypePaused) == nil {
+		return
+	}
+	meta.RemoveStatusCondition(&orchestrator.Status.Conditions, TypePaused)
+	r.Recorder.Event(orchestrator, corev1.EventTypeNormal, "Resumed", "Reconciliation is resumed")
+}
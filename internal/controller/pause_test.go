@@ -0,0 +1,80 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	orchestratorv1alpha2 "github.com/rhdhorchestrator/orchestrator-operator/api/v1alpha3"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestReconcilePaused(t *testing.T) {
+	ctx := context.TODO()
+	scheme := runtime.NewScheme()
+	utilruntime.Must(corev1.AddToScheme(scheme))
+	utilruntime.Must(orchestratorv1alpha2.AddToScheme(scheme))
+
+	testCases := []struct {
+		name     string
+		deleting bool
+	}{
+		{name: "Components are not reconciled"},
+		{name: "Deletion is not handled", deleting: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			orchestrator := newTestOrchestrator()
+			orchestrator.Annotations = map[string]string{PauseReconcileAnnotation: "true"}
+			orchestrator.Status.Inventory = []orchestratorv1alpha2.InventoryEntry{{APIVersion: "v1", Kind: "Namespace", Name: "workflows"}}
+			if tc.deleting {
+				orchestrator.Finalizers = []string{FinalizerCRCleanup}
+				orchestrator.DeletionTimestamp = &metav1.Time{Time: metav1.Now().Time}
+			}
+			namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "workflows"}}
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).
+				WithObjects(orchestrator, namespace).WithStatusSubresource(orchestrator).Build()
+			recorder := record.NewFakeRecorder(10)
+			r := &OrchestratorReconciler{Client: fakeClient, Scheme: scheme, Recorder: recorder}
+
+			request := ctrl.Request{NamespacedName: types.NamespacedName{Name: orchestrator.Name, Namespace: orchestrator.Namespace}}
+			for range 2 {
+				result, err := r.Reconcile(ctx, request)
+				assert.NoError(t, err)
+				assert.Equal(t, ctrl.Result{}, result)
+			}
+			assert.Len(t, recorder.Events, 1, "The pause is reported once")
+
+			updated := &orchestratorv1alpha2.Orchestrator{}
+			assert.NoError(t, fakeClient.Get(ctx, request.NamespacedName, updated))
+			assert.Equal(t, orchestratorv1alpha2.PausedPhase, updated.Status.Phase)
+			assert.True(t, meta.IsStatusConditionTrue(updated.Status.Conditions, TypePaused))
+			assert.Equal(t, tc.deleting, len(updated.Finalizers) == 1, "The finalizer is neither added nor removed")
+			assert.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: namespace.Name}, namespace), "Nothing is deleted")
+		})
+	}
+}
+
+func TestResumeReconcile(t *testing.T) {
+	orchestrator := newTestOrchestrator()
+	recorder := record.NewFakeRecorder(10)
+	r := &OrchestratorReconciler{Recorder: recorder}
+
+	r.resumeReconcile(orchestrator)
+	assert.Empty(t, recorder.Events, "Reconciliation was not paused")
+
+	meta.SetStatusCondition(&orchestrator.Status.Conditions, metav1.Condition{
+		Type: TypePaused, Status: metav1.ConditionTrue, Reason: "PauseRequested"})
+	r.resumeReconcile(orchestrator)
+	assert.Nil(t, meta.FindStatusCondition(orchestrator.Status.Conditions, TypePaused))
+	assert.Contains(t, <-recorder.Events, "Normal Resumed")
+}
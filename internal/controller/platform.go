@@ -0,0 +1,141 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	configv1 "github.com/openshift/api/config/v1"
+	orchestratorv1alpha2 "github.com/rhdhorchestrator/orchestrator-operator/api/v1alpha3"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	// Platforms reported in the Orchestrator status.
+	PlatformOpenShift  = "OpenShift"
+	PlatformKubernetes = "Kubernetes"
+
+	// TypePlatformCapabilities reports the OpenShift capabilities skipped on the other platforms.
+	TypePlatformCapabilities  string = "PlatformCapabilities"
+	ReasonCapabilitiesSkipped        = "CapabilitiesSkipped"
+)
+
+// openShiftIngressGVK is only served by OpenShift, which provides the cluster domain with it.
+var openShiftIngressGVK = configv1.GroupVersion.WithKind("Ingress")
+
+// clusterPlatform describes the platform the operator runs on, detected from the APIs served by the cluster.
+type clusterPlatform struct {
+	openShift bool
+}
+
+func (p clusterPlatform) name() string {
+	if p.openShift {
+		return PlatformOpenShift
+	}
+	return PlatformKubernetes
+}
+
+// detectPlatform detects OpenShift from its ingress configuration API.
+// The discovery is cached by the REST mapper of the client.
+func (r *OrchestratorReconciler) detectPlatform() (clusterPlatform, error) {
+	openShift, err := isServed(r.RESTMapper(), openShiftIngressGVK)
+	return clusterPlatform{openShift: openShift}, err
+}
+
+// getSkippedCapabilities lists the OpenShift capabilities used by the spec, which are skipped on the platform.
+func getSkippedCapabilities(platform clusterPlatform, spec orchestratorv1alpha2.OrchestratorSpec) []string {
+	if platform.openShift {
+		return nil
+	}
+	skipped := []string{"cluster domain discovery from the OpenShift ingress configuration"}
+	if spec.RHDHConfig.InstallOperator {
+		if spec.RHDHConfig.Ingress.Type == orchestratorv1alpha2.RHDHIngressTypeNone {
+			skipped = append(skipped, "RHDH Route")
+		} else {
+			skipped = append(skipped, fmt.Sprintf("RHDH Route, replaced by an %s", rhdhIngressType(spec.RHDHConfig)))
+		}
+	}
+	if spec.ArgoCd.Enabled && spec.Tekton.Enabled {
+		skipped = append(skipped, "buildah Task of openshift-pipelines, resolved from Tekton Hub instead")
+	}
+	if spec.PlatformConfig.Monitoring.Enabled {
+		skipped = append(skipped, fmt.Sprintf("%s network policy for OpenShift user workload monitoring",
+			allowMonitoringToSonataflowWorkflows))
+	}
+	return skipped
+}
+
+func rhdhIngressType(rhdhConfig orchestratorv1alpha2.RHDHConfig) orchestratorv1alpha2.RHDHIngressType {
+	if rhdhConfig.Ingress.Type == "" {
+		return orchestratorv1alpha2.RHDHIngressTypeIngress
+	}
+	return rhdhConfig.Ingress.Type
+}
+
+// setPlatformStatus reports the detected platform and the skipped capabilities in the Orchestrator status.
+func setPlatformStatus(orchestrator *orchestratorv1alpha2.Orchestrator, platform clusterPlatform) {
+	orchestrator.Status.Platform = platform.name()
+	condition := metav1.Condition{
+		Type:               TypePlatformCapabilities,
+		Status:             metav1.ConditionTrue,
+		Reason:             platform.name(),
+		Message:            "All the capabilities used by the Orchestrator are available",
+		ObservedGeneration: orchestrator.Generation,
+	}
+	if skipped := getSkippedCapabilities(platform, orchestrator.Spec); len(skipped) > 0 {
+		condition.Status = metav1.ConditionFalse
+		condition.Reason = ReasonCapabilitiesSkipped
+		condition.Message = fmt.Sprintf("Skipped the OpenShift capabilities on %s: %s",
+			platform.name(), strings.Join(skipped, "; "))
+	}
+	meta.SetStatusCondition(&orchestrator.Status.Conditions, condition)
+}
+
+// getRHDHBaseURL returns the external URL of RHDH, computed from the cluster domain unless set by the spec.
+func (r *OrchestratorReconciler) getRHDHBaseURL(
+	ctx context.Context, orchestrator *orchestratorv1alpha2.Orchestrator, platform clusterPlatform) (string, error) {
+	rhdhConfig := orchestrator.Spec.RHDHConfig
+	if rhdhConfig.BaseURL != "" {
+		return strings.TrimSuffix(rhdhConfig.BaseURL, "/"), nil
+	}
+	clusterDomain := orchestrator.Spec.PlatformConfig.ClusterDomain
+	if clusterDomain == "" {
+		if !platform.openShift {
+			return "", fmt.Errorf("spec.platform.clusterDomain or spec.rhdh.baseUrl must be set on %s", platform.name())
+		}
+		var err error
+		if clusterDomain, err = r.getClusterDomain(ctx); err != nil {
+			return "", err
+		}
+	}
+	return fmt.Sprintf("https://backstage-%s-%s.%s", rhdhConfig.Name, rhdhConfig.Namespace, clusterDomain), nil
+}
+
+// isServed reports whether the cluster serves the type.
+func isServed(mapper meta.RESTMapper, gvk schema.GroupVersionKind) (bool, error) {
+	if _, err := mapper.RESTMapping(gvk.GroupKind(), gvk.Version); err != nil {
+		if meta.IsNoMatchError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
@@ -0,0 +1,144 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	configv1 "github.com/openshift/api/config/v1"
+	orchestratorv1alpha2 "github.com/rhdhorchestrator/orchestrator-operator/api/v1alpha3"
+	"github.com/stretchr/testify/assert"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newTestOpenShiftRESTMapper() meta.RESTMapper {
+	mapper := meta.NewDefaultRESTMapper(nil)
+	mapper.Add(openShiftIngressGVK, meta.RESTScopeRoot)
+	return mapper
+}
+
+func TestDetectPlatform(t *testing.T) {
+	r := &OrchestratorReconciler{Client: fake.NewClientBuilder().WithRESTMapper(newTestOpenShiftRESTMapper()).Build()}
+	platform, err := r.detectPlatform()
+	assert.NoError(t, err)
+	assert.Equal(t, PlatformOpenShift, platform.name())
+
+	r = &OrchestratorReconciler{Client: fake.NewClientBuilder().Build()}
+	platform, err = r.detectPlatform()
+	assert.NoError(t, err)
+	assert.Equal(t, PlatformKubernetes, platform.name())
+}
+
+func TestSetPlatformStatus(t *testing.T) {
+	testCases := []struct {
+		name            string
+		platform        clusterPlatform
+		ingressType     orchestratorv1alpha2.RHDHIngressType
+		expectedStatus  metav1.ConditionStatus
+		expectedReason  string
+		expectedMessage []string
+	}{
+		{
+			name:           "OpenShift",
+			platform:       clusterPlatform{openShift: true},
+			expectedStatus: metav1.ConditionTrue,
+			expectedReason: PlatformOpenShift,
+		},
+		{
+			name:           "Kubernetes with an Ingress",
+			platform:       clusterPlatform{},
+			expectedStatus: metav1.ConditionFalse,
+			expectedReason: ReasonCapabilitiesSkipped,
+			expectedMessage: []string{"Skipped the OpenShift capabilities on Kubernetes: ",
+				"RHDH Route, replaced by an Ingress", "network policy for OpenShift user workload monitoring"},
+		},
+		{
+			name:            "Kubernetes without exposure",
+			platform:        clusterPlatform{},
+			ingressType:     orchestratorv1alpha2.RHDHIngressTypeNone,
+			expectedStatus:  metav1.ConditionFalse,
+			expectedReason:  ReasonCapabilitiesSkipped,
+			expectedMessage: []string{"RHDH Route;"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			orchestrator := newTestOrchestrator()
+			orchestrator.Spec.RHDHConfig.InstallOperator = true
+			orchestrator.Spec.RHDHConfig.Ingress.Type = tc.ingressType
+			orchestrator.Spec.PlatformConfig.Monitoring.Enabled = true
+
+			setPlatformStatus(orchestrator, tc.platform)
+			assert.Equal(t, tc.platform.name(), orchestrator.Status.Platform)
+			condition := meta.FindStatusCondition(orchestrator.Status.Conditions, TypePlatformCapabilities)
+			assert.NotNil(t, condition)
+			assert.Equal(t, tc.expectedStatus, condition.Status)
+			assert.Equal(t, tc.expectedReason, condition.Reason)
+			for _, message := range tc.expectedMessage {
+				assert.Contains(t, condition.Message, message)
+			}
+		})
+	}
+}
+
+func TestGetRHDHBaseURL(t *testing.T) {
+	ctx := context.TODO()
+	scheme := runtime.NewScheme()
+	utilruntime.Must(configv1.AddToScheme(scheme))
+	clusterIngress := &configv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster"},
+		Spec:       configv1.IngressSpec{Domain: "apps.openshift.example.com"},
+	}
+
+	testCases := []struct {
+		name          string
+		platform      clusterPlatform
+		baseURL       string
+		clusterDomain string
+		expectedURL   string
+		expectedError string
+	}{
+		{
+			name:        "Base URL of the spec",
+			baseURL:     "https://rhdh.example.com/",
+			expectedURL: "https://rhdh.example.com",
+		},
+		{
+			name:          "Cluster domain of the spec",
+			clusterDomain: "apps.example.com",
+			expectedURL:   "https://backstage-rhdh-rhdh-ns.apps.example.com",
+		},
+		{
+			name:        "Cluster domain of OpenShift",
+			platform:    clusterPlatform{openShift: true},
+			expectedURL: "https://backstage-rhdh-rhdh-ns.apps.openshift.example.com",
+		},
+		{
+			name:          "No cluster domain on Kubernetes",
+			expectedError: "spec.platform.clusterDomain or spec.rhdh.baseUrl must be set on Kubernetes",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			orchestrator := newTestOrchestrator()
+			orchestrator.Spec.RHDHConfig.Name = "rhdh"
+			orchestrator.Spec.RHDHConfig.Namespace = "rhdh-ns"
+			orchestrator.Spec.RHDHConfig.BaseURL = tc.baseURL
+			orchestrator.Spec.PlatformConfig.ClusterDomain = tc.clusterDomain
+			r := &OrchestratorReconciler{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(clusterIngress).Build()}
+
+			baseURL, err := r.getRHDHBaseURL(ctx, orchestrator, tc.platform)
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedURL, baseURL)
+		})
+	}
+}
@@ -0,0 +1,344 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+
+	orchestratorv1alpha2 "github.com/rhdhorchestrator/orchestrator-operator/api/v1alpha3"
+	"github.com/rhdhorchestrator/orchestrator-operator/internal/controller/kube"
+	"github.com/rhdhorchestrator/orchestrator-operator/internal/controller/util"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	postgresDefaultImage       = "registry.redhat.io/rhel9/postgresql-15:latest"
+	postgresDefaultStorageSize = "1Gi"
+	postgresDefaultUser        = "sonataflow"
+	postgresPort               = 5432
+	postgresContainerName      = "postgresql"
+	postgresDataVolumeName     = "data"
+	postgresDataMountPath      = "/var/lib/pgsql/data"
+	postgresAppLabel           = "app.kubernetes.io/instance"
+)
+
+// handlePostgres deploys the PostgreSQL instance configured with provision mode.
+// It returns a not ready error until the instance accepts connections.
+func handlePostgres(
+	ctx context.Context, k8client client.Client, recorder record.EventRecorder,
+	orchestrator *orchestratorv1alpha2.Orchestrator) error {
+	logger := log.FromContext(ctx)
+
+	postgresConfig := orchestrator.Spec.PostgresConfig
+	if !postgresConfig.Provision {
+		return nil
+	}
+	logger.Info("Handling provisioned PostgreSQL", "Name", postgresConfig.Name, "NS", postgresConfig.Namespace)
+
+	if _, err := kube.CheckNamespaceExist(ctx, k8client, postgresConfig.Namespace); err != nil {
+		if !apierrors.IsNotFound(err) {
+			logger.Error(err, "Error occurred when checking namespace exist", "NS", postgresConfig.Namespace)
+			return err
+		}
+		if err := kube.CreateNamespace(ctx, k8client, postgresConfig.Namespace); err != nil {
+			logger.Error(err, "Error occurred when creating namespace", "NS", postgresConfig.Namespace)
+			return err
+		}
+	}
+
+	if err := handlePostgresSecret(ctx, k8client, postgresConfig); err != nil {
+		logger.Error(err, "Error occurred when handling PostgreSQL secret", "Secret", postgresConfig.AuthSecret.SecretName)
+		return err
+	}
+
+	desiredService := getPostgresService(postgresConfig)
+	if err := k8client.Get(ctx, client.ObjectKeyFromObject(desiredService), &corev1.Service{}); err != nil {
+		if !apierrors.IsNotFound(err) {
+			logger.Error(err, "Error occurred when retrieving PostgreSQL service", "Service", desiredService.Name)
+			return err
+		}
+		if err := k8client.Create(ctx, desiredService); err != nil {
+			logger.Error(err, "Error occurred when creating PostgreSQL service", "Service", desiredService.Name)
+			return err
+		}
+		logger.Info("Successfully created PostgreSQL service", "Service", desiredService.Name)
+	}
+
+	desiredStatefulSet, err := getPostgresStatefulSet(postgresConfig)
+	if err != nil {
+		logger.Error(err, "Error occurred when computing PostgreSQL StatefulSet", "StatefulSet", postgresConfig.Name)
+		return err
+	}
+	existingStatefulSet := &appsv1.StatefulSet{}
+	if err := k8client.Get(ctx, client.ObjectKeyFromObject(desiredStatefulSet), existingStatefulSet); err != nil {
+		if !apierrors.IsNotFound(err) {
+			logger.Error(err, "Error occurred when retrieving PostgreSQL StatefulSet", "StatefulSet", desiredStatefulSet.Name)
+			return err
+		}
+		if err := k8client.Create(ctx, desiredStatefulSet); err != nil {
+			logger.Error(err, "Error occurred when creating PostgreSQL StatefulSet", "StatefulSet", desiredStatefulSet.Name)
+			return err
+		}
+		logger.Info("Successfully created PostgreSQL StatefulSet", "StatefulSet", desiredStatefulSet.Name)
+		recorder.Eventf(orchestrator, corev1.EventTypeNormal, "PostgresProvisioned",
+			"Created PostgreSQL StatefulSet %s/%s", desiredStatefulSet.Namespace, desiredStatefulSet.Name)
+		return &kube.NotReadyError{Kind: "StatefulSet", Name: desiredStatefulSet.Name}
+	}
+
+	// the volume claim templates are immutable; only the image follows the spec
+	if existingImage := existingStatefulSet.Spec.Template.Spec.Containers[0].Image; existingImage != getPostgresImage(postgresConfig) {
+		original := existingStatefulSet.DeepCopy()
+		existingStatefulSet.Spec.Template.Spec.Containers[0].Image = getPostgresImage(postgresConfig)
+		if err := k8client.Patch(ctx, existingStatefulSet, client.MergeFrom(original)); err != nil {
+			logger.Error(err, "Error occurred when updating PostgreSQL StatefulSet", "StatefulSet", existingStatefulSet.Name)
+			return err
+		}
+		logger.Info("Successfully updated PostgreSQL StatefulSet", "StatefulSet", existingStatefulSet.Name)
+	}
+
+	if existingStatefulSet.Status.ReadyReplicas < 1 {
+		logger.Info("PostgreSQL StatefulSet is not ready", "StatefulSet", existingStatefulSet.Name)
+		return &kube.NotReadyError{Kind: "StatefulSet", Name: existingStatefulSet.Name}
+	}
+	return nil
+}
+
+// handlePostgresSecret creates the auth secret, or adds the missing credentials to an existing one.
+func handlePostgresSecret(ctx context.Context, k8client client.Client, postgresConfig orchestratorv1alpha2.PostgresConfig) error {
+	authSecret := postgresConfig.AuthSecret
+	secret := &corev1.Secret{}
+	if err := k8client.Get(ctx, types.NamespacedName{Name: authSecret.SecretName, Namespace: postgresConfig.Namespace}, secret); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+		password, err := generatePassword()
+		if err != nil {
+			return err
+		}
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      authSecret.SecretName,
+				Namespace: postgresConfig.Namespace,
+				Labels:    kube.AddLabel(),
+			},
+			Data: map[string][]byte{
+				authSecret.UserKey:     []byte(postgresDefaultUser),
+				authSecret.PasswordKey: []byte(password),
+			},
+		}
+		return k8client.Create(ctx, secret)
+	}
+
+	if len(secret.Data[authSecret.UserKey]) > 0 && len(secret.Data[authSecret.PasswordKey]) > 0 {
+		return nil
+	}
+	original := secret.DeepCopy()
+	if secret.Data == nil {
+		secret.Data = map[string][]byte{}
+	}
+	if len(secret.Data[authSecret.UserKey]) == 0 {
+		secret.Data[authSecret.UserKey] = []byte(postgresDefaultUser)
+	}
+	if len(secret.Data[authSecret.PasswordKey]) == 0 {
+		password, err := generatePassword()
+		if err != nil {
+			return err
+		}
+		secret.Data[authSecret.PasswordKey] = []byte(password)
+	}
+	return k8client.Patch(ctx, secret, client.MergeFrom(original))
+}
+
+func generatePassword() (string, error) {
+	randomBytes := make([]byte, 16)
+	if _, err := rand.Read(randomBytes); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(randomBytes), nil
+}
+
+func getPostgresImage(postgresConfig orchestratorv1alpha2.PostgresConfig) string {
+	if postgresConfig.Image == "" {
+		return postgresDefaultImage
+	}
+	return postgresConfig.Image
+}
+
+func getPostgresSelector(postgresConfig orchestratorv1alpha2.PostgresConfig) map[string]string {
+	return map[string]string{postgresAppLabel: postgresConfig.Name}
+}
+
+func getPostgresLabels(postgresConfig orchestratorv1alpha2.PostgresConfig) map[string]string {
+	labels := kube.AddLabel()
+	for key, value := range getPostgresSelector(postgresConfig) {
+		labels[key] = value
+	}
+	return labels
+}
+
+func getPostgresService(postgresConfig orchestratorv1alpha2.PostgresConfig) *corev1.Service {
+	return &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      postgresConfig.Name,
+			Namespace: postgresConfig.Namespace,
+			Labels:    getPostgresLabels(postgresConfig),
+		},
+		Spec: corev1.ServiceSpec{
+			Selector: getPostgresSelector(postgresConfig),
+			Ports: []corev1.ServicePort{
+				{
+					Name:       postgresContainerName,
+					Port:       postgresPort,
+					TargetPort: intstr.FromInt32(postgresPort),
+				},
+			},
+		},
+	}
+}
+
+func getPostgresStatefulSet(postgresConfig orchestratorv1alpha2.PostgresConfig) (*appsv1.StatefulSet, error) {
+	storageSize := postgresConfig.StorageSize
+	if storageSize == "" {
+		storageSize = postgresDefaultStorageSize
+	}
+	storageQuantity, err := resource.ParseQuantity(storageSize)
+	if err != nil {
+		return nil, fmt.Errorf("invalid PostgreSQL storage size %q: %w", storageSize, err)
+	}
+	volumeClaim := corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:   postgresDataVolumeName,
+			Labels: getPostgresLabels(postgresConfig),
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes: []corev1.PersistentVolumeAccessMode{corev1.ReadWriteOnce},
+			Resources: corev1.VolumeResourceRequirements{
+				Requests: corev1.ResourceList{corev1.ResourceStorage: storageQuantity},
+			},
+		},
+	}
+	if postgresConfig.StorageClassName != "" {
+		volumeClaim.Spec.StorageClassName = util.MakePointer(postgresConfig.StorageClassName)
+	}
+
+	secretKeyRef := func(key string) *corev1.EnvVarSource {
+		return &corev1.EnvVarSource{
+			SecretKeyRef: &corev1.SecretKeySelector{
+				LocalObjectReference: corev1.LocalObjectReference{Name: postgresConfig.AuthSecret.SecretName},
+				Key:                  key,
+			},
+		}
+	}
+
+	return &appsv1.StatefulSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      postgresConfig.Name,
+			Namespace: postgresConfig.Namespace,
+			Labels:    getPostgresLabels(postgresConfig),
+		},
+		Spec: appsv1.StatefulSetSpec{
+			Replicas:    util.MakePointer(int32(1)),
+			ServiceName: postgresConfig.Name,
+			Selector:    &metav1.LabelSelector{MatchLabels: getPostgresSelector(postgresConfig)},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: getPostgresLabels(postgresConfig)},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name:  postgresContainerName,
+							Image: getPostgresImage(postgresConfig),
+							Ports: []corev1.ContainerPort{{Name: postgresContainerName, ContainerPort: postgresPort}},
+							Env: []corev1.EnvVar{
+								{Name: "POSTGRESQL_USER", ValueFrom: secretKeyRef(postgresConfig.AuthSecret.UserKey)},
+								{Name: "POSTGRESQL_PASSWORD", ValueFrom: secretKeyRef(postgresConfig.AuthSecret.PasswordKey)},
+								{Name: "POSTGRESQL_DATABASE", Value: postgresConfig.DatabaseName},
+							},
+							ReadinessProbe: &corev1.Probe{
+								ProbeHandler: corev1.ProbeHandler{
+									Exec: &corev1.ExecAction{Command: []string{"/usr/libexec/check-container"}},
+								},
+								InitialDelaySeconds: 5,
+								TimeoutSeconds:      1,
+							},
+							VolumeMounts: []corev1.VolumeMount{{Name: postgresDataVolumeName, MountPath: postgresDataMountPath}},
+						},
+					},
+				},
+			},
+			VolumeClaimTemplates: []corev1.PersistentVolumeClaim{volumeClaim},
+		},
+	}, nil
+}
+
+// isRetainedPostgresEntry reports whether the inventory entry holds the data of the provisioned PostgreSQL
+// which is kept by the Retain policy: the generated secret and the namespace of the data volumes.
+func isRetainedPostgresEntry(postgresConfig orchestratorv1alpha2.PostgresConfig, entry orchestratorv1alpha2.InventoryEntry) bool {
+	if !postgresConfig.Provision || postgresConfig.RetentionPolicy == orchestratorv1alpha2.PostgresDeletePolicy {
+		return false
+	}
+	switch entry.Kind {
+	case "Secret":
+		return entry.Namespace == postgresConfig.Namespace && entry.Name == postgresConfig.AuthSecret.SecretName
+	case "Namespace":
+		return entry.Name == postgresConfig.Namespace
+	}
+	return false
+}
+
+// handlePostgresCleanUp deletes the data volumes and the generated secret of the provisioned PostgreSQL, for the
+// Delete retention policy. The StatefulSet and its Service are deleted with the inventory.
+func handlePostgresCleanUp(ctx context.Context, k8client client.Client, postgresConfig orchestratorv1alpha2.PostgresConfig) error {
+	logger := log.FromContext(ctx)
+	volumeClaims := &corev1.PersistentVolumeClaimList{}
+	if err := k8client.List(ctx, volumeClaims, client.InNamespace(postgresConfig.Namespace),
+		client.MatchingLabels(getPostgresLabels(postgresConfig))); err != nil {
+		return err
+	}
+	for i := range volumeClaims.Items {
+		if err := k8client.Delete(ctx, &volumeClaims.Items[i]); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+
+	secret := &corev1.Secret{}
+	if err := k8client.Get(ctx, types.NamespacedName{Name: postgresConfig.AuthSecret.SecretName, Namespace: postgresConfig.Namespace}, secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	// a secret provided by the user is never deleted
+	if kube.CheckLabelExist(secret.Labels) {
+		if err := k8client.Delete(ctx, secret); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+	logger.Info("Successfully deleted PostgreSQL data volume", "Name", postgresConfig.Name)
+	return nil
+}
@@ -0,0 +1,189 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	orchestratorv1alpha2 "github.com/rhdhorchestrator/orchestrator-operator/api/v1alpha3"
+	"github.com/rhdhorchestrator/orchestrator-operator/internal/controller/kube"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newTestPostgresOrchestrator() *orchestratorv1alpha2.Orchestrator {
+	orchestrator := newTestSonataFlowOrchestrator()
+	orchestrator.Spec.PostgresConfig.Provision = true
+	return orchestrator
+}
+
+func TestHandlePostgres(t *testing.T) {
+	ctx := context.TODO()
+	scheme := runtime.NewScheme()
+	utilruntime.Must(corev1.AddToScheme(scheme))
+	utilruntime.Must(appsv1.AddToScheme(scheme))
+
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: testNamespace, Labels: kube.AddLabel()}}
+	postgresConfig := newTestPostgresOrchestrator().Spec.PostgresConfig
+	readyStatefulSet, err := getPostgresStatefulSet(postgresConfig)
+	assert.NoError(t, err)
+	readyStatefulSet.Status.ReadyReplicas = 1
+	userSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: postgresConfig.AuthSecret.SecretName, Namespace: testNamespace},
+		Data:       map[string][]byte{postgresConfig.AuthSecret.UserKey: []byte("admin")},
+	}
+
+	testCases := []struct {
+		name         string
+		existing     []client.Object
+		notReady     bool
+		expectedUser string
+	}{
+		{
+			name:         "Missing instance is provisioned",
+			notReady:     true,
+			expectedUser: postgresDefaultUser,
+		},
+		{
+			name:         "Existing secret is completed",
+			existing:     []client.Object{userSecret},
+			notReady:     true,
+			expectedUser: "admin",
+		},
+		{
+			name:         "Ready instance is reconciled",
+			existing:     []client.Object{readyStatefulSet},
+			expectedUser: postgresDefaultUser,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			objects := []client.Object{namespace.DeepCopy()}
+			for _, object := range tc.existing {
+				objects = append(objects, object.DeepCopyObject().(client.Object))
+			}
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(objects...).Build()
+			recorder := record.NewFakeRecorder(10)
+
+			err := handlePostgres(ctx, fakeClient, recorder, newTestPostgresOrchestrator())
+			if tc.notReady {
+				assert.True(t, kube.IsNotReady(err))
+			} else {
+				assert.NoError(t, err)
+			}
+
+			key := types.NamespacedName{Name: postgresConfig.Name, Namespace: testNamespace}
+			assert.NoError(t, fakeClient.Get(ctx, key, &corev1.Service{}))
+			statefulSet := &appsv1.StatefulSet{}
+			assert.NoError(t, fakeClient.Get(ctx, key, statefulSet))
+			assert.Equal(t, postgresDefaultImage, statefulSet.Spec.Template.Spec.Containers[0].Image)
+
+			secret := &corev1.Secret{}
+			assert.NoError(t, fakeClient.Get(ctx,
+				types.NamespacedName{Name: postgresConfig.AuthSecret.SecretName, Namespace: testNamespace}, secret))
+			assert.Equal(t, tc.expectedUser, string(secret.Data[postgresConfig.AuthSecret.UserKey]))
+			assert.Len(t, secret.Data[postgresConfig.AuthSecret.PasswordKey], 32)
+		})
+	}
+}
+
+func TestHandlePostgresCleanUp(t *testing.T) {
+	ctx := context.TODO()
+	scheme := runtime.NewScheme()
+	utilruntime.Must(corev1.AddToScheme(scheme))
+	utilruntime.Must(appsv1.AddToScheme(scheme))
+
+	postgresConfig := newTestPostgresOrchestrator().Spec.PostgresConfig
+	volumeClaim := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      postgresDataVolumeName + "-" + postgresConfig.Name + "-0",
+			Namespace: testNamespace,
+			Labels:    getPostgresLabels(postgresConfig),
+		},
+	}
+	otherVolumeClaim := &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      postgresDataVolumeName + "-other-postgres-0",
+			Namespace: testNamespace,
+			Labels:    map[string]string{postgresAppLabel: "other-postgres"},
+		},
+	}
+	newSecret := func(labels map[string]string) *corev1.Secret {
+		return &corev1.Secret{ObjectMeta: metav1.ObjectMeta{
+			Name: postgresConfig.AuthSecret.SecretName, Namespace: testNamespace, Labels: labels}}
+	}
+
+	testCases := []struct {
+		name                string
+		secret              *corev1.Secret
+		expectSecretDeleted bool
+	}{
+		{
+			name:                "Generated secret is deleted with the data volume",
+			secret:              newSecret(kube.AddLabel()),
+			expectSecretDeleted: true,
+		},
+		{
+			name:                "Secret provided by the user is kept",
+			secret:              newSecret(nil),
+			expectSecretDeleted: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+				volumeClaim.DeepCopy(), otherVolumeClaim.DeepCopy(), tc.secret).Build()
+
+			assert.NoError(t, handlePostgresCleanUp(ctx, fakeClient, postgresConfig))
+
+			err := fakeClient.Get(ctx, client.ObjectKeyFromObject(volumeClaim), &corev1.PersistentVolumeClaim{})
+			assert.True(t, apierrors.IsNotFound(err))
+			err = fakeClient.Get(ctx, client.ObjectKeyFromObject(otherVolumeClaim), &corev1.PersistentVolumeClaim{})
+			assert.NoError(t, err)
+			err = fakeClient.Get(ctx, client.ObjectKeyFromObject(tc.secret), &corev1.Secret{})
+			assert.Equal(t, tc.expectSecretDeleted, apierrors.IsNotFound(err))
+		})
+	}
+}
+
+func TestGetPostgresStatefulSetStorageSize(t *testing.T) {
+	postgresConfig := newTestPostgresOrchestrator().Spec.PostgresConfig
+	postgresConfig.StorageSize = "5Gi"
+	statefulSet, err := getPostgresStatefulSet(postgresConfig)
+	assert.NoError(t, err)
+	assert.Equal(t, resource.MustParse("5Gi"),
+		statefulSet.Spec.VolumeClaimTemplates[0].Spec.Resources.Requests[corev1.ResourceStorage])
+
+	postgresConfig.StorageSize = "five gigabytes"
+	_, err = getPostgresStatefulSet(postgresConfig)
+	assert.ErrorContains(t, err, "invalid PostgreSQL storage size")
+}
+
+func TestIsRetainedPostgresEntry(t *testing.T) {
+	postgresConfig := newTestPostgresOrchestrator().Spec.PostgresConfig
+	secret := orchestratorv1alpha2.InventoryEntry{
+		APIVersion: "v1", Kind: "Secret", Namespace: postgresConfig.Namespace, Name: postgresConfig.AuthSecret.SecretName}
+	namespace := orchestratorv1alpha2.InventoryEntry{APIVersion: "v1", Kind: "Namespace", Name: postgresConfig.Namespace}
+	statefulSet := orchestratorv1alpha2.InventoryEntry{
+		APIVersion: "apps/v1", Kind: "StatefulSet", Namespace: postgresConfig.Namespace, Name: postgresConfig.Name}
+
+	postgresConfig.RetentionPolicy = orchestratorv1alpha2.PostgresRetainPolicy
+	assert.True(t, isRetainedPostgresEntry(postgresConfig, secret))
+	assert.True(t, isRetainedPostgresEntry(postgresConfig, namespace))
+	assert.False(t, isRetainedPostgresEntry(postgresConfig, statefulSet))
+
+	postgresConfig.RetentionPolicy = orchestratorv1alpha2.PostgresDeletePolicy
+	assert.False(t, isRetainedPostgresEntry(postgresConfig, secret))
+	assert.False(t, isRetainedPostgresEntry(postgresConfig, namespace))
+}
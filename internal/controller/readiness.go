@@ -0,0 +1,92 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	sonataapi "github.com/apache/incubator-kie-tools/packages/sonataflow-operator/api/v1alpha08"
+	"github.com/rhdhorchestrator/orchestrator-operator/internal/controller/kube"
+	"k8s.io/apimachinery/pkg/types"
+	knative "knative.dev/operator/pkg/apis/operator/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+const (
+	// dataIndexDeploymentSuffix and jobServiceDeploymentSuffix name the deployments of the platform services,
+	// prefixed by the name of the SonataFlowPlatform
+	dataIndexDeploymentSuffix  = "-data-index-service"
+	jobServiceDeploymentSuffix = "-jobs-service"
+)
+
+// checkKnativeReady returns a NotReadyError until the KnativeEventing and KnativeServing CRs are ready.
+func checkKnativeReady(ctx context.Context, k8client client.Client) error {
+	knativeEventing := &knative.KnativeEventing{}
+	if err := k8client.Get(ctx, types.NamespacedName{
+		Name: knativeEventingNamespacedName, Namespace: knativeEventingNamespacedName}, knativeEventing); err != nil {
+		return err
+	}
+	if !knativeEventing.Status.IsReady() {
+		return &kube.NotReadyError{Kind: knativeEventingKind,
+			Name: kube.FormatObjectName(knativeEventingNamespacedName, knativeEventingNamespacedName)}
+	}
+
+	knativeServing := &knative.KnativeServing{}
+	if err := k8client.Get(ctx, types.NamespacedName{
+		Name: knativeServingNamespacedName, Namespace: knativeServingNamespacedName}, knativeServing); err != nil {
+		return err
+	}
+	if !knativeServing.Status.IsReady() {
+		return &kube.NotReadyError{Kind: knativeServingKind,
+			Name: kube.FormatObjectName(knativeServingNamespacedName, knativeServingNamespacedName)}
+	}
+	return nil
+}
+
+// checkSonataFlowPlatformReady returns a NotReadyError until the SonataFlowPlatform of namespace has succeeded
+// and the deployments of its enabled Data Index and Job Service are available.
+func checkSonataFlowPlatformReady(ctx context.Context, k8client client.Client, namespace string) error {
+	platform := &sonataapi.SonataFlowPlatform{}
+	if err := k8client.Get(ctx, types.NamespacedName{Name: sonataFlowPlatformCRName, Namespace: namespace}, platform); err != nil {
+		return err
+	}
+	if !platform.Status.IsReady() {
+		notReady := &kube.NotReadyError{Kind: sonataFlowPlatformKind, Name: kube.FormatObjectName(namespace, platform.Name)}
+		if condition := platform.Status.GetTopLevelCondition(); condition != nil && condition.Reason != "" {
+			notReady.Reason = condition.Reason
+		}
+		return notReady
+	}
+
+	if services := platform.Spec.Services; services != nil {
+		if services.DataIndex != nil && isServiceEnabled(services.DataIndex.ServiceSpec) {
+			if err := kube.CheckDeploymentAvailable(ctx, k8client, namespace, platform.Name+dataIndexDeploymentSuffix); err != nil {
+				return err
+			}
+		}
+		if services.JobService != nil && isServiceEnabled(services.JobService.ServiceSpec) {
+			if err := kube.CheckDeploymentAvailable(ctx, k8client, namespace, platform.Name+jobServiceDeploymentSuffix); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func isServiceEnabled(service sonataapi.ServiceSpec) bool {
+	return service.Enabled != nil && *service.Enabled
+}
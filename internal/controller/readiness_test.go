@@ -0,0 +1,153 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	sonataapi "github.com/apache/incubator-kie-tools/packages/sonataflow-operator/api"
+	sonataapiv1alpha08 "github.com/apache/incubator-kie-tools/packages/sonataflow-operator/api/v1alpha08"
+	"github.com/rhdhorchestrator/orchestrator-operator/internal/controller/kube"
+	"github.com/stretchr/testify/assert"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/utils/ptr"
+	knative "knative.dev/operator/pkg/apis/operator/v1beta1"
+	"knative.dev/pkg/apis"
+	duckv1 "knative.dev/pkg/apis/duck/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newTestKnativeStatus(ready corev1.ConditionStatus) duckv1.Status {
+	return duckv1.Status{Conditions: duckv1.Conditions{{Type: apis.ConditionReady, Status: ready}}}
+}
+
+func newTestAvailableDeployment(namespace, name string) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace},
+		Status: appsv1.DeploymentStatus{
+			Conditions: []appsv1.DeploymentCondition{{Type: appsv1.DeploymentAvailable, Status: corev1.ConditionTrue}},
+		},
+	}
+}
+
+func TestCheckKnativeReady(t *testing.T) {
+	ctx := context.TODO()
+	scheme := runtime.NewScheme()
+	utilruntime.Must(knative.AddToScheme(scheme))
+
+	newEventing := func(ready corev1.ConditionStatus) *knative.KnativeEventing {
+		return &knative.KnativeEventing{
+			ObjectMeta: metav1.ObjectMeta{Name: knativeEventingNamespacedName, Namespace: knativeEventingNamespacedName},
+			Status:     knative.KnativeEventingStatus{Status: newTestKnativeStatus(ready)},
+		}
+	}
+	newServing := func(ready corev1.ConditionStatus) *knative.KnativeServing {
+		return &knative.KnativeServing{
+			ObjectMeta: metav1.ObjectMeta{Name: knativeServingNamespacedName, Namespace: knativeServingNamespacedName},
+			Status:     knative.KnativeServingStatus{Status: newTestKnativeStatus(ready)},
+		}
+	}
+
+	testCases := []struct {
+		name          string
+		objects       []client.Object
+		expectedError string
+	}{
+		{
+			name:          "KnativeEventing not found",
+			objects:       []client.Object{newServing(corev1.ConditionTrue)},
+			expectedError: `knativeeventings.operator.knative.dev "knative-eventing" not found`,
+		},
+		{
+			name:          "KnativeEventing not ready",
+			objects:       []client.Object{newEventing(corev1.ConditionFalse), newServing(corev1.ConditionTrue)},
+			expectedError: "KnativeEventing knative-eventing/knative-eventing is not ready",
+		},
+		{
+			name:          "KnativeServing not ready",
+			objects:       []client.Object{newEventing(corev1.ConditionTrue), newServing(corev1.ConditionUnknown)},
+			expectedError: "KnativeServing knative-serving/knative-serving is not ready",
+		},
+		{
+			name:    "Knative ready",
+			objects: []client.Object{newEventing(corev1.ConditionTrue), newServing(corev1.ConditionTrue)},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(tc.objects...).Build()
+			err := checkKnativeReady(ctx, fakeClient)
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.True(t, apierrors.IsNotFound(err) || kube.IsNotReady(err))
+			assert.EqualError(t, err, tc.expectedError)
+		})
+	}
+}
+
+func TestCheckSonataFlowPlatformReady(t *testing.T) {
+	ctx := context.TODO()
+	scheme := runtime.NewScheme()
+	utilruntime.Must(sonataapiv1alpha08.AddToScheme(scheme))
+	utilruntime.Must(appsv1.AddToScheme(scheme))
+
+	newPlatform := func(succeed corev1.ConditionStatus, reason string) *sonataapiv1alpha08.SonataFlowPlatform {
+		platform := &sonataapiv1alpha08.SonataFlowPlatform{
+			ObjectMeta: metav1.ObjectMeta{Name: sonataFlowPlatformCRName, Namespace: testNamespace},
+			Spec: sonataapiv1alpha08.SonataFlowPlatformSpec{
+				Services: &sonataapiv1alpha08.ServicesPlatformSpec{
+					DataIndex: &sonataapiv1alpha08.DataIndexServiceSpec{
+						ServiceSpec: sonataapiv1alpha08.ServiceSpec{Enabled: ptr.To(true)}},
+					JobService: &sonataapiv1alpha08.JobServiceServiceSpec{
+						ServiceSpec: sonataapiv1alpha08.ServiceSpec{Enabled: ptr.To(false)}},
+				},
+			},
+		}
+		platform.Status.Conditions = sonataapi.Conditions{
+			{Type: sonataapi.SucceedConditionType, Status: succeed, Reason: reason}}
+		return platform
+	}
+	dataIndex := newTestAvailableDeployment(testNamespace, sonataFlowPlatformCRName+dataIndexDeploymentSuffix)
+
+	testCases := []struct {
+		name          string
+		objects       []client.Object
+		expectedError string
+	}{
+		{
+			name:          "Platform not succeeded",
+			objects:       []client.Object{newPlatform(corev1.ConditionFalse, "Warming"), dataIndex},
+			expectedError: "SonataFlowPlatform " + testNamespace + "/sonataflow-platform is not ready: Warming",
+		},
+		{
+			name:          "Data Index not deployed",
+			objects:       []client.Object{newPlatform(corev1.ConditionTrue, "")},
+			expectedError: `deployments.apps "sonataflow-platform-data-index-service" not found`,
+		},
+		{
+			// the disabled Job Service is not awaited
+			name:    "Platform ready",
+			objects: []client.Object{newPlatform(corev1.ConditionTrue, ""), dataIndex},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(tc.objects...).Build()
+			err := checkSonataFlowPlatformReady(ctx, fakeClient, testNamespace)
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, tc.expectedError)
+		})
+	}
+}
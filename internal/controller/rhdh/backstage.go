@@ -2,19 +2,27 @@ package rhdh
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	olmclientset "github.com/operator-framework/operator-lifecycle-manager/pkg/api/client/clientset/versioned"
 	orchestratorv1alpha2 "github.com/rhdhorchestrator/orchestrator-operator/api/v1alpha3"
 	kubeoperations "github.com/rhdhorchestrator/orchestrator-operator/internal/controller/kube"
 	"github.com/rhdhorchestrator/orchestrator-operator/internal/controller/util"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	rhdhv1alpha3 "redhat-developer/red-hat-developer-hub-operator/api/v1alpha3"
 	"reflect"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"slices"
+	"sort"
 )
 
 const (
@@ -27,8 +35,20 @@ const (
 	rhdhSubscriptionChannel           = "fast-1.5"
 	rhdhOperatorNamespace             = "rhdh-operator"
 	rhdhSubscriptionStartingCSV       = "rhdh-operator.v1.5.1"
+	rhdhBackendContainerName          = "backstage-backend"
+	// rhdhRuntimeObjectPrefix prefixes the names of the deployment and service created by the RHDH operator
+	// for a Backstage CR
+	rhdhRuntimeObjectPrefix = "backstage-"
 )
 
+// RHDHOperatorDetection finds the RHDH operator installed without the Subscription of the Orchestrator.
+var RHDHOperatorDetection = kubeoperations.OperatorDetection{
+	Name:                  "RHDH",
+	CRDName:               rhdhCRDName,
+	SubscriptionName:      rhdhSubscriptionName,
+	SubscriptionNamespace: rhdhOperatorNamespace,
+}
+
 var ConfigMapNameAndConfigDataKey = map[string]string{
 	AppConfigRHDHName:              "app-config-rhdh.yaml",
 	AppConfigRHDHAuthName:          "app-config-auth.gh.yaml",
@@ -36,7 +56,10 @@ var ConfigMapNameAndConfigDataKey = map[string]string{
 	AppConfigRHDHDynamicPluginName: "dynamic-plugins.yaml",
 }
 
-func HandleRHDHOperatorInstallation(ctx context.Context, client client.Client, olmClientSet olmclientset.Interface) error {
+func HandleRHDHOperatorInstallation(
+	ctx context.Context, client *kubeoperations.InventoryClient, recorder record.EventRecorder,
+	orchestrator *orchestratorv1alpha2.Orchestrator, olmClientSet olmclientset.Interface,
+	subscriptionConfig orchestratorv1alpha2.SubscriptionConfig, installPlanPolicy kubeoperations.InstallPlanPolicy) error {
 	rhdhLogger := log.FromContext(ctx)
 
 	if _, err := kubeoperations.CheckNamespaceExist(ctx, client, rhdhOperatorNamespace); err != nil {
@@ -55,7 +78,8 @@ func HandleRHDHOperatorInstallation(ctx context.Context, client client.Client, o
 		rhdhSubscriptionName,
 		rhdhOperatorNamespace,
 		rhdhSubscriptionChannel,
-		rhdhSubscriptionStartingCSV)
+		rhdhSubscriptionStartingCSV,
+		subscriptionConfig)
 
 	// check if subscription exists
 	subscriptionExists, existingSubscription, err := kubeoperations.CheckSubscriptionExists(ctx, olmClientSet, rhdhSubscription)
@@ -72,6 +96,11 @@ func HandleRHDHOperatorInstallation(ctx context.Context, client client.Client, o
 		}
 		rhdhLogger.Info("Operator successfully installed", "SubscriptionName", rhdhSubscriptionName)
 	} else {
+		// the subscriptions created by earlier releases are not in the inventory yet
+		if err := client.Adopt(ctx, existingSubscription); err != nil {
+			rhdhLogger.Error(err, "Error occurred when adopting subscription", "SubscriptionName", rhdhSubscriptionName)
+			return err
+		}
 		// Compare the current and desired state
 		if !reflect.DeepEqual(existingSubscription.Spec, rhdhSubscription.Spec) {
 			// Update the existing subscription with the new Spec
@@ -81,21 +110,27 @@ func HandleRHDHOperatorInstallation(ctx context.Context, client client.Client, o
 				return err
 			}
 			rhdhLogger.Info("Successfully updated subscription spec", "SubscriptionName", rhdhSubscriptionName)
+			recorder.Eventf(orchestrator, corev1.EventTypeNormal, "SubscriptionUpdated",
+				"Updated Subscription %s/%s to match the Orchestrator spec", rhdhOperatorNamespace, rhdhSubscriptionName)
 		}
 	}
 
 	// approve install plan
-	if existingSubscription.Status.InstallPlanRef != nil && existingSubscription.Status.CurrentCSV == rhdhSubscriptionStartingCSV {
+	if existingSubscription.Status.InstallPlanRef != nil && existingSubscription.Status.CurrentCSV == rhdhSubscription.Spec.StartingCSV {
 		installPlanName := existingSubscription.Status.InstallPlanRef.Name
-		if err := kubeoperations.ApproveInstallPlan(client, ctx, installPlanName, existingSubscription.Namespace); err != nil {
+		if err := kubeoperations.ApproveInstallPlan(client, ctx, recorder, orchestrator, installPlanName, existingSubscription.Namespace, installPlanPolicy); err != nil {
 			rhdhLogger.Error(err, "Error occurred while approving install plan for subscription", "SubscriptionName", installPlanName)
 			return err
 		}
 	}
-	return nil
+
+	// the operands are reconciled once the operator is installed; until then the reconciliation is retried
+	return kubeoperations.CheckCSVSucceeded(ctx, olmClientSet, rhdhSubscription)
 }
 
-func CreateRHDHSecret(secretNamespace string, ctx context.Context, client client.Client) error {
+func CreateRHDHSecret(
+	secretNamespace string, disconnected orchestratorv1alpha2.DisconnectedConfig,
+	ctx context.Context, client client.Client) error {
 	logger := log.FromContext(ctx)
 	logger.Info("Creating RHDH NPMrc Secret")
 
@@ -116,7 +151,7 @@ func CreateRHDHSecret(secretNamespace string, ctx context.Context, client client
 				},
 				Type: corev1.SecretTypeOpaque,
 				StringData: map[string]string{
-					".npmrc": fmt.Sprintf("registry=%s", NpmRegistry),
+					".npmrc": getNpmrc(disconnected),
 				},
 			}
 
@@ -131,17 +166,27 @@ func CreateRHDHSecret(secretNamespace string, ctx context.Context, client client
 		return err
 	}
 	logger.Info("Secret already exist", "Secret", RegistrySecretName)
+
+	// point the existing secret to the configured registry
+	if string(secret.Data[".npmrc"]) != getNpmrc(disconnected) {
+		secret.StringData = map[string]string{".npmrc": getNpmrc(disconnected)}
+		if err := client.Update(ctx, secret); err != nil {
+			logger.Error(err, "Error occurred when updating secret", "Secret", RegistrySecretName)
+			return err
+		}
+		logger.Info("Successfully updated secret", "Secret", RegistrySecretName)
+	}
 	return nil
 }
 
 func HandleRHDHCR(
 	rhdhConfig orchestratorv1alpha2.RHDHConfig,
 	bsConfigMapList []rhdhv1alpha3.FileObjectRef,
-	ctx context.Context, client client.Client) error {
+	ctx context.Context, k8client client.Client) error {
 	rhdhLogger := log.FromContext(ctx)
 
 	// subscription exists; check if CRD exists for RHDH
-	if err := kubeoperations.CheckCRDExists(ctx, client, rhdhCRDName); err != nil {
+	if err := kubeoperations.CheckCRDExists(ctx, k8client, rhdhCRDName); err != nil {
 		if apierrors.IsNotFound(err) {
 			rhdhLogger.Info("CRD resource not found or ready", "CRD", rhdhCRDName)
 			return err
@@ -155,11 +200,15 @@ func HandleRHDHCR(
 	rhdhNamespace := rhdhConfig.Namespace
 	rhdhName := rhdhConfig.Name
 
-	if err := client.Get(ctx, types.NamespacedName{Namespace: rhdhNamespace, Name: rhdhName}, &rhdhv1alpha3.Backstage{}); err != nil {
+	desiredSpec, err := getBackstageSpec(rhdhConfig, bsConfigMapList)
+	if err != nil {
+		rhdhLogger.Error(err, "Error occurred when computing RHDH resource spec", "CR-Name", rhdhName)
+		return err
+	}
+
+	existingBackstageCR := &rhdhv1alpha3.Backstage{}
+	if err := k8client.Get(ctx, types.NamespacedName{Namespace: rhdhNamespace, Name: rhdhName}, existingBackstageCR); err != nil {
 		if apierrors.IsNotFound(err) {
-			secret := rhdhv1alpha3.EnvObjectRef{
-				Name: BackendAuthSecretName,
-			}
 			backstageCR := &rhdhv1alpha3.Backstage{
 				TypeMeta: metav1.TypeMeta{
 					APIVersion: rhdhAPIVersion,
@@ -170,19 +219,10 @@ func HandleRHDHCR(
 					Namespace: rhdhConfig.Namespace,
 					Labels:    kubeoperations.AddLabel(),
 				},
-				Spec: rhdhv1alpha3.BackstageSpec{
-					Application: &rhdhv1alpha3.Application{
-						AppConfig:                   &rhdhv1alpha3.AppConfig{ConfigMaps: bsConfigMapList},
-						DynamicPluginsConfigMapName: AppConfigRHDHDynamicPluginName,
-						ExtraEnvs: &rhdhv1alpha3.ExtraEnvs{
-							Secrets: []rhdhv1alpha3.EnvObjectRef{secret},
-						},
-						Replicas: util.MakePointer(rhdhReplica),
-					},
-				},
+				Spec: desiredSpec,
 			}
 			rhdhLogger.Info("Creating Backstage CR", "CR-Name", backstageCR.Name)
-			if err := client.Create(ctx, backstageCR); err != nil {
+			if err := k8client.Create(ctx, backstageCR); err != nil {
 				rhdhLogger.Error(err, "Error occurred when creating RHDH resource", "CR-Name", rhdhName)
 				return err
 			}
@@ -192,46 +232,271 @@ func HandleRHDHCR(
 		rhdhLogger.Error(err, "Error occurred when retrieving RHDH resource", "CR-Name", rhdhName)
 		return err
 	}
+
+	// patch the fields owned by the orchestrator; other fields of the CR are left to the user
+	originalBackstageCR := existingBackstageCR.DeepCopy()
+	if existingBackstageCR.Spec.Application == nil {
+		existingBackstageCR.Spec.Application = &rhdhv1alpha3.Application{}
+	}
+	application := existingBackstageCR.Spec.Application
+	desiredApplication := desiredSpec.Application
+	if application.AppConfig == nil {
+		application.AppConfig = &rhdhv1alpha3.AppConfig{}
+	}
+	application.AppConfig.ConfigMaps = desiredApplication.AppConfig.ConfigMaps
+	application.DynamicPluginsConfigMapName = desiredApplication.DynamicPluginsConfigMapName
+	if application.ExtraEnvs == nil {
+		application.ExtraEnvs = &rhdhv1alpha3.ExtraEnvs{}
+	}
+	application.ExtraEnvs.Secrets = desiredApplication.ExtraEnvs.Secrets
+	application.Replicas = desiredApplication.Replicas
+	// only the resources of the backend container are patched; the rest of the deployment patch is the user's
+	deployment, err := getBackstageDeployment(existingBackstageCR.Spec.Deployment, rhdhConfig.Resources)
+	if err != nil {
+		rhdhLogger.Error(err, "Error occurred when computing RHDH resource spec", "CR-Name", rhdhName)
+		return err
+	}
+	existingBackstageCR.Spec.Deployment = deployment
+
+	if reflect.DeepEqual(originalBackstageCR.Spec, existingBackstageCR.Spec) {
+		return nil
+	}
+	rhdhLogger.Info("Updating Backstage CR", "CR-Name", rhdhName)
+	if err := k8client.Patch(ctx, existingBackstageCR, client.MergeFrom(originalBackstageCR)); err != nil {
+		rhdhLogger.Error(err, "Error occurred when updating RHDH resource", "CR-Name", rhdhName)
+		return err
+	}
+	rhdhLogger.Info("Successfully updated RHDH resource", "CR-Name", rhdhName)
 	return nil
 }
 
-// GetOrCreateConfigMaps creates or gets the configmap list
-func GetOrCreateConfigMaps(ctx context.Context, client client.Client,
-	clusterDomain, serverlessWorkflowNamespace string,
-	tektonEnabled, argoCDEnabled bool,
-	rhdhConfig orchestratorv1alpha2.RHDHConfig) ([]rhdhv1alpha3.FileObjectRef, error) {
+// getBackstageSpec returns the Backstage spec the orchestrator expects for the RHDH config.
+func getBackstageSpec(
+	rhdhConfig orchestratorv1alpha2.RHDHConfig,
+	bsConfigMapList []rhdhv1alpha3.FileObjectRef) (rhdhv1alpha3.BackstageSpec, error) {
+	secrets := []rhdhv1alpha3.EnvObjectRef{{Name: BackendAuthSecretName}}
+	for _, secretName := range rhdhConfig.ExtraEnvSecrets {
+		secrets = append(secrets, rhdhv1alpha3.EnvObjectRef{Name: secretName})
+	}
+
+	replicas := rhdhReplica
+	if rhdhConfig.Replicas != nil {
+		replicas = *rhdhConfig.Replicas
+	}
+
+	deployment, err := getBackstageDeployment(nil, rhdhConfig.Resources)
+	if err != nil {
+		return rhdhv1alpha3.BackstageSpec{}, err
+	}
+
+	return rhdhv1alpha3.BackstageSpec{
+		Application: &rhdhv1alpha3.Application{
+			AppConfig:                   &rhdhv1alpha3.AppConfig{ConfigMaps: bsConfigMapList},
+			DynamicPluginsConfigMapName: AppConfigRHDHDynamicPluginName,
+			ExtraEnvs: &rhdhv1alpha3.ExtraEnvs{
+				Secrets: secrets,
+			},
+			Replicas: util.MakePointer(replicas),
+		},
+		Deployment: deployment,
+	}, nil
+}
+
+// getBackstageDeployment sets the resources of the backend container in the deployment patch of the Backstage CR,
+// keeping the rest of the patch. When no resources are configured, the resources of the backend container are
+// removed from the patch, along with the parts of the patch left empty.
+func getBackstageDeployment(
+	deployment *rhdhv1alpha3.BackstageDeployment,
+	resources orchestratorv1alpha2.ContainerResources) (*rhdhv1alpha3.BackstageDeployment, error) {
+	resourceList := func(memoryCpu orchestratorv1alpha2.ResourceQuantities) map[string]any {
+		quantities := map[string]any{}
+		if memoryCpu.Cpu != "" {
+			quantities["cpu"] = memoryCpu.Cpu
+		}
+		if memoryCpu.Memory != "" {
+			quantities["memory"] = memoryCpu.Memory
+		}
+		return quantities
+	}
+	containerResources := map[string]any{}
+	if requests := resourceList(resources.Requests); len(requests) > 0 {
+		containerResources["requests"] = requests
+	}
+	if limits := resourceList(resources.Limits); len(limits) > 0 {
+		containerResources["limits"] = limits
+	}
+
+	patch := map[string]any{}
+	originalPatch := map[string]any{}
+	if deployment != nil && deployment.Patch != nil && len(deployment.Patch.Raw) > 0 {
+		if err := json.Unmarshal(deployment.Patch.Raw, &patch); err != nil {
+			return nil, fmt.Errorf("invalid deployment patch of the RHDH resource: %w", err)
+		}
+		if err := json.Unmarshal(deployment.Patch.Raw, &originalPatch); err != nil {
+			return nil, err
+		}
+	}
+
+	podSpec := getNestedMap(patch, "spec", "template", "spec")
+	containers, _ := podSpec["containers"].([]any)
+	backendIndex := slices.IndexFunc(containers, func(item any) bool {
+		container, ok := item.(map[string]any)
+		return ok && container["name"] == rhdhBackendContainerName
+	})
+	if len(containerResources) == 0 {
+		if backendIndex < 0 {
+			return deployment, nil
+		}
+		backendContainer := containers[backendIndex].(map[string]any)
+		delete(backendContainer, "resources")
+		if len(backendContainer) == 1 {
+			containers = slices.Delete(containers, backendIndex, backendIndex+1)
+		}
+		if len(containers) == 0 {
+			delete(podSpec, "containers")
+		} else {
+			podSpec["containers"] = containers
+		}
+		deleteEmptyMaps(patch, "spec", "template", "spec")
+	} else {
+		if backendIndex < 0 {
+			containers = append(containers, map[string]any{"name": rhdhBackendContainerName})
+			backendIndex = len(containers) - 1
+			podSpec["containers"] = containers
+		}
+		containers[backendIndex].(map[string]any)["resources"] = containerResources
+	}
+	if reflect.DeepEqual(patch, originalPatch) {
+		return deployment, nil
+	}
+	if len(patch) == 0 {
+		return nil, nil
+	}
+
+	deploymentPatch, err := json.Marshal(patch)
+	if err != nil {
+		return nil, err
+	}
+	desiredDeployment := &rhdhv1alpha3.BackstageDeployment{}
+	if deployment != nil {
+		desiredDeployment = deployment.DeepCopy()
+	}
+	desiredDeployment.Patch = &apiextensionsv1.JSON{Raw: deploymentPatch}
+	return desiredDeployment, nil
+}
+
+// getNestedMap returns the map at the path of keys in parent, creating the missing maps.
+func getNestedMap(parent map[string]any, keys ...string) map[string]any {
+	for _, key := range keys {
+		child, ok := parent[key].(map[string]any)
+		if !ok {
+			child = map[string]any{}
+			parent[key] = child
+		}
+		parent = child
+	}
+	return parent
+}
+
+// deleteEmptyMaps deletes the maps left empty along the path of keys in parent, from the deepest one.
+func deleteEmptyMaps(parent map[string]any, keys ...string) {
+	if len(keys) == 0 {
+		return
+	}
+	child, ok := parent[keys[0]].(map[string]any)
+	if !ok {
+		return
+	}
+	deleteEmptyMaps(child, keys[1:]...)
+	if len(child) == 0 {
+		delete(parent, keys[0])
+	}
+}
+
+// ReconcileConfigMaps creates the RHDH ConfigMaps, or updates them when they drifted from the Orchestrator spec.
+// ConfigMaps annotated with SkipReconcileAnnotation set to "true" are created but never updated, to keep user edits;
+// each spec change they skip is reported once.
+// It returns the list of app-config ConfigMaps to reference in the Backstage CR.
+func ReconcileConfigMaps(ctx context.Context, client client.Client, recorder record.EventRecorder,
+	orchestrator *orchestratorv1alpha2.Orchestrator, baseURL string) ([]rhdhv1alpha3.FileObjectRef, error) {
 
 	cmLogger := log.FromContext(ctx)
 	cmLogger.Info("Processing ConfigMaps...")
 
+	spec := orchestrator.Spec
 	configmapList := make([]rhdhv1alpha3.FileObjectRef, 0)
-	namespace := rhdhConfig.Namespace
+	namespace := spec.RHDHConfig.Namespace
 	for cmName, configDataKey := range ConfigMapNameAndConfigDataKey {
 		if cmName != AppConfigRHDHDynamicPluginName {
 			configmapList = append(configmapList, rhdhv1alpha3.FileObjectRef{Name: cmName})
 		}
-		cmLogger.Info("Starting Configmap creation for:", "CM", cmName, "NS", namespace)
 
-		err := client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: cmName}, &corev1.ConfigMap{})
+		configValue, err := ConfigMapTemplateFactory(
+			cmName, baseURL, spec.PlatformConfig,
+			spec.ArgoCd.Enabled, spec.Tekton.Enabled, spec.RHDHConfig, spec.Disconnected)
 		if err != nil {
+			cmLogger.Error(err, "Error occurred when parsing config data for configmap", "CM", cmName)
+			return configmapList, fmt.Errorf("failed to parse template data for configmap: %s", err)
+		}
+
+		existingConfigMap := &corev1.ConfigMap{}
+		if err := client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: cmName}, existingConfigMap); err != nil {
 			if apierrors.IsNotFound(err) {
 				cmLogger.Info("Configmap does not exist, creating CM", "CM", cmName)
-				configValue, err := ConfigMapTemplateFactory(cmName, clusterDomain, serverlessWorkflowNamespace, argoCDEnabled, tektonEnabled, rhdhConfig)
-				if err != nil {
-					cmLogger.Error(err, "Error occurred when parsing config data for configmap", "CM", cmName)
-					return configmapList, fmt.Errorf("failed to parse template data for configmap: %s", err)
-				} else {
-					if err := CreateConfigMap(cmName, configDataKey, namespace, configValue, ctx, client); err != nil {
-						cmLogger.Error(err, "Error occurred when creating ConfigMap", "CM", cmName)
-						return configmapList, err
-					}
+				if err := CreateConfigMap(cmName, configDataKey, namespace, configValue, ctx, client); err != nil {
+					cmLogger.Error(err, "Error occurred when creating ConfigMap", "CM", cmName)
+					return configmapList, err
 				}
+				continue
+			}
+			cmLogger.Error(err, "Error occurred when retrieving ConfigMap", "CM", cmName)
+			return configmapList, err
+		}
+
+		if existingConfigMap.Data[configDataKey] == configValue {
+			continue
+		}
+		if existingConfigMap.Annotations[SkipReconcileAnnotation] == "true" {
+			skippedConfig := getConfigHash(configValue)
+			if existingConfigMap.Annotations[SkippedConfigAnnotation] == skippedConfig {
+				continue
 			}
+			cmLogger.Info("ConfigMap differs from the Orchestrator spec but is opted out of reconciliation", "CM", cmName)
+			// only the annotation is updated; the data edited by the user is kept
+			existingConfigMap.Annotations[SkippedConfigAnnotation] = skippedConfig
+			if err := client.Update(ctx, existingConfigMap); err != nil {
+				cmLogger.Error(err, "Error occurred when annotating ConfigMap", "CM", cmName)
+				return configmapList, err
+			}
+			recorder.Eventf(orchestrator, corev1.EventTypeNormal, "ConfigMapReconcileSkipped",
+				"ConfigMap %s/%s differs from the Orchestrator spec and is not updated because it is annotated with %s",
+				namespace, cmName, SkipReconcileAnnotation)
+			continue
+		}
+		if existingConfigMap.Data == nil {
+			existingConfigMap.Data = map[string]string{}
+		}
+		existingConfigMap.Data[configDataKey] = configValue
+		if err := client.Update(ctx, existingConfigMap); err != nil {
+			cmLogger.Error(err, "Error occurred when updating ConfigMap", "CM", cmName)
+			return configmapList, err
 		}
+		cmLogger.Info("Successfully updated ConfigMap", "CM", cmName)
+		recorder.Eventf(orchestrator, corev1.EventTypeNormal, "ConfigMapUpdated",
+			"Updated ConfigMap %s/%s to match the Orchestrator spec", namespace, cmName)
 	}
+	sort.Slice(configmapList, func(i, j int) bool {
+		return configmapList[i].Name < configmapList[j].Name
+	})
 	return configmapList, nil
 }
 
+// getConfigHash returns the hash of the rendered config of a ConfigMap.
+func getConfigHash(configValue string) string {
+	hash := sha256.Sum256([]byte(configValue))
+	return hex.EncodeToString(hash[:])
+}
+
 func CreateConfigMap(
 	name string, configDataKey string, namespace string, configValue string,
 	ctx context.Context, client client.Client) error {
@@ -257,12 +522,12 @@ func CreateConfigMap(
 	return nil
 }
 
-func HandleRHDHCleanUp(ctx context.Context, client client.Client, rhdhNamespace string) error {
+func HandleRHDHCleanUp(ctx context.Context, inventory *kubeoperations.InventoryClient, rhdhNamespace string) error {
 	rhdhLogger := log.FromContext(ctx)
 
-	namespaceExist, _ := kubeoperations.CheckNamespaceExist(ctx, client, rhdhNamespace)
+	namespaceExist, _ := kubeoperations.CheckNamespaceExist(ctx, inventory, rhdhNamespace)
 	if namespaceExist {
-		backstageCRList, err := listBackstageCRs(ctx, client, rhdhNamespace)
+		backstageCRList, err := listBackstageCRs(ctx, inventory, rhdhNamespace)
 
 		if err != nil || len(backstageCRList) == 0 {
 			rhdhLogger.Error(err, "Failed to list RHDH CRs or have no RHDH CRs created by Orchestrator Operator and cannot perform clean up process")
@@ -270,7 +535,7 @@ func HandleRHDHCleanUp(ctx context.Context, client client.Client, rhdhNamespace
 		}
 		if len(backstageCRList) == 1 {
 			// remove namespace
-			if err := kubeoperations.CleanUpNamespace(ctx, rhdhNamespace, client); err != nil {
+			if err := kubeoperations.CleanUpNamespace(ctx, rhdhNamespace, inventory); err != nil {
 				rhdhLogger.Error(err, "Error occurred when deleting namespace", "NS", "namespace")
 				return err
 			}
@@ -278,7 +543,7 @@ func HandleRHDHCleanUp(ctx context.Context, client client.Client, rhdhNamespace
 	}
 
 	// remove operator namespace
-	if err := kubeoperations.CleanUpNamespace(ctx, rhdhOperatorNamespace, client); err != nil {
+	if err := kubeoperations.CleanUpNamespace(ctx, rhdhOperatorNamespace, inventory); err != nil {
 		rhdhLogger.Error(err, "Error occurred when deleting namespace", "NS", rhdhOperatorNamespace)
 		return err
 	}
@@ -304,3 +569,27 @@ func listBackstageCRs(ctx context.Context, k8client client.Client, namespace str
 	rhdhLogger.Info("Successfully listed RHDH CRs", "Total", len(crList.Items))
 	return crList.Items, nil
 }
+
+// GetBackstageImage returns the image of the backend container of the Backstage CR, or an empty string until the
+// RHDH operator has deployed it.
+func GetBackstageImage(ctx context.Context, k8client client.Client, rhdhConfig orchestratorv1alpha2.RHDHConfig) (string, error) {
+	deployment := &appsv1.Deployment{}
+	if err := k8client.Get(ctx, types.NamespacedName{
+		Name: rhdhRuntimeObjectPrefix + rhdhConfig.Name, Namespace: rhdhConfig.Namespace}, deployment); err != nil {
+		if apierrors.IsNotFound(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	for _, container := range deployment.Spec.Template.Spec.Containers {
+		if container.Name == rhdhBackendContainerName {
+			return container.Image, nil
+		}
+	}
+	return "", nil
+}
+
+// CheckRHDHReady returns a NotReadyError until the deployment of the Backstage CR is available.
+func CheckRHDHReady(ctx context.Context, k8client client.Client, rhdhConfig orchestratorv1alpha2.RHDHConfig) error {
+	return kubeoperations.CheckDeploymentAvailable(ctx, k8client, rhdhConfig.Namespace, rhdhRuntimeObjectPrefix+rhdhConfig.Name)
+}
@@ -0,0 +1,228 @@
+package rhdh
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	orchestratorv1alpha2 "github.com/rhdhorchestrator/orchestrator-operator/api/v1alpha3"
+	kubeoperations "github.com/rhdhorchestrator/orchestrator-operator/internal/controller/kube"
+	"github.com/rhdhorchestrator/orchestrator-operator/internal/controller/util"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/record"
+	rhdhv1alpha3 "redhat-developer/red-hat-developer-hub-operator/api/v1alpha3"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const testRHDHNamespace = "rhdh"
+
+func newTestOrchestrator() *orchestratorv1alpha2.Orchestrator {
+	return &orchestratorv1alpha2.Orchestrator{
+		ObjectMeta: metav1.ObjectMeta{Name: "orchestrator-sample", Namespace: "default"},
+		Spec: orchestratorv1alpha2.OrchestratorSpec{
+			RHDHConfig: orchestratorv1alpha2.RHDHConfig{
+				Name:            "my-rhdh",
+				Namespace:       testRHDHNamespace,
+				InstallOperator: true,
+			},
+			PlatformConfig: orchestratorv1alpha2.PlatformConfig{Namespace: "sonataflow-infra"},
+		},
+	}
+}
+
+func TestReconcileConfigMaps(t *testing.T) {
+	ctx := context.TODO()
+	scheme := runtime.NewScheme()
+	utilruntime.Must(corev1.AddToScheme(scheme))
+	utilruntime.Must(orchestratorv1alpha2.AddToScheme(scheme))
+
+	staleConfigMap := func(annotations map[string]string) *corev1.ConfigMap {
+		return &corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        AppConfigRHDHDynamicPluginName,
+				Namespace:   testRHDHNamespace,
+				Annotations: annotations,
+			},
+			Data: map[string]string{ConfigMapNameAndConfigDataKey[AppConfigRHDHDynamicPluginName]: "stale"},
+		}
+	}
+
+	testCases := []struct {
+		name           string
+		existing       *corev1.ConfigMap
+		expectUpdated  bool
+		expectedReason string
+	}{
+		{
+			name:           "Stale ConfigMap is updated",
+			existing:       staleConfigMap(nil),
+			expectUpdated:  true,
+			expectedReason: "ConfigMapUpdated",
+		},
+		{
+			name:           "Opted out ConfigMap is kept",
+			existing:       staleConfigMap(map[string]string{SkipReconcileAnnotation: "true"}),
+			expectUpdated:  false,
+			expectedReason: "ConfigMapReconcileSkipped",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			orchestrator := newTestOrchestrator()
+			orchestrator.Spec.RHDHConfig.RHDHPlugins.NotificationsConfig.Enabled = true
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(tc.existing).Build()
+			recorder := record.NewFakeRecorder(10)
+			// the creations are reported by the inventory client
+			k8client := kubeoperations.NewInventoryClient(fakeClient, fakeClient, nil, recorder, orchestrator)
+
+			configMapList, err := ReconcileConfigMaps(ctx, k8client, recorder, orchestrator, "https://backstage-rhdh.apps.example.com")
+			assert.NoError(t, err)
+			assert.Len(t, configMapList, len(ConfigMapNameAndConfigDataKey)-1)
+
+			// the missing ConfigMaps are created
+			for cmName := range ConfigMapNameAndConfigDataKey {
+				assert.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Namespace: testRHDHNamespace, Name: cmName}, &corev1.ConfigMap{}))
+			}
+
+			expectedValue, err := ConfigMapTemplateFactory(AppConfigRHDHDynamicPluginName, "https://backstage-rhdh.apps.example.com",
+				orchestrator.Spec.PlatformConfig, false, false, orchestrator.Spec.RHDHConfig, orchestrator.Spec.Disconnected)
+			assert.NoError(t, err)
+
+			updated := &corev1.ConfigMap{}
+			assert.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Namespace: testRHDHNamespace, Name: AppConfigRHDHDynamicPluginName}, updated))
+			dataKey := ConfigMapNameAndConfigDataKey[AppConfigRHDHDynamicPluginName]
+			if tc.expectUpdated {
+				assert.Equal(t, expectedValue, updated.Data[dataKey])
+			} else {
+				assert.Equal(t, "stale", updated.Data[dataKey])
+			}
+
+			var reasons []string
+			for len(recorder.Events) > 0 {
+				// fake recorder events are formatted as "<type> <reason> <message>"
+				reasons = append(reasons, strings.Fields(<-recorder.Events)[1])
+			}
+			assert.Len(t, reasons, len(ConfigMapNameAndConfigDataKey))
+			assert.Contains(t, reasons, tc.expectedReason)
+
+			// the skipped spec change is reported once
+			_, err = ReconcileConfigMaps(ctx, k8client, recorder, orchestrator, "https://backstage-rhdh.apps.example.com")
+			assert.NoError(t, err)
+			assert.Empty(t, recorder.Events)
+		})
+	}
+}
+
+func TestHandleRHDHCRUpdatesExistingCR(t *testing.T) {
+	ctx := context.TODO()
+	scheme := runtime.NewScheme()
+	utilruntime.Must(rhdhv1alpha3.AddToScheme(scheme))
+	utilruntime.Must(apiextensionsv1.AddToScheme(scheme))
+
+	userImage := "quay.io/my-org/rhdh:custom"
+	existingBackstageCR := &rhdhv1alpha3.Backstage{
+		ObjectMeta: metav1.ObjectMeta{Name: "my-rhdh", Namespace: testRHDHNamespace},
+		Spec: rhdhv1alpha3.BackstageSpec{
+			Application: &rhdhv1alpha3.Application{
+				AppConfig:                   &rhdhv1alpha3.AppConfig{ConfigMaps: []rhdhv1alpha3.FileObjectRef{{Name: AppConfigRHDHName}}},
+				DynamicPluginsConfigMapName: AppConfigRHDHDynamicPluginName,
+				Replicas:                    util.MakePointer(int32(1)),
+				Image:                       &userImage,
+			},
+		},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(existingBackstageCR, &apiextensionsv1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: rhdhCRDName}}).
+		Build()
+
+	rhdhConfig := newTestOrchestrator().Spec.RHDHConfig
+	rhdhConfig.Replicas = util.MakePointer(int32(3))
+	rhdhConfig.ExtraEnvSecrets = []string{"my-integrations-secret"}
+	rhdhConfig.Resources = orchestratorv1alpha2.ContainerResources{Limits: orchestratorv1alpha2.ResourceQuantities{Memory: "2Gi"}}
+	configMapList := []rhdhv1alpha3.FileObjectRef{{Name: AppConfigRHDHName}, {Name: AppConfigRHDHAuthName}}
+
+	assert.NoError(t, HandleRHDHCR(rhdhConfig, configMapList, ctx, fakeClient))
+
+	updated := &rhdhv1alpha3.Backstage{}
+	assert.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Namespace: testRHDHNamespace, Name: "my-rhdh"}, updated))
+	application := updated.Spec.Application
+	assert.Equal(t, int32(3), *application.Replicas)
+	assert.Equal(t, configMapList, application.AppConfig.ConfigMaps)
+	assert.Equal(t, []rhdhv1alpha3.EnvObjectRef{{Name: BackendAuthSecretName}, {Name: "my-integrations-secret"}}, application.ExtraEnvs.Secrets)
+	assert.Equal(t, userImage, *application.Image, "Fields not owned by the orchestrator are kept")
+	assert.NotNil(t, updated.Spec.Deployment)
+	assert.JSONEq(t,
+		`{"spec":{"template":{"spec":{"containers":[{"name":"backstage-backend","resources":{"limits":{"memory":"2Gi"}}}]}}}}`,
+		string(updated.Spec.Deployment.Patch.Raw))
+
+	// the resources cleared from the spec are removed from the Backstage CR
+	rhdhConfig.Resources = orchestratorv1alpha2.ContainerResources{}
+	assert.NoError(t, HandleRHDHCR(rhdhConfig, configMapList, ctx, fakeClient))
+	updated = &rhdhv1alpha3.Backstage{}
+	assert.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Namespace: testRHDHNamespace, Name: "my-rhdh"}, updated))
+	assert.Nil(t, updated.Spec.Deployment)
+	assert.Equal(t, userImage, *updated.Spec.Application.Image)
+}
+
+func TestGetBackstageDeployment(t *testing.T) {
+	userPatch := `{"spec":{"replicas":2,"template":{"spec":{"containers":[` +
+		`{"name":"install-dynamic-plugins","env":[{"name":"NPM_CONFIG_REGISTRY","value":"https://npm.example.com"}]},` +
+		`{"name":"backstage-backend","resources":{"requests":{"cpu":"1"}},"env":[{"name":"LOG_LEVEL","value":"debug"}]}]}}}}`
+	userDeployment := &rhdhv1alpha3.BackstageDeployment{Patch: &apiextensionsv1.JSON{Raw: []byte(userPatch)}}
+
+	testCases := []struct {
+		name          string
+		deployment    *rhdhv1alpha3.BackstageDeployment
+		resources     orchestratorv1alpha2.ContainerResources
+		expectedPatch string
+	}{
+		{
+			name: "No patch and no resources",
+		},
+		{
+			name:       "Backend resources removed from the user patch when no resources are configured",
+			deployment: userDeployment,
+			expectedPatch: `{"spec":{"replicas":2,"template":{"spec":{"containers":[` +
+				`{"name":"install-dynamic-plugins","env":[{"name":"NPM_CONFIG_REGISTRY","value":"https://npm.example.com"}]},` +
+				`{"name":"backstage-backend","env":[{"name":"LOG_LEVEL","value":"debug"}]}]}}}}`,
+		},
+		{
+			name: "Patch with only the backend resources removed when no resources are configured",
+			deployment: &rhdhv1alpha3.BackstageDeployment{Patch: &apiextensionsv1.JSON{Raw: []byte(
+				`{"spec":{"template":{"spec":{"containers":[{"name":"backstage-backend","resources":{"limits":{"memory":"2Gi"}}}]}}}}`)}},
+		},
+		{
+			name:      "Resources of the backend container patched",
+			resources: orchestratorv1alpha2.ContainerResources{Limits: orchestratorv1alpha2.ResourceQuantities{Memory: "2Gi"}},
+			expectedPatch: `{"spec":{"template":{"spec":{"containers":[` +
+				`{"name":"backstage-backend","resources":{"limits":{"memory":"2Gi"}}}]}}}}`,
+		},
+		{
+			name:       "Resources merged into the user patch",
+			deployment: userDeployment,
+			resources:  orchestratorv1alpha2.ContainerResources{Limits: orchestratorv1alpha2.ResourceQuantities{Memory: "2Gi"}},
+			expectedPatch: `{"spec":{"replicas":2,"template":{"spec":{"containers":[` +
+				`{"name":"install-dynamic-plugins","env":[{"name":"NPM_CONFIG_REGISTRY","value":"https://npm.example.com"}]},` +
+				`{"name":"backstage-backend","resources":{"limits":{"memory":"2Gi"}},"env":[{"name":"LOG_LEVEL","value":"debug"}]}]}}}}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			deployment, err := getBackstageDeployment(tc.deployment, tc.resources)
+			assert.NoError(t, err)
+			if tc.expectedPatch == "" {
+				assert.Nil(t, deployment)
+				return
+			}
+			assert.JSONEq(t, tc.expectedPatch, string(deployment.Patch.Raw))
+		})
+	}
+}
@@ -4,13 +4,14 @@ import (
 	"bytes"
 	"fmt"
 	"github.com/rhdhorchestrator/orchestrator-operator/api/v1alpha3"
+	"strings"
 	"text/template"
 )
 
 func ConfigMapTemplateFactory(
-	cmTemplateType, clusterDomain, serverlessWorkflowNamespace string,
+	cmTemplateType, baseURL string, platformConfig v1alpha3.PlatformConfig,
 	argoCDEnabled, tektonEnabled bool,
-	rhdhConfig v1alpha3.RHDHConfig) (string, error) {
+	rhdhConfig v1alpha3.RHDHConfig, disconnected v1alpha3.DisconnectedConfig) (string, error) {
 	switch cmTemplateType {
 	case AppConfigRHDHName:
 		configData := RHDHConfig{
@@ -21,7 +22,7 @@ func ConfigMapTemplateFactory(
 			ArgoCDUrl:      ArgoCDUrl,
 			ArgoCDEnabled:  argoCDEnabled,
 			BackendSecret:  BackendSecretKey,
-			ClusterDomain:  clusterDomain,
+			BaseURL:        baseURL,
 		}
 		formattedConfig, err := parseConfigTemplate(RHDHConfigTempl, configData)
 		if err != nil {
@@ -29,14 +30,13 @@ func ConfigMapTemplateFactory(
 		}
 		return formattedConfig, nil
 	case AppConfigRHDHAuthName:
+		providers := getAuthProviders(rhdhConfig)
 		configData := RHDHConfigAuth{
-			GitHubToken:         GitHubToken,
-			Environment:         "development",
-			GitHubClientId:      GitHubClientID,
-			GitHubClientSecret:  GitHubClientSecret,
-			GitLabToken:         GitLabToken,
-			GitLabHost:          GitLabHost,
-			EnableGuestProvider: rhdhConfig.DevMode,
+			IntegrationGroups: getIntegrationGroups(rhdhConfig),
+			Environment:       valueOrDefault(rhdhConfig.Auth.Environment, "development"),
+			SignInPage:        string(providers[0].Type),
+			SessionSecret:     getAuthSessionSecret(rhdhConfig.Auth, providers),
+			Providers:         providers,
 		}
 		formattedConfig, err := parseConfigTemplate(RHDHAuthTempl, configData)
 		if err != nil {
@@ -47,6 +47,7 @@ func ConfigMapTemplateFactory(
 		configData := RHDHConfigCatalog{
 			EnableGuestProvider: rhdhConfig.DevMode,
 			CatalogBranch:       CatalogBranch,
+			CatalogLocations:    disconnected.CatalogLocations,
 		}
 		formattedConfig, err := parseConfigTemplate(RHDHCatalogTempl, configData)
 		if err != nil {
@@ -67,7 +68,7 @@ func ConfigMapTemplateFactory(
 			OrchestratorBackendIntegrity:           pluginsMap[OrchestratorBackend].Integrity,
 			OrchestratorPackage:                    pluginsMap[Orchestrator].Package,
 			OrchestratorIntegrity:                  pluginsMap[Orchestrator].Integrity,
-			Scope:                                  Scope,
+			Scope:                                  getPluginScope(disconnected),
 			NotificationEmailEnabled:               rhdhConfig.RHDHPlugins.NotificationsConfig.Enabled,
 			NotificationEmailHostname:              NotificationHostname,
 			NotificationEmailUsername:              NotificationUsername,
@@ -75,9 +76,11 @@ func ConfigMapTemplateFactory(
 			NotificationEmailSender:                rhdhConfig.RHDHPlugins.NotificationsConfig.Sender,
 			NotificationEmailReplyTo:               rhdhConfig.RHDHPlugins.NotificationsConfig.Recipient,
 			NotificationEmailPort:                  rhdhConfig.RHDHPlugins.NotificationsConfig.Port,
-			WorkflowNamespace:                      serverlessWorkflowNamespace,
+			WorkflowNamespace:                      platformConfig.Namespace,
+			AdditionalWorkflowNamespaces:           platformConfig.AdditionalWorkflowNamespaces,
 			ScaffolderBackendOrchestratorPackage:   pluginsMap[ScaffolderBackendOrchestrator].Package,
 			ScaffolderBackendOrchestratorIntegrity: pluginsMap[ScaffolderBackendOrchestrator].Integrity,
+			ScaffolderBackendModules:               getScaffolderModules(rhdhConfig),
 		}
 		formattedConfig, err := parseConfigTemplate(RHDHDynamicPluginTempl, configData)
 		if err != nil {
@@ -89,6 +92,42 @@ func ConfigMapTemplateFactory(
 	}
 }
 
+// GetNpmRegistry returns the npm registry mirror when one is configured, the default registry otherwise.
+func GetNpmRegistry(disconnected v1alpha3.DisconnectedConfig) string {
+	if disconnected.NpmRegistry != "" {
+		return disconnected.NpmRegistry
+	}
+	return NpmRegistry
+}
+
+func getPluginScope(disconnected v1alpha3.DisconnectedConfig) string {
+	if disconnected.PluginBaseUrl != "" {
+		return strings.TrimSuffix(disconnected.PluginBaseUrl, "/")
+	}
+	return Scope
+}
+
+// RenderConfigs returns the rendered content of the RHDH ConfigMaps and of the npmrc secret.
+func RenderConfigs(
+	baseURL string, platformConfig v1alpha3.PlatformConfig,
+	argoCDEnabled, tektonEnabled bool,
+	rhdhConfig v1alpha3.RHDHConfig, disconnected v1alpha3.DisconnectedConfig) ([]string, error) {
+	renderedConfigs := []string{getNpmrc(disconnected)}
+	for cmName := range ConfigMapNameAndConfigDataKey {
+		configValue, err := ConfigMapTemplateFactory(
+			cmName, baseURL, platformConfig, argoCDEnabled, tektonEnabled, rhdhConfig, disconnected)
+		if err != nil {
+			return nil, err
+		}
+		renderedConfigs = append(renderedConfigs, configValue)
+	}
+	return renderedConfigs, nil
+}
+
+func getNpmrc(disconnected v1alpha3.DisconnectedConfig) string {
+	return fmt.Sprintf("registry=%s", GetNpmRegistry(disconnected))
+}
+
 func parseConfigTemplate(templateString string, configData any) (string, error) {
 	// parse the template
 	templ, err := template.New("config").Parse(templateString)
@@ -0,0 +1,228 @@
+package rhdh
+
+import (
+	"strings"
+	"testing"
+
+	orchestratorv1alpha2 "github.com/rhdhorchestrator/orchestrator-operator/api/v1alpha3"
+	"github.com/stretchr/testify/assert"
+	"sigs.k8s.io/yaml"
+)
+
+func TestConfigMapTemplateFactoryAuth(t *testing.T) {
+	testCases := []struct {
+		name               string
+		devMode            bool
+		auth               orchestratorv1alpha2.RHDHAuth
+		expectedSignInPage string
+		// expectedSessionSecret is the reference to the secret signing the auth sessions, when set
+		expectedSessionSecret string
+		expectedProviders     map[string]any
+	}{
+		{
+			name:               "Default GitHub provider",
+			expectedSignInPage: "github",
+			expectedProviders: map[string]any{
+				"github": map[string]any{"development": map[string]any{
+					"clientId":     "${GITHUB_CLIENT_ID}",
+					"clientSecret": "${GITHUB_CLIENT_SECRET}",
+					"signIn":       resolvers("usernameMatchingUserEntityName"),
+				}},
+			},
+		},
+		{
+			name:               "Default GitHub provider and guest provider in devMode",
+			devMode:            true,
+			expectedSignInPage: "github",
+			expectedProviders: map[string]any{
+				"github": map[string]any{"development": map[string]any{
+					"clientId":     "${GITHUB_CLIENT_ID}",
+					"clientSecret": "${GITHUB_CLIENT_SECRET}",
+					"signIn":       resolvers("usernameMatchingUserEntityName"),
+				}},
+				"guest": map[string]any{"dangerouslyAllowOutsideDevelopment": true, "userEntityRef": "user:default/guest"},
+			},
+		},
+		{
+			name: "Keycloak OIDC provider in production",
+			auth: orchestratorv1alpha2.RHDHAuth{
+				Environment:      "production",
+				SessionSecretKey: "KEYCLOAK_SESSION_SECRET",
+				Providers: []orchestratorv1alpha2.RHDHAuthProvider{{
+					Type:            orchestratorv1alpha2.RHDHAuthProviderOIDC,
+					ClientIDKey:     "KEYCLOAK_CLIENT_ID",
+					ClientSecretKey: "KEYCLOAK_CLIENT_SECRET",
+					URL:             "https://keycloak.example.com/realms/rhdh/.well-known/openid-configuration",
+					SignInResolvers: []string{"preferredUsernameMatchingUserEntityName", "emailMatchingUserEntityProfileEmail"},
+				}},
+			},
+			expectedSignInPage:    "oidc",
+			expectedSessionSecret: "${KEYCLOAK_SESSION_SECRET}",
+			expectedProviders: map[string]any{
+				"oidc": map[string]any{"production": map[string]any{
+					"clientId":     "${KEYCLOAK_CLIENT_ID}",
+					"clientSecret": "${KEYCLOAK_CLIENT_SECRET}",
+					"metadataUrl":  "https://keycloak.example.com/realms/rhdh/.well-known/openid-configuration",
+					"prompt":       "auto",
+					"signIn": resolvers(
+						"preferredUsernameMatchingUserEntityName", "emailMatchingUserEntityProfileEmail"),
+				}},
+			},
+		},
+		{
+			name: "OIDC provider with the default session secret",
+			auth: orchestratorv1alpha2.RHDHAuth{
+				Providers: []orchestratorv1alpha2.RHDHAuthProvider{{
+					Type: orchestratorv1alpha2.RHDHAuthProviderOIDC,
+					URL:  "https://keycloak.example.com/realms/rhdh/.well-known/openid-configuration",
+				}},
+			},
+			expectedSignInPage:    "oidc",
+			expectedSessionSecret: "${AUTH_SESSION_SECRET}",
+			expectedProviders: map[string]any{
+				"oidc": map[string]any{"development": map[string]any{
+					"clientId":     "${OIDC_CLIENT_ID}",
+					"clientSecret": "${OIDC_CLIENT_SECRET}",
+					"metadataUrl":  "https://keycloak.example.com/realms/rhdh/.well-known/openid-configuration",
+					"prompt":       "auto",
+					"signIn":       resolvers("emailMatchingUserEntityProfileEmail"),
+				}},
+			},
+		},
+		{
+			name: "Microsoft and GitLab providers",
+			auth: orchestratorv1alpha2.RHDHAuth{
+				Providers: []orchestratorv1alpha2.RHDHAuthProvider{
+					{Type: orchestratorv1alpha2.RHDHAuthProviderMicrosoft},
+					{Type: orchestratorv1alpha2.RHDHAuthProviderGitLab},
+				},
+			},
+			expectedSignInPage: "microsoft",
+			expectedProviders: map[string]any{
+				"microsoft": map[string]any{"development": map[string]any{
+					"clientId":     "${MICROSOFT_CLIENT_ID}",
+					"clientSecret": "${MICROSOFT_CLIENT_SECRET}",
+					"tenantId":     "${MICROSOFT_TENANT_ID}",
+					"signIn":       resolvers("emailMatchingUserEntityProfileEmail"),
+				}},
+				"gitlab": map[string]any{"development": map[string]any{
+					"clientId":     "${GITLAB_CLIENT_ID}",
+					"clientSecret": "${GITLAB_CLIENT_SECRET}",
+					"audience":     "https://gitlab.com",
+					"signIn":       resolvers("usernameMatchingUserEntityName"),
+				}},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rhdhConfig := newTestOrchestrator().Spec.RHDHConfig
+			rhdhConfig.DevMode = tc.devMode
+			rhdhConfig.Auth = tc.auth
+			rendered, err := ConfigMapTemplateFactory(AppConfigRHDHAuthName, "", orchestratorv1alpha2.PlatformConfig{},
+				false, false, rhdhConfig, orchestratorv1alpha2.DisconnectedConfig{})
+			assert.NoError(t, err)
+
+			appConfig := map[string]any{}
+			assert.NoError(t, yaml.Unmarshal([]byte(rendered), &appConfig), rendered)
+			assert.Equal(t, tc.expectedSignInPage, appConfig["signInPage"])
+			auth := appConfig["auth"].(map[string]any)
+			assert.Equal(t, tc.expectedProviders, auth["providers"])
+			if tc.expectedSessionSecret != "" {
+				assert.Equal(t, map[string]any{"secret": tc.expectedSessionSecret}, auth["session"])
+			} else {
+				assert.NotContains(t, auth, "session")
+			}
+		})
+	}
+}
+
+func resolvers(names ...string) map[string]any {
+	var resolverList []any
+	for _, name := range names {
+		resolverList = append(resolverList, map[string]any{"resolver": name})
+	}
+	return map[string]any{"resolvers": resolverList}
+}
+
+func TestConfigMapTemplateFactoryIntegrations(t *testing.T) {
+	testCases := []struct {
+		name                 string
+		integrations         []orchestratorv1alpha2.RHDHIntegration
+		expectedIntegrations map[string]any
+		expectedModules      []string
+	}{
+		{
+			name: "Default GitHub and GitLab integrations",
+			expectedIntegrations: map[string]any{
+				"github": []any{map[string]any{"host": "github.com", "token": "${GITHUB_TOKEN}"}},
+				"gitlab": []any{map[string]any{"host": "${GITLAB_HOST}", "token": "${GITLAB_TOKEN}",
+					"apiBaseUrl": "https://${GITLAB_HOST}/api/v4"}},
+			},
+			expectedModules: []string{
+				"./dynamic-plugins/dist/backstage-plugin-scaffolder-backend-module-github-dynamic",
+				"./dynamic-plugins/dist/backstage-plugin-scaffolder-backend-module-gitlab-dynamic",
+			},
+		},
+		{
+			name: "GitHub Enterprise, Bitbucket and Azure DevOps integrations",
+			integrations: []orchestratorv1alpha2.RHDHIntegration{
+				{Type: orchestratorv1alpha2.RHDHIntegrationGitHub, Host: "github.example.com", TokenKey: "GHE_TOKEN"},
+				{Type: orchestratorv1alpha2.RHDHIntegrationBitbucketServer, Host: "bitbucket.example.com"},
+				{Type: orchestratorv1alpha2.RHDHIntegrationGitHub},
+				{Type: orchestratorv1alpha2.RHDHIntegrationBitbucketCloud},
+				{Type: orchestratorv1alpha2.RHDHIntegrationAzure},
+			},
+			expectedIntegrations: map[string]any{
+				"github": []any{
+					map[string]any{"host": "github.example.com", "token": "${GHE_TOKEN}",
+						"apiBaseUrl": "https://github.example.com/api/v3"},
+					map[string]any{"host": "github.com", "token": "${GITHUB_TOKEN}"},
+				},
+				"bitbucketServer": []any{map[string]any{"host": "bitbucket.example.com", "token": "${BITBUCKET_SERVER_TOKEN}",
+					"apiBaseUrl": "https://bitbucket.example.com/rest/api/1.0"}},
+				"bitbucketCloud": []any{map[string]any{"username": "${BITBUCKET_CLOUD_USERNAME}",
+					"appPassword": "${BITBUCKET_CLOUD_APP_PASSWORD}"}},
+				"azure": []any{map[string]any{"host": "dev.azure.com",
+					"credentials": []any{map[string]any{"personalAccessToken": "${AZURE_TOKEN}"}}}},
+			},
+			expectedModules: []string{
+				"./dynamic-plugins/dist/backstage-plugin-scaffolder-backend-module-github-dynamic",
+				"./dynamic-plugins/dist/backstage-plugin-scaffolder-backend-module-bitbucket-server-dynamic",
+				"./dynamic-plugins/dist/backstage-plugin-scaffolder-backend-module-bitbucket-cloud-dynamic",
+				"./dynamic-plugins/dist/backstage-plugin-scaffolder-backend-module-azure-dynamic",
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rhdhConfig := newTestOrchestrator().Spec.RHDHConfig
+			rhdhConfig.Integrations = tc.integrations
+			rendered, err := ConfigMapTemplateFactory(AppConfigRHDHAuthName, "", orchestratorv1alpha2.PlatformConfig{},
+				false, false, rhdhConfig, orchestratorv1alpha2.DisconnectedConfig{})
+			assert.NoError(t, err)
+			appConfig := map[string]any{}
+			assert.NoError(t, yaml.Unmarshal([]byte(rendered), &appConfig), rendered)
+			assert.Equal(t, tc.expectedIntegrations, appConfig["integrations"])
+
+			rendered, err = ConfigMapTemplateFactory(AppConfigRHDHDynamicPluginName, "", orchestratorv1alpha2.PlatformConfig{},
+				false, false, rhdhConfig, orchestratorv1alpha2.DisconnectedConfig{})
+			assert.NoError(t, err)
+			dynamicPlugins := struct {
+				Plugins []struct {
+					Package string `json:"package"`
+				} `json:"plugins"`
+			}{}
+			assert.NoError(t, yaml.Unmarshal([]byte(rendered), &dynamicPlugins), rendered)
+			var modules []string
+			for _, plugin := range dynamicPlugins.Plugins {
+				if strings.HasPrefix(plugin.Package, "./dynamic-plugins/dist/backstage-plugin-scaffolder-backend-module-") {
+					modules = append(modules, plugin.Package)
+				}
+			}
+			assert.Equal(t, tc.expectedModules, modules)
+		})
+	}
+}
@@ -6,6 +6,12 @@ const (
 	AppConfigRHDHCatalogName       = "app-config-rhdh-catalog"
 	AppConfigRHDHDynamicPluginName = "dynamic-plugins-rhdh"
 	NpmRegistry                    = "https://npm.stage.registry.redhat.com"
-	Scope                          = "https://github.com/rhdhorchestrator/orchestrator-plugins-internal-release/releases/download/v1.5.0-rc.2"
+	Scope                          = "https://github.com/rhdhorchestrator/orchestrator-plugins-internal-release/releases/download/v" + PluginsVersion
 	CatalogBranch                  = "v1.5.x"
+
+	// SkipReconcileAnnotation opts a ConfigMap out of the drift correction when set to "true"
+	SkipReconcileAnnotation = "rhdh.redhat.com/skip-reconcile"
+	// SkippedConfigAnnotation holds the hash of the rendered config last skipped for a ConfigMap opted out of the
+	// drift correction, so that each skipped spec change is reported once
+	SkippedConfigAnnotation = "rhdh.redhat.com/skipped-config-hash"
 )
@@ -0,0 +1,266 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package rhdh
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"reflect"
+
+	orchestratorv1alpha2 "github.com/rhdhorchestrator/orchestrator-operator/api/v1alpha3"
+	kubeoperations "github.com/rhdhorchestrator/orchestrator-operator/internal/controller/kube"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+const (
+	HTTPRouteCRDName    = "httproutes.gateway.networking.k8s.io"
+	httpRouteAPIVersion = "gateway.networking.k8s.io/v1"
+	httpRouteKind       = "HTTPRoute"
+	// rhdhServicePort is the http-backend port of the service created by the RHDH operator
+	rhdhServicePort = 80
+)
+
+// HandleRHDHIngress exposes the RHDH instance at baseURL with the resource selected by the ingress type,
+// on the clusters without OpenShift Routes. The resource of the other type is deleted.
+func HandleRHDHIngress(
+	ctx context.Context, k8client client.Client, recorder record.EventRecorder,
+	orchestrator *orchestratorv1alpha2.Orchestrator, rhdhConfig orchestratorv1alpha2.RHDHConfig, baseURL string) error {
+	parsedURL, err := url.Parse(baseURL)
+	if err != nil || parsedURL.Hostname() == "" {
+		return fmt.Errorf("invalid RHDH base URL %q", baseURL)
+	}
+	host := parsedURL.Hostname()
+	name := rhdhRuntimeObjectPrefix + rhdhConfig.Name
+
+	switch rhdhConfig.Ingress.Type {
+	case orchestratorv1alpha2.RHDHIngressTypeHTTPRoute:
+		if err := deleteRHDHIngressObject(ctx, k8client, &networkingv1.Ingress{}, rhdhConfig.Namespace, name); err != nil {
+			return err
+		}
+		return handleRHDHHTTPRoute(ctx, k8client, recorder, orchestrator, rhdhConfig, name, host)
+	case orchestratorv1alpha2.RHDHIngressTypeNone:
+		if err := deleteRHDHIngressObject(ctx, k8client, &networkingv1.Ingress{}, rhdhConfig.Namespace, name); err != nil {
+			return err
+		}
+		return deleteRHDHIngressObject(ctx, k8client, NewHTTPRouteObject(), rhdhConfig.Namespace, name)
+	default:
+		if err := deleteRHDHIngressObject(ctx, k8client, NewHTTPRouteObject(), rhdhConfig.Namespace, name); err != nil {
+			return err
+		}
+		return handleRHDHKubernetesIngress(ctx, k8client, recorder, orchestrator, rhdhConfig, name, host)
+	}
+}
+
+func handleRHDHKubernetesIngress(
+	ctx context.Context, k8client client.Client, recorder record.EventRecorder,
+	orchestrator *orchestratorv1alpha2.Orchestrator, rhdhConfig orchestratorv1alpha2.RHDHConfig, name, host string) error {
+	logger := log.FromContext(ctx)
+	desiredIngress := getRHDHIngressObject(rhdhConfig, name, host)
+
+	existingIngress := &networkingv1.Ingress{}
+	if err := k8client.Get(ctx, types.NamespacedName{Name: name, Namespace: rhdhConfig.Namespace}, existingIngress); err != nil {
+		if !apierrors.IsNotFound(err) {
+			logger.Error(err, "Error occurred when retrieving Ingress", "Ingress", name)
+			return err
+		}
+		if err := k8client.Create(ctx, desiredIngress); err != nil {
+			logger.Error(err, "Error occurred when creating Ingress", "Ingress", name)
+			return err
+		}
+		logger.Info("Successfully created Ingress", "Ingress", name)
+		return nil
+	}
+
+	// the class set by the default IngressClass admission is kept
+	if desiredIngress.Spec.IngressClassName == nil {
+		desiredIngress.Spec.IngressClassName = existingIngress.Spec.IngressClassName
+	}
+	if reflect.DeepEqual(desiredIngress.Spec, existingIngress.Spec) {
+		return nil
+	}
+	existingIngress.Spec = desiredIngress.Spec
+	if err := k8client.Update(ctx, existingIngress); err != nil {
+		logger.Error(err, "Error occurred when updating Ingress", "Ingress", name)
+		return err
+	}
+	recorder.Eventf(orchestrator, corev1.EventTypeNormal, "IngressUpdated",
+		"Updated Ingress %s/%s to match the Orchestrator spec", rhdhConfig.Namespace, name)
+	return nil
+}
+
+func getRHDHIngressObject(rhdhConfig orchestratorv1alpha2.RHDHConfig, name, host string) *networkingv1.Ingress {
+	pathType := networkingv1.PathTypePrefix
+	ingress := &networkingv1.Ingress{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: rhdhConfig.Namespace,
+			Labels:    kubeoperations.AddLabel(),
+		},
+		Spec: networkingv1.IngressSpec{
+			Rules: []networkingv1.IngressRule{{
+				Host: host,
+				IngressRuleValue: networkingv1.IngressRuleValue{HTTP: &networkingv1.HTTPIngressRuleValue{
+					Paths: []networkingv1.HTTPIngressPath{{
+						Path:     "/",
+						PathType: &pathType,
+						Backend: networkingv1.IngressBackend{Service: &networkingv1.IngressServiceBackend{
+							Name: name,
+							Port: networkingv1.ServiceBackendPort{Number: rhdhServicePort},
+						}},
+					}},
+				}},
+			}},
+		},
+	}
+	if rhdhConfig.Ingress.ClassName != "" {
+		ingress.Spec.IngressClassName = &rhdhConfig.Ingress.ClassName
+	}
+	if rhdhConfig.Ingress.TLSSecretName != "" {
+		ingress.Spec.TLS = []networkingv1.IngressTLS{{Hosts: []string{host}, SecretName: rhdhConfig.Ingress.TLSSecretName}}
+	}
+	return ingress
+}
+
+func handleRHDHHTTPRoute(
+	ctx context.Context, k8client client.Client, recorder record.EventRecorder,
+	orchestrator *orchestratorv1alpha2.Orchestrator, rhdhConfig orchestratorv1alpha2.RHDHConfig, name, host string) error {
+	logger := log.FromContext(ctx)
+
+	if err := kubeoperations.CheckCRDExists(ctx, k8client, HTTPRouteCRDName); err != nil {
+		if apierrors.IsNotFound(err) {
+			logger.Info("CRD resource not found or ready", "CRD", HTTPRouteCRDName)
+			return err
+		}
+		logger.Error(err, "Error occurred when retrieving CRD", "CRD", HTTPRouteCRDName)
+		return err
+	}
+
+	desiredRoute := getRHDHHTTPRouteObject(rhdhConfig, name, host)
+	existingRoute := NewHTTPRouteObject()
+	if err := k8client.Get(ctx, types.NamespacedName{Name: name, Namespace: rhdhConfig.Namespace}, existingRoute); err != nil {
+		if !apierrors.IsNotFound(err) {
+			logger.Error(err, "Error occurred when retrieving HTTPRoute", "HTTPRoute", name)
+			return err
+		}
+		if err := k8client.Create(ctx, desiredRoute); err != nil {
+			logger.Error(err, "Error occurred when creating HTTPRoute", "HTTPRoute", name)
+			return err
+		}
+		logger.Info("Successfully created HTTPRoute", "HTTPRoute", name)
+		return nil
+	}
+
+	// the fields defaulted by the Gateway API are ignored
+	if reflect.DeepEqual(getHTTPRouteTarget(desiredRoute), getHTTPRouteTarget(existingRoute)) {
+		return nil
+	}
+	existingRoute.Object["spec"] = desiredRoute.Object["spec"]
+	if err := k8client.Update(ctx, existingRoute); err != nil {
+		logger.Error(err, "Error occurred when updating HTTPRoute", "HTTPRoute", name)
+		return err
+	}
+	recorder.Eventf(orchestrator, corev1.EventTypeNormal, "HTTPRouteUpdated",
+		"Updated HTTPRoute %s/%s to match the Orchestrator spec", rhdhConfig.Namespace, name)
+	return nil
+}
+
+// NewHTTPRouteObject returns an empty HTTPRoute of the Gateway API, which is not part of the scheme.
+func NewHTTPRouteObject() *unstructured.Unstructured {
+	route := &unstructured.Unstructured{}
+	route.SetGroupVersionKind(schema.FromAPIVersionAndKind(httpRouteAPIVersion, httpRouteKind))
+	return route
+}
+
+func getRHDHHTTPRouteObject(rhdhConfig orchestratorv1alpha2.RHDHConfig, name, host string) *unstructured.Unstructured {
+	gatewayNamespace := rhdhConfig.Ingress.GatewayNamespace
+	if gatewayNamespace == "" {
+		gatewayNamespace = rhdhConfig.Namespace
+	}
+	route := NewHTTPRouteObject()
+	route.SetName(name)
+	route.SetNamespace(rhdhConfig.Namespace)
+	route.SetLabels(kubeoperations.AddLabel())
+	route.Object["spec"] = map[string]interface{}{
+		"parentRefs": []interface{}{
+			map[string]interface{}{"name": rhdhConfig.Ingress.GatewayName, "namespace": gatewayNamespace},
+		},
+		"hostnames": []interface{}{host},
+		"rules": []interface{}{
+			map[string]interface{}{
+				"backendRefs": []interface{}{
+					map[string]interface{}{"name": name, "port": int64(rhdhServicePort)},
+				},
+			},
+		},
+	}
+	return route
+}
+
+// getHTTPRouteTarget returns the hostnames, gateway and backend of the HTTPRoute, as set by getRHDHHTTPRouteObject.
+func getHTTPRouteTarget(route *unstructured.Unstructured) []interface{} {
+	hostnames, _, _ := unstructured.NestedStringSlice(route.Object, "spec", "hostnames")
+	target := []interface{}{hostnames}
+	parentRefs, _, _ := unstructured.NestedSlice(route.Object, "spec", "parentRefs")
+	for _, parentRef := range parentRefs {
+		if parentRefMap, ok := parentRef.(map[string]interface{}); ok {
+			target = append(target, parentRefMap["name"], parentRefMap["namespace"])
+		}
+	}
+	rules, _, _ := unstructured.NestedSlice(route.Object, "spec", "rules")
+	for _, rule := range rules {
+		ruleMap, ok := rule.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		backendRefs, _, _ := unstructured.NestedSlice(ruleMap, "backendRefs")
+		for _, backendRef := range backendRefs {
+			if backendRefMap, ok := backendRef.(map[string]interface{}); ok {
+				target = append(target, backendRefMap["name"], fmt.Sprint(backendRefMap["port"]))
+			}
+		}
+	}
+	return target
+}
+
+// deleteRHDHIngressObject deletes the object exposing RHDH when it was created by the operator.
+func deleteRHDHIngressObject(ctx context.Context, k8client client.Client, obj client.Object, namespace, name string) error {
+	if err := k8client.Get(ctx, types.NamespacedName{Name: name, Namespace: namespace}, obj); err != nil {
+		if apierrors.IsNotFound(err) || meta.IsNoMatchError(err) {
+			return nil
+		}
+		return err
+	}
+	if !kubeoperations.CheckLabelExist(obj.GetLabels()) {
+		return nil
+	}
+	if err := k8client.Delete(ctx, obj); err != nil && !apierrors.IsNotFound(err) {
+		log.FromContext(ctx).Error(err, "Error occurred when deleting RHDH ingress object", "Name", name)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+package rhdh
+
+import (
+	"context"
+	"testing"
+
+	orchestratorv1alpha2 "github.com/rhdhorchestrator/orchestrator-operator/api/v1alpha3"
+	kubeoperations "github.com/rhdhorchestrator/orchestrator-operator/internal/controller/kube"
+	"github.com/stretchr/testify/assert"
+	networkingv1 "k8s.io/api/networking/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestHandleRHDHIngress(t *testing.T) {
+	ctx := context.TODO()
+	scheme := runtime.NewScheme()
+	utilruntime.Must(networkingv1.AddToScheme(scheme))
+	utilruntime.Must(apiextensionsv1.AddToScheme(scheme))
+
+	baseURL := "https://rhdh.example.com"
+	name := rhdhRuntimeObjectPrefix + "my-rhdh"
+	key := types.NamespacedName{Name: name, Namespace: testRHDHNamespace}
+	httpRouteCRD := &apiextensionsv1.CustomResourceDefinition{ObjectMeta: metav1.ObjectMeta{Name: HTTPRouteCRDName}}
+	staleIngress := getRHDHIngressObject(newTestOrchestrator().Spec.RHDHConfig, name, "stale.example.com")
+
+	testCases := []struct {
+		name            string
+		ingress         orchestratorv1alpha2.RHDHIngress
+		existing        []client.Object
+		expectIngress   bool
+		expectHTTPRoute bool
+		expectErr       bool
+		expectedEvent   string
+	}{
+		{
+			name:          "Ingress is created",
+			ingress:       orchestratorv1alpha2.RHDHIngress{ClassName: "nginx", TLSSecretName: "rhdh-tls"},
+			expectIngress: true,
+		},
+		{
+			name:          "Stale Ingress is updated",
+			existing:      []client.Object{staleIngress.DeepCopy()},
+			expectIngress: true,
+			expectedEvent: "Normal IngressUpdated",
+		},
+		{
+			name:     "Ingress is deleted when the exposure is disabled",
+			ingress:  orchestratorv1alpha2.RHDHIngress{Type: orchestratorv1alpha2.RHDHIngressTypeNone},
+			existing: []client.Object{staleIngress.DeepCopy()},
+		},
+		{
+			name: "HTTPRoute replaces the Ingress",
+			ingress: orchestratorv1alpha2.RHDHIngress{
+				Type: orchestratorv1alpha2.RHDHIngressTypeHTTPRoute, GatewayName: "gateway", GatewayNamespace: "gateways"},
+			existing:        []client.Object{httpRouteCRD, staleIngress.DeepCopy()},
+			expectHTTPRoute: true,
+		},
+		{
+			name:      "HTTPRoute requires the Gateway API",
+			ingress:   orchestratorv1alpha2.RHDHIngress{Type: orchestratorv1alpha2.RHDHIngressTypeHTTPRoute, GatewayName: "gateway"},
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rhdhConfig := newTestOrchestrator().Spec.RHDHConfig
+			rhdhConfig.Ingress = tc.ingress
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(tc.existing...).Build()
+			recorder := record.NewFakeRecorder(10)
+			orchestrator := newTestOrchestrator()
+			k8client := kubeoperations.NewInventoryClient(fakeClient, fakeClient, nil, recorder, orchestrator)
+
+			err := HandleRHDHIngress(ctx, k8client, recorder, orchestrator, rhdhConfig, baseURL)
+			if tc.expectErr {
+				assert.True(t, apierrors.IsNotFound(err))
+				return
+			}
+			assert.NoError(t, err)
+
+			ingress := &networkingv1.Ingress{}
+			err = fakeClient.Get(ctx, key, ingress)
+			if tc.expectIngress {
+				assert.NoError(t, err)
+				assert.Equal(t, "rhdh.example.com", ingress.Spec.Rules[0].Host)
+				assert.Equal(t, name, ingress.Spec.Rules[0].HTTP.Paths[0].Backend.Service.Name)
+				if tc.ingress.ClassName != "" {
+					assert.Equal(t, tc.ingress.ClassName, *ingress.Spec.IngressClassName)
+					assert.Equal(t, tc.ingress.TLSSecretName, ingress.Spec.TLS[0].SecretName)
+				}
+			} else {
+				assert.True(t, apierrors.IsNotFound(err))
+			}
+
+			route := NewHTTPRouteObject()
+			err = fakeClient.Get(ctx, key, route)
+			if tc.expectHTTPRoute {
+				assert.NoError(t, err)
+				assert.Equal(t, getHTTPRouteTarget(getRHDHHTTPRouteObject(rhdhConfig, name, "rhdh.example.com")),
+					getHTTPRouteTarget(route))
+			} else {
+				assert.True(t, apierrors.IsNotFound(err))
+			}
+
+			if tc.expectedEvent != "" {
+				assert.Contains(t, <-recorder.Events, tc.expectedEvent)
+			}
+		})
+	}
+}
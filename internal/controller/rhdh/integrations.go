@@ -0,0 +1,115 @@
+package rhdh
+
+import (
+	"slices"
+
+	"github.com/rhdhorchestrator/orchestrator-operator/api/v1alpha3"
+)
+
+type rhdhIntegrationDefaults struct {
+	host             string
+	tokenKey         string
+	scaffolderModule string
+}
+
+// integrationDefaults are the host, the secret key of the token and the scaffolder backend module of each SCM.
+var integrationDefaults = map[v1alpha3.RHDHIntegrationType]rhdhIntegrationDefaults{
+	v1alpha3.RHDHIntegrationGitHub: {
+		host:             "github.com",
+		tokenKey:         GitHubToken,
+		scaffolderModule: "backstage-plugin-scaffolder-backend-module-github-dynamic",
+	},
+	v1alpha3.RHDHIntegrationGitLab: {
+		host:             "gitlab.com",
+		tokenKey:         GitLabToken,
+		scaffolderModule: "backstage-plugin-scaffolder-backend-module-gitlab-dynamic",
+	},
+	v1alpha3.RHDHIntegrationBitbucketServer: {
+		tokenKey:         BitbucketServerToken,
+		scaffolderModule: "backstage-plugin-scaffolder-backend-module-bitbucket-server-dynamic",
+	},
+	v1alpha3.RHDHIntegrationBitbucketCloud: {
+		tokenKey:         BitbucketCloudAppPassword,
+		scaffolderModule: "backstage-plugin-scaffolder-backend-module-bitbucket-cloud-dynamic",
+	},
+	v1alpha3.RHDHIntegrationAzure: {
+		host:             "dev.azure.com",
+		tokenKey:         AzureToken,
+		scaffolderModule: "backstage-plugin-scaffolder-backend-module-azure-dynamic",
+	},
+}
+
+// RHDHIntegrationGroup holds the integrations of one SCM type, rendered as one list of the integrations block.
+type RHDHIntegrationGroup struct {
+	Type         v1alpha3.RHDHIntegrationType
+	Integrations []RHDHIntegrationConfig
+}
+
+type RHDHIntegrationConfig struct {
+	Host       string
+	APIBaseURL string
+	Token      string
+	Username   string
+}
+
+// defaultIntegrations are the integrations of RHDH when the spec sets none: github.com, and the GitLab host read from
+// the secret.
+var defaultIntegrations = []v1alpha3.RHDHIntegration{
+	{Type: v1alpha3.RHDHIntegrationGitHub},
+	{Type: v1alpha3.RHDHIntegrationGitLab, Host: "${" + GitLabHost + "}"},
+}
+
+func getIntegrations(rhdhConfig v1alpha3.RHDHConfig) []v1alpha3.RHDHIntegration {
+	if len(rhdhConfig.Integrations) == 0 {
+		return defaultIntegrations
+	}
+	return rhdhConfig.Integrations
+}
+
+// getIntegrationGroups returns the integrations of the spec with their defaults, grouped by type in the order of
+// their first occurrence.
+func getIntegrationGroups(rhdhConfig v1alpha3.RHDHConfig) []RHDHIntegrationGroup {
+	var groups []RHDHIntegrationGroup
+	for _, integration := range getIntegrations(rhdhConfig) {
+		index := slices.IndexFunc(groups, func(group RHDHIntegrationGroup) bool { return group.Type == integration.Type })
+		if index < 0 {
+			groups = append(groups, RHDHIntegrationGroup{Type: integration.Type})
+			index = len(groups) - 1
+		}
+		groups[index].Integrations = append(groups[index].Integrations, getIntegrationConfig(integration))
+	}
+	return groups
+}
+
+func getIntegrationConfig(integration v1alpha3.RHDHIntegration) RHDHIntegrationConfig {
+	defaults := integrationDefaults[integration.Type]
+	config := RHDHIntegrationConfig{
+		Host:       valueOrDefault(integration.Host, defaults.host),
+		APIBaseURL: integration.APIBaseURL,
+		Token:      valueOrDefault(integration.TokenKey, defaults.tokenKey),
+	}
+	if config.APIBaseURL == "" {
+		switch {
+		case integration.Type == v1alpha3.RHDHIntegrationGitHub && config.Host != defaults.host:
+			config.APIBaseURL = "https://" + config.Host + "/api/v3"
+		case integration.Type == v1alpha3.RHDHIntegrationGitLab:
+			config.APIBaseURL = "https://" + config.Host + "/api/v4"
+		case integration.Type == v1alpha3.RHDHIntegrationBitbucketServer:
+			config.APIBaseURL = "https://" + config.Host + "/rest/api/1.0"
+		}
+	}
+	if integration.Type == v1alpha3.RHDHIntegrationBitbucketCloud {
+		config.Username = valueOrDefault(integration.UsernameKey, BitbucketCloudUsername)
+	}
+	return config
+}
+
+// getScaffolderModules returns the scaffolder backend modules of the integration types, in the order of their first
+// occurrence.
+func getScaffolderModules(rhdhConfig v1alpha3.RHDHConfig) []string {
+	var modules []string
+	for _, group := range getIntegrationGroups(rhdhConfig) {
+		modules = append(modules, integrationDefaults[group.Type].scaffolderModule)
+	}
+	return modules
+}
@@ -5,6 +5,9 @@ type Plugin struct {
 	Integrity string
 }
 
+// PluginsVersion is the version of the orchestrator plugins installed in RHDH.
+const PluginsVersion = "1.5.0-rc.2"
+
 const Orchestrator string = "orchestrator"
 const OrchestratorBackend string = "orchestratorBackend"
 const ScaffolderBackendOrchestrator string = "scaffolderBackendOrchestrator"
@@ -12,15 +15,15 @@ const ScaffolderBackendOrchestrator string = "scaffolderBackendOrchestrator"
 func getPlugins() map[string]Plugin {
 	return map[string]Plugin{
 		Orchestrator: {
-			Package:   "backstage-plugin-orchestrator-1.5.0-rc.2.tgz",
+			Package:   "backstage-plugin-orchestrator-" + PluginsVersion + ".tgz",
 			Integrity: "sha512-k+oXawNBQa0TFskAoYvExWZ/EOJ9H4s2+y4ujE+RFzsu7rkm4YmElDIrVYMZhJLRqBhSoHgCdGyn7nSPW20rcg==",
 		},
 		OrchestratorBackend: {
-			Package:   "backstage-plugin-orchestrator-backend-dynamic-1.5.0-rc.2.tgz",
+			Package:   "backstage-plugin-orchestrator-backend-dynamic-" + PluginsVersion + ".tgz",
 			Integrity: "sha512-TmG54OazZLSuzPFmqQSi11koChBE+T8q0ZA7zVkSZZHZjkxvXy2fjqi4Vozz/2hYDUuXRXMJFJ806ijlsiwUsw==",
 		},
 		ScaffolderBackendOrchestrator: {
-			Package:   "backstage-plugin-scaffolder-backend-module-orchestrator-dynamic-1.5.0-rc.2.tgz",
+			Package:   "backstage-plugin-scaffolder-backend-module-orchestrator-dynamic-" + PluginsVersion + ".tgz",
 			Integrity: "sha512-vBosJHdFdgN1FaVjRRBdjQ41rSRBsAAlX+6eD0F2DAAgkjLfERp2SMNHhSV3q18QIGqxJ03KZeX7uPypyw+qVA==",
 		},
 	}
@@ -2,7 +2,7 @@ package rhdh
 
 const RHDHConfigTempl = `app:
   title: Red Hat Developer Hub
-  baseUrl: https://backstage-{{ .RHDHName }}-{{ .RHDHNamespace }}.{{ .ClusterDomain }}
+  baseUrl: {{ .BaseURL }}
 backend:
   auth:
     externalAccess:
@@ -10,13 +10,13 @@ backend:
         options:
           token: {{ printf "${%s}" .BackendSecret }}
           subject: orchestrator
-  baseUrl: https://backstage-{{ .RHDHName }}-{{ .RHDHNamespace }}.{{ .ClusterDomain }}
+  baseUrl: {{ .BaseURL }}
   csp:
     script-src: ["'self'", "'unsafe-inline'", "'unsafe-eval'"]
     script-src-elem: ["'self'", "'unsafe-inline'", "'unsafe-eval'"]
     connect-src: ["'self'", 'http:', 'https:', 'data:']
   cors:
-    origin: https://backstage-{{ .RHDHName }}-{{ .RHDHNamespace }}.{{ .ClusterDomain }}
+    origin: {{ .BaseURL }}
   database:
     client: pg
     connection:
@@ -44,5 +44,5 @@ type RHDHConfig struct {
 	ArgoCDUrl      string
 	ArgoCDEnabled  bool
 	BackendSecret  string
-	ClusterDomain  string
+	BaseURL        string
 }
@@ -1,41 +1,156 @@
 package rhdh
 
-const RHDHAuthTempl =`integrations:
-  {{- if and ( .GitHubToken) }}
-  github:
-    - host: github.com
-      token: {{ printf "${%s}" .GitHubToken }}
-  {{- end }}
-  {{- if and ( .GitLabToken) ( .GitLabHost) }}
-  gitlab:
-    - host: {{ printf "${%s}" .GitLabHost }}
-      token: {{ printf "${%s}" .GitLabToken }}
-      apiBaseUrl: https://{{ printf "${%s}" .GitLabHost }}/api/v4
+import (
+	"slices"
+	"strings"
+
+	"github.com/rhdhorchestrator/orchestrator-operator/api/v1alpha3"
+)
+
+const RHDHAuthTempl = `integrations:
+  {{- range $group := .IntegrationGroups }}
+  {{ $group.Type }}:
+    {{- range $group.Integrations }}
+    {{- if eq $group.Type "bitbucketCloud" }}
+    - username: {{ printf "${%s}" .Username }}
+      appPassword: {{ printf "${%s}" .Token }}
+    {{- else if eq $group.Type "azure" }}
+    - host: {{ .Host }}
+      credentials:
+        - personalAccessToken: {{ printf "${%s}" .Token }}
+    {{- else }}
+    - host: {{ .Host }}
+      token: {{ printf "${%s}" .Token }}
+      {{- if .APIBaseURL }}
+      apiBaseUrl: {{ .APIBaseURL }}
+      {{- end }}
+    {{- end }}
+    {{- end }}
   {{- end }}
-{{- if and ( .GitHubToken) }}
+{{- if .SignInPage }}
+signInPage: {{ .SignInPage }}
+{{- end }}
 auth:
   environment: {{ .Environment }}
-{{- end }}
+  {{- if .SessionSecret }}
+  session:
+    secret: {{ printf "${%s}" .SessionSecret }}
+  {{- end }}
   providers:
-    {{- if and ( .GitHubClientId) ( .GitHubClientSecret) }}
-    github:
-      development:
-        clientId: {{ printf "${%s}" .GitHubClientId }}
-        clientSecret: {{ printf "${%s}" .GitHubClientSecret }}
-    {{- end }}
-    {{- if .EnableGuestProvider }}
+    {{- range .Providers }}
+    {{- if eq .Type "guest" }}
     guest:
       dangerouslyAllowOutsideDevelopment: true
       userEntityRef: user:default/guest
+    {{- else }}
+    {{ .Type }}:
+      {{ $.Environment }}:
+        clientId: {{ printf "${%s}" .ClientID }}
+        clientSecret: {{ printf "${%s}" .ClientSecret }}
+        {{- if and (eq .Type "github") .URL }}
+        enterpriseInstanceUrl: {{ .URL }}
+        {{- end }}
+        {{- if eq .Type "gitlab" }}
+        audience: {{ .URL }}
+        {{- end }}
+        {{- if eq .Type "oidc" }}
+        metadataUrl: {{ .URL }}
+        prompt: auto
+        {{- end }}
+        {{- if eq .Type "microsoft" }}
+        tenantId: {{ printf "${%s}" .TenantID }}
+        {{- end }}
+        signIn:
+          resolvers:
+            {{- range .SignInResolvers }}
+            - resolver: {{ . }}
+            {{- end }}
+    {{- end }}
     {{- end }}
 `
 
+// defaultGitLabURL is the GitLab instance of the gitlab provider when no URL is set.
+const defaultGitLabURL = "https://gitlab.com"
+
+// DefaultSignInResolvers are the sign-in resolvers of the providers which do not set any.
+var DefaultSignInResolvers = map[v1alpha3.RHDHAuthProviderType][]string{
+	v1alpha3.RHDHAuthProviderGitHub:    {"usernameMatchingUserEntityName"},
+	v1alpha3.RHDHAuthProviderGitLab:    {"usernameMatchingUserEntityName"},
+	v1alpha3.RHDHAuthProviderOIDC:      {"emailMatchingUserEntityProfileEmail"},
+	v1alpha3.RHDHAuthProviderMicrosoft: {"emailMatchingUserEntityProfileEmail"},
+}
+
 type RHDHConfigAuth struct {
-	GitHubToken         string
-	Environment         string
-	GitHubClientId      string
-	GitHubClientSecret  string
-	GitLabHost          string
-	GitLabToken         string
-	EnableGuestProvider bool
+	IntegrationGroups []RHDHIntegrationGroup
+	Environment       string
+	SignInPage        string
+	SessionSecret     string
+	Providers         []RHDHAuthProviderConfig
+}
+
+type RHDHAuthProviderConfig struct {
+	Type            v1alpha3.RHDHAuthProviderType
+	ClientID        string
+	ClientSecret    string
+	URL             string
+	TenantID        string
+	SignInResolvers []string
+}
+
+// getAuthProviders returns the providers of the auth spec with their defaults: the GitHub provider when none is
+// set, and the guest provider in devMode.
+func getAuthProviders(rhdhConfig v1alpha3.RHDHConfig) []RHDHAuthProviderConfig {
+	providers := rhdhConfig.Auth.Providers
+	if len(providers) == 0 {
+		providers = []v1alpha3.RHDHAuthProvider{{Type: v1alpha3.RHDHAuthProviderGitHub}}
+	}
+	var configs []RHDHAuthProviderConfig
+	for _, provider := range providers {
+		configs = append(configs, getAuthProviderConfig(provider))
+	}
+	isGuest := func(provider v1alpha3.RHDHAuthProvider) bool { return provider.Type == v1alpha3.RHDHAuthProviderGuest }
+	if rhdhConfig.DevMode && !slices.ContainsFunc(providers, isGuest) {
+		configs = append(configs, RHDHAuthProviderConfig{Type: v1alpha3.RHDHAuthProviderGuest})
+	}
+	return configs
+}
+
+func getAuthProviderConfig(provider v1alpha3.RHDHAuthProvider) RHDHAuthProviderConfig {
+	if provider.Type == v1alpha3.RHDHAuthProviderGuest {
+		return RHDHAuthProviderConfig{Type: provider.Type}
+	}
+	prefix := strings.ToUpper(string(provider.Type))
+	config := RHDHAuthProviderConfig{
+		Type:            provider.Type,
+		ClientID:        valueOrDefault(provider.ClientIDKey, prefix+"_CLIENT_ID"),
+		ClientSecret:    valueOrDefault(provider.ClientSecretKey, prefix+"_CLIENT_SECRET"),
+		URL:             provider.URL,
+		SignInResolvers: provider.SignInResolvers,
+	}
+	if len(config.SignInResolvers) == 0 {
+		config.SignInResolvers = DefaultSignInResolvers[provider.Type]
+	}
+	switch provider.Type {
+	case v1alpha3.RHDHAuthProviderGitLab:
+		config.URL = valueOrDefault(provider.URL, defaultGitLabURL)
+	case v1alpha3.RHDHAuthProviderMicrosoft:
+		config.TenantID = valueOrDefault(provider.TenantIDKey, MicrosoftTenantID)
+	}
+	return config
+}
+
+// getAuthSessionSecret returns the secret key signing the auth sessions, which the oidc provider requires.
+func getAuthSessionSecret(auth v1alpha3.RHDHAuth, providers []RHDHAuthProviderConfig) string {
+	isOIDC := func(provider RHDHAuthProviderConfig) bool { return provider.Type == v1alpha3.RHDHAuthProviderOIDC }
+	if slices.ContainsFunc(providers, isOIDC) {
+		return valueOrDefault(auth.SessionSecretKey, AuthSessionSecretKey)
+	}
+	return ""
+}
+
+func valueOrDefault(value, defaultValue string) string {
+	if value == "" {
+		return defaultValue
+	}
+	return value
 }
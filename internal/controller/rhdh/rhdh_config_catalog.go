@@ -15,6 +15,12 @@ const RHDHCatalogTempl = `catalog:
           Domain,
         ]
   locations:
+    {{- if .CatalogLocations }}
+    {{- range .CatalogLocations }}
+    - type: url
+      target: {{ . }}
+    {{- end }}
+    {{- else }}
     {{- if .EnableGuestProvider }}
     - type: url
       target: https://github.com/rhdhorchestrator/orchestrator-helm-chart/blob/main/resources/users.yaml
@@ -35,9 +41,11 @@ const RHDHCatalogTempl = `catalog:
       target: https://github.com/rhdhorchestrator/workflow-software-templates/blob/{{ .CatalogBranch }}/scaffolder-templates/gitlab-workflows/convert-workflow-to-template/template.yaml
     - type: url
       target: https://github.com/rhdhorchestrator/workflow-software-templates/blob/{{ .CatalogBranch }}/scaffolder-templates/github-workflows/convert-workflow-to-template/template.yaml
+    {{- end }}
 `
 
 type RHDHConfigCatalog struct {
 	EnableGuestProvider bool
 	CatalogBranch       string
+	CatalogLocations    []string
 }
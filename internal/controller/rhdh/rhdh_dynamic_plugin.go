@@ -21,8 +21,10 @@ type RHDHDynamicPluginConfig struct {
 	NotificationEmailReplyTo               string
 	NotificationEmailPort                  int
 	WorkflowNamespace                      string
+	AdditionalWorkflowNamespaces           []string
 	ScaffolderBackendOrchestratorPackage   string
 	ScaffolderBackendOrchestratorIntegrity string
+	ScaffolderBackendModules               []string
 }
 
 const RHDHDynamicPluginTempl = `includes:
@@ -78,6 +80,13 @@ plugins:
       orchestrator:
         dataIndexService:
           url: http://sonataflow-platform-data-index-service.{{ .WorkflowNamespace }}
+        {{- if .AdditionalWorkflowNamespaces }}
+        workflowNamespaces:
+          - {{ .WorkflowNamespace }}
+          {{- range .AdditionalWorkflowNamespaces }}
+          - {{ . }}
+          {{- end }}
+        {{- end }}
   - package: "{{ .Scope }}/{{ .OrchestratorPackage }}"
     disabled: false
     integrity: {{ .OrchestratorIntegrity }}
@@ -128,10 +137,10 @@ plugins:
     disabled: false
   - package: ./dynamic-plugins/dist/backstage-plugin-signals-backend-dynamic
     disabled: false
-  - package: ./dynamic-plugins/dist/backstage-plugin-scaffolder-backend-module-github-dynamic
-    disabled: false
-  - package: ./dynamic-plugins/dist/backstage-plugin-scaffolder-backend-module-gitlab-dynamic
+  {{- range .ScaffolderBackendModules }}
+  - package: ./dynamic-plugins/dist/{{ . }}
     disabled: false
+  {{- end }}
   {{- if and (.NotificationEmailEnabled) (.NotificationEmailHostname) }}
   - package: ./dynamic-plugins/dist/backstage-plugin-notifications-backend-module-email-dynamic
     disabled: false
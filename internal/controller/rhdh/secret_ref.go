@@ -1,20 +1,26 @@
 package rhdh
 
 const (
-	BackendAuthSecretName = "backstage-backend-auth-secret"
-	BackendSecretKey      = "BACKEND_SECRET"
-	GitHubToken           = "GITHUB_TOKEN"
-	GitHubClientID        = "GITHUB_CLIENT_ID"
-	GitHubClientSecret    = "GITHUB_CLIENT_SECRET"
-	ClusterUrl            = "K8S_CLUSTER_TOKEN"
-	ClusterToken          = "K8S_CLUSTER_URL"
-	ArgoCDUrl             = "ARGOCD_URL"
-	ArgoCDUsername        = "ARGOCD_USERNAME"
-	ArgoCDPassword        = "ARGOCD_PASSWORD"
-	NotificationHostname  = "NOTIFICATIONS_EMAIL_HOSTNAME"
-	NotificationUsername  = "NOTIFICATIONS_EMAIL_USERNAME"
-	NotificationPassword  = "NOTIFICATIONS_EMAIL_PASSWORD"
-	RegistrySecretName    = "dynamic-plugins-npmrc"
-	GitLabHost            = "GITLAB_HOST"
-	GitLabToken           = "GITLAB_TOKEN"
+	BackendAuthSecretName     = "backstage-backend-auth-secret"
+	BackendSecretKey          = "BACKEND_SECRET"
+	AuthSessionSecretKey      = "AUTH_SESSION_SECRET"
+	GitHubToken               = "GITHUB_TOKEN"
+	GitHubClientID            = "GITHUB_CLIENT_ID"
+	GitHubClientSecret        = "GITHUB_CLIENT_SECRET"
+	MicrosoftTenantID         = "MICROSOFT_TENANT_ID"
+	ClusterUrl                = "K8S_CLUSTER_TOKEN"
+	ClusterToken              = "K8S_CLUSTER_URL"
+	ArgoCDUrl                 = "ARGOCD_URL"
+	ArgoCDUsername            = "ARGOCD_USERNAME"
+	ArgoCDPassword            = "ARGOCD_PASSWORD"
+	NotificationHostname      = "NOTIFICATIONS_EMAIL_HOSTNAME"
+	NotificationUsername      = "NOTIFICATIONS_EMAIL_USERNAME"
+	NotificationPassword      = "NOTIFICATIONS_EMAIL_PASSWORD"
+	RegistrySecretName        = "dynamic-plugins-npmrc"
+	GitLabHost                = "GITLAB_HOST"
+	GitLabToken               = "GITLAB_TOKEN"
+	BitbucketServerToken      = "BITBUCKET_SERVER_TOKEN"
+	BitbucketCloudUsername    = "BITBUCKET_CLOUD_USERNAME"
+	BitbucketCloudAppPassword = "BITBUCKET_CLOUD_APP_PASSWORD"
+	AzureToken                = "AZURE_TOKEN"
 )
@@ -16,6 +16,7 @@ package controller
 
 import (
 	"context"
+	"fmt"
 	"reflect"
 
 	sonataapi "github.com/apache/incubator-kie-tools/packages/sonataflow-operator/api/v1alpha08"
@@ -24,10 +25,12 @@ import (
 	"github.com/rhdhorchestrator/orchestrator-operator/internal/controller/kube"
 	"github.com/rhdhorchestrator/orchestrator-operator/internal/controller/util"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	duckv1 "knative.dev/pkg/apis/duck/v1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/log"
@@ -50,8 +53,20 @@ const (
 	sonataFlowPlatformReference            = "sonataflow-platform"
 )
 
+// serverlessLogicOperatorDetection finds the Serverless Logic operator installed without the Subscription of the
+// Orchestrator.
+var serverlessLogicOperatorDetection = kube.OperatorDetection{
+	Name:                  "ServerlessLogic",
+	CRDName:               sonataFlowClusterPlatformCRDName,
+	SubscriptionName:      serverlessLogicSubscriptionName,
+	SubscriptionNamespace: serverlessLogicOperatorNamespace,
+}
+
 // handleServerlessLogicOperatorInstallation performs operator installation for the OSL operand
-func handleServerlessLogicOperatorInstallation(ctx context.Context, client client.Client, olmClientSet olmclientset.Interface) error {
+func handleServerlessLogicOperatorInstallation(
+	ctx context.Context, client *kube.InventoryClient, recorder record.EventRecorder,
+	orchestrator *orchestratorv1alpha2.Orchestrator, olmClientSet olmclientset.Interface,
+	subscriptionConfig orchestratorv1alpha2.SubscriptionConfig, installPlanPolicy kube.InstallPlanPolicy) error {
 	sfLogger := log.FromContext(ctx)
 
 	// create namespace for operator
@@ -71,7 +86,8 @@ func handleServerlessLogicOperatorInstallation(ctx context.Context, client clien
 		serverlessLogicSubscriptionName,
 		serverlessLogicOperatorNamespace,
 		serverlessLogicSubscriptionChannel,
-		serverlessLogicSubscriptionStartingCSV)
+		serverlessLogicSubscriptionStartingCSV,
+		subscriptionConfig)
 
 	subscriptionExists, existingSubscription, err := kube.CheckSubscriptionExists(ctx, olmClientSet, oslSubscription)
 	if err != nil {
@@ -89,6 +105,11 @@ func handleServerlessLogicOperatorInstallation(ctx context.Context, client clien
 		}
 		sfLogger.Info("Operator successfully installed via Subscription", "SubscriptionName", serverlessLogicSubscriptionName)
 	} else {
+		// the subscriptions created by earlier releases are not in the inventory yet
+		if err := client.Adopt(ctx, existingSubscription); err != nil {
+			sfLogger.Error(err, "Error occurred when adopting subscription", "SubscriptionName", serverlessLogicSubscriptionName)
+			return err
+		}
 		// Compare the current and desired state
 		if !reflect.DeepEqual(existingSubscription.Spec, oslSubscription.Spec) {
 			// Update the existing subscription with the desired spec
@@ -98,22 +119,28 @@ func handleServerlessLogicOperatorInstallation(ctx context.Context, client clien
 				return err
 			}
 			sfLogger.Info("Successfully updated updating subscription spec", "SubscriptionName", serverlessLogicSubscriptionName)
+			recorder.Eventf(orchestrator, corev1.EventTypeNormal, "SubscriptionUpdated",
+				"Updated Subscription %s/%s to match the Orchestrator spec", serverlessLogicOperatorNamespace, serverlessLogicSubscriptionName)
 		}
 	}
 
 	// approve install plan
-	if existingSubscription.Status.InstallPlanRef != nil && existingSubscription.Status.CurrentCSV == serverlessLogicSubscriptionStartingCSV {
+	if existingSubscription.Status.InstallPlanRef != nil && existingSubscription.Status.CurrentCSV == oslSubscription.Spec.StartingCSV {
 		installPlanName := existingSubscription.Status.InstallPlanRef.Name
-		if err := kube.ApproveInstallPlan(client, ctx, installPlanName, existingSubscription.Namespace); err != nil {
+		if err := kube.ApproveInstallPlan(client, ctx, recorder, orchestrator, installPlanName, existingSubscription.Namespace, installPlanPolicy); err != nil {
 			sfLogger.Error(err, "Error occurred while approving install plan for subscription", "SubscriptionName", installPlanName)
 			return err
 		}
 	}
-	return nil
+
+	// the operands are reconciled once the operator is installed; until then the reconciliation is retried
+	return kube.CheckCSVSucceeded(ctx, olmClientSet, oslSubscription)
 }
 
 // handleServerlessLogicCR performs the creation of serverless logic namespace and CRs
-func handleServerlessLogicCR(ctx context.Context, client client.Client, orchestrator *orchestratorv1alpha2.Orchestrator) error {
+func handleServerlessLogicCR(
+	ctx context.Context, client client.Client, recorder record.EventRecorder,
+	orchestrator *orchestratorv1alpha2.Orchestrator) error {
 	sfLogger := log.FromContext(ctx)
 	sfLogger.Info("Handling ServerlessLogic CR...")
 	serverlessWorkflowNamespace := orchestrator.Spec.PlatformConfig.Namespace
@@ -127,13 +154,13 @@ func handleServerlessLogicCR(ctx context.Context, client client.Client, orchestr
 		return err
 	}
 
-	if err := handleSonataFlowClusterCR(ctx, client, sonataFlowClusterPlatformCRName, serverlessWorkflowNamespace); err != nil {
+	if err := handleSonataFlowClusterCR(ctx, client, recorder, orchestrator, sonataFlowClusterPlatformCRName, serverlessWorkflowNamespace); err != nil {
 		sfLogger.Error(err, "Error occurred when creating SonataFlowClusterCR", "CR-Name", sonataFlowClusterPlatformCRName)
 		return err
 
 	}
 	// create sonataflowplatform  CR
-	if err := handleSonataFlowPlatformCR(ctx, client, orchestrator, sonataFlowClusterPlatformCRName, serverlessWorkflowNamespace); err != nil {
+	if err := handleSonataFlowPlatformCR(ctx, client, recorder, orchestrator, sonataFlowClusterPlatformCRName, serverlessWorkflowNamespace); err != nil {
 		sfLogger.Error(err, "Error occurred when creating SonataFlowPlatform", "CR-Name", sonataFlowClusterPlatformCRName)
 		return err
 	}
@@ -159,14 +186,16 @@ func getServerlessLogicPersistence(orchestrator *orchestratorv1alpha2.Orchestrat
 	}
 }
 
-func handleSonataFlowClusterCR(ctx context.Context, client client.Client, crName, namespace string) error {
+func handleSonataFlowClusterCR(
+	ctx context.Context, k8client client.Client, recorder record.EventRecorder,
+	orchestrator *orchestratorv1alpha2.Orchestrator, crName, namespace string) error {
 	logger := log.FromContext(ctx)
 	logger.Info("Starting CR creation for SonataFlowCluster...")
 
 	// check sonataflowlusterplatform CR exists
 	sfcCR := &sonataapi.SonataFlowClusterPlatform{}
 
-	err := client.Get(ctx, types.NamespacedName{Name: crName, Namespace: namespace}, sfcCR)
+	err := k8client.Get(ctx, types.NamespacedName{Name: crName, Namespace: namespace}, sfcCR)
 	if err != nil {
 		if apierrors.IsNotFound(err) {
 			// Create sonataflowcluster CR object
@@ -183,7 +212,7 @@ func handleSonataFlowClusterCR(ctx context.Context, client client.Client, crName
 			}
 
 			// Create sonataflowcluster CR
-			if err := client.Create(ctx, sonataFlowClusterCR); err != nil {
+			if err := k8client.Create(ctx, sonataFlowClusterCR); err != nil {
 				logger.Error(err, "Error occurred when creating Custom Resource", "CR-Name", crName)
 				return err
 			}
@@ -193,6 +222,20 @@ func handleSonataFlowClusterCR(ctx context.Context, client client.Client, crName
 		logger.Error(err, "Error occurred when retrieving SonataFlowClusterPlatform CR", "CR-Name", crName)
 		return err
 	}
+
+	// the platform reference is owned by the orchestrator; capabilities are left to the user
+	originalCR := sfcCR.DeepCopy()
+	sfcCR.Spec.PlatformRef = getSonataFlowClusterSpec(namespace).PlatformRef
+	if equality.Semantic.DeepEqual(originalCR.Spec, sfcCR.Spec) {
+		return nil
+	}
+	if err := k8client.Patch(ctx, sfcCR, client.MergeFrom(originalCR)); err != nil {
+		logger.Error(err, "Error occurred when updating SonataFlowClusterPlatform CR", "CR-Name", crName)
+		return err
+	}
+	logger.Info("Successfully updated SonataFlowClusterPlatform resource", "CR-Name", crName)
+	recorder.Eventf(orchestrator, corev1.EventTypeNormal, "SonataFlowClusterPlatformUpdated",
+		"Updated SonataFlowClusterPlatform %s to match the Orchestrator spec", crName)
 	return nil
 }
 
@@ -206,14 +249,14 @@ func getSonataFlowClusterSpec(namespace string) sonataapi.SonataFlowClusterPlatf
 }
 
 func handleSonataFlowPlatformCR(
-	ctx context.Context, client client.Client,
+	ctx context.Context, k8client client.Client, recorder record.EventRecorder,
 	orchestrator *orchestratorv1alpha2.Orchestrator, crName, namespace string) error {
 	logger := log.FromContext(ctx)
 
 	logger.Info("Starting CR creation for SonataFlowPlatform...")
 
 	sfpCR := &sonataapi.SonataFlowPlatform{}
-	err := client.Get(ctx, types.NamespacedName{
+	err := k8client.Get(ctx, types.NamespacedName{
 		Namespace: namespace,
 		Name:      sonataFlowPlatformCRName,
 	}, sfpCR)
@@ -221,6 +264,10 @@ func handleSonataFlowPlatformCR(
 	if err != nil {
 		if apierrors.IsNotFound(err) {
 			logger.Info("SonataFlowPlatform not found. Proceed to creating CR...")
+			platformSpec, err := getSonataFlowPlatformSpec(ctx, orchestrator)
+			if err != nil {
+				return err
+			}
 
 			// Create sonataflow platform CR object
 			sonataFlowPlatformCR := &sonataapi.SonataFlowPlatform{
@@ -233,11 +280,11 @@ func handleSonataFlowPlatformCR(
 					Namespace: namespace,
 					Labels:    kube.AddLabel(),
 				},
-				Spec: getSonataFlowPlatformSpec(ctx, orchestrator),
+				Spec: platformSpec,
 			}
 			logger.Info("Persistence function", "Persistent", getServerlessLogicPersistence(orchestrator))
 			// Create sonataflowplatform CR
-			if err := client.Create(ctx, sonataFlowPlatformCR); err != nil {
+			if err := k8client.Create(ctx, sonataFlowPlatformCR); err != nil {
 				logger.Error(err, "Failed to create Custom Resource", "CR-Name", crName)
 				return err
 			}
@@ -247,22 +294,57 @@ func handleSonataFlowPlatformCR(
 		logger.Error(err, "Error occurred when retrieving SonataFlowPlatform CR", "CR-Name", crName)
 		return err
 	}
+
+	platformSpec, err := getSonataFlowPlatformSpec(ctx, orchestrator)
+	if err != nil {
+		return err
+	}
+	originalCR := sfpCR.DeepCopy()
+	applySonataFlowPlatformSpec(&sfpCR.Spec, platformSpec)
+	if equality.Semantic.DeepEqual(originalCR.Spec, sfpCR.Spec) {
+		return nil
+	}
+	if err := k8client.Patch(ctx, sfpCR, client.MergeFrom(originalCR)); err != nil {
+		logger.Error(err, "Error occurred when updating SonataFlowPlatform CR", "CR-Name", sonataFlowPlatformCRName)
+		return err
+	}
+	logger.Info("Successfully updated SonataFlowPlatform CR", "CR-Name", sonataFlowPlatformCRName)
+	recorder.Eventf(orchestrator, corev1.EventTypeNormal, "SonataFlowPlatformUpdated",
+		"Updated SonataFlowPlatform %s/%s to match the Orchestrator spec", namespace, sonataFlowPlatformCRName)
 	return nil
 }
 
-func getSonataFlowPlatformSpec(ctx context.Context, orchestrator *orchestratorv1alpha2.Orchestrator) sonataapi.SonataFlowPlatformSpec {
-	limitResourceMap := make(map[corev1.ResourceName]resource.Quantity)
+// applySonataFlowPlatformSpec copies the fields owned by the orchestrator from the desired spec to the live spec.
+// Fields set by the SonataFlow operator or by users, such as the build config or the pod templates, are preserved.
+func applySonataFlowPlatformSpec(live *sonataapi.SonataFlowPlatformSpec, desired sonataapi.SonataFlowPlatformSpec) {
+	live.Build.Template.Resources = desired.Build.Template.Resources
+	live.Monitoring = desired.Monitoring
+	live.Eventing = desired.Eventing
 
-	cpuQuantity, _ := resource.ParseQuantity(orchestrator.Spec.PlatformConfig.Resources.Limits.Cpu)
-	memoryQuantity, _ := resource.ParseQuantity(orchestrator.Spec.PlatformConfig.Resources.Limits.Memory)
-	limitResourceMap[corev1.ResourceCPU] = cpuQuantity
-	limitResourceMap[corev1.ResourceMemory] = memoryQuantity
+	if live.Services == nil {
+		live.Services = &sonataapi.ServicesPlatformSpec{}
+	}
+	if live.Services.DataIndex == nil {
+		live.Services.DataIndex = &sonataapi.DataIndexServiceSpec{}
+	}
+	live.Services.DataIndex.Enabled = desired.Services.DataIndex.Enabled
+	live.Services.DataIndex.Persistence = desired.Services.DataIndex.Persistence
+	if live.Services.JobService == nil {
+		live.Services.JobService = &sonataapi.JobServiceServiceSpec{}
+	}
+	live.Services.JobService.Enabled = desired.Services.JobService.Enabled
+	live.Services.JobService.Persistence = desired.Services.JobService.Persistence
+}
 
-	requestResourceMap := make(map[corev1.ResourceName]resource.Quantity)
-	requestCpuQuantity, _ := resource.ParseQuantity(orchestrator.Spec.PlatformConfig.Resources.Requests.Cpu)
-	requestMemoryQuantity, _ := resource.ParseQuantity(orchestrator.Spec.PlatformConfig.Resources.Requests.Memory)
-	requestResourceMap[corev1.ResourceCPU] = requestCpuQuantity
-	requestResourceMap[corev1.ResourceMemory] = requestMemoryQuantity
+func getSonataFlowPlatformSpec(ctx context.Context, orchestrator *orchestratorv1alpha2.Orchestrator) (sonataapi.SonataFlowPlatformSpec, error) {
+	limitResourceMap, err := getResourceList(orchestrator.Spec.PlatformConfig.Resources.Limits)
+	if err != nil {
+		return sonataapi.SonataFlowPlatformSpec{}, fmt.Errorf("invalid platform resource limits: %w", err)
+	}
+	requestResourceMap, err := getResourceList(orchestrator.Spec.PlatformConfig.Resources.Requests)
+	if err != nil {
+		return sonataapi.SonataFlowPlatformSpec{}, fmt.Errorf("invalid platform resource requests: %w", err)
+	}
 
 	return sonataapi.SonataFlowPlatformSpec{
 		Build: sonataapi.BuildPlatformSpec{
@@ -290,21 +372,45 @@ func getSonataFlowPlatformSpec(ctx context.Context, orchestrator *orchestratorv1
 			},
 		},
 		Eventing: createEventingSpec(ctx, orchestrator),
+	}, nil
+}
+
+// getResourceList parses the set quantities of memoryCpu. It returns nil when none is set.
+func getResourceList(memoryCpu orchestratorv1alpha2.MemoryCpu) (corev1.ResourceList, error) {
+	var resourceList corev1.ResourceList
+	for name, value := range map[corev1.ResourceName]string{
+		corev1.ResourceCPU:    memoryCpu.Cpu,
+		corev1.ResourceMemory: memoryCpu.Memory,
+	} {
+		if value == "" {
+			continue
+		}
+		quantity, err := resource.ParseQuantity(value)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", name, err)
+		}
+		if resourceList == nil {
+			resourceList = corev1.ResourceList{}
+		}
+		resourceList[name] = quantity
 	}
+	return resourceList, nil
 }
 
-func handleServerlessLogicCleanUp(ctx context.Context, client client.Client, namespace string) error {
+func handleServerlessLogicCleanUp(ctx context.Context, inventory *kube.InventoryClient, workflowNamespaces []string) error {
 	logger := log.FromContext(ctx)
 	logger.Info("Starting Clean Up for Serverless Logic ...")
 
-	// remove operand namespace
-	if err := kube.CleanUpNamespace(ctx, namespace, client); err != nil {
-		logger.Error(err, "Error occurred when deleting namespace", "NS", namespace)
-		return err
+	// remove operand namespaces
+	for _, namespace := range workflowNamespaces {
+		if err := kube.CleanUpNamespace(ctx, namespace, inventory); err != nil {
+			logger.Error(err, "Error occurred when deleting namespace", "NS", namespace)
+			return err
+		}
 	}
 
 	// remove operator namespace
-	if err := kube.CleanUpNamespace(ctx, serverlessLogicOperatorNamespace, client); err != nil {
+	if err := kube.CleanUpNamespace(ctx, serverlessLogicOperatorNamespace, inventory); err != nil {
 		logger.Error(err, "Error occurred when deleting namespace", "NS", serverlessLogicOperatorNamespace)
 		return err
 	}
@@ -315,7 +421,8 @@ func createEventingSpec(ctx context.Context, orchestrator *orchestratorv1alpha2.
 	sfLogger := log.FromContext(ctx)
 
 	// Check if Broker is empty
-	if reflect.ValueOf(orchestrator.Spec.PlatformConfig.Eventing.Broker).IsZero() {
+	broker := orchestrator.Spec.PlatformConfig.Eventing.Broker
+	if broker.Name == "" || broker.Namespace == "" {
 		sfLogger.Info("No existing eventing broker")
 		return &sonataapi.PlatformEventingSpec{}
 	}
@@ -0,0 +1,123 @@
+package controller
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	sonataapi "github.com/apache/incubator-kie-tools/packages/sonataflow-operator/api/v1alpha08"
+	orchestratorv1alpha2 "github.com/rhdhorchestrator/orchestrator-operator/api/v1alpha3"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func newTestSonataFlowOrchestrator() *orchestratorv1alpha2.Orchestrator {
+	orchestrator := newTestOrchestrator()
+	orchestrator.Spec.PostgresConfig = orchestratorv1alpha2.PostgresConfig{
+		Name:      "sonataflow-psql-postgresql",
+		Namespace: testNamespace,
+		AuthSecret: orchestratorv1alpha2.PostgresAuthSecret{
+			SecretName:  "sonataflow-psql-postgresql",
+			UserKey:     "postgres-username",
+			PasswordKey: "postgres-password",
+		},
+		DatabaseName: "sonataflow",
+	}
+	orchestrator.Spec.PlatformConfig.Resources = orchestratorv1alpha2.Resource{
+		Requests: orchestratorv1alpha2.MemoryCpu{Memory: "64Mi", Cpu: "250m"},
+		Limits:   orchestratorv1alpha2.MemoryCpu{Memory: "1Gi", Cpu: "500m"},
+	}
+	return orchestrator
+}
+
+func TestHandleSonataFlowPlatformCRUpdate(t *testing.T) {
+	ctx := context.TODO()
+	scheme := runtime.NewScheme()
+	utilruntime.Must(sonataapi.AddToScheme(scheme))
+
+	orchestrator := newTestSonataFlowOrchestrator()
+	liveSpec, err := getSonataFlowPlatformSpec(ctx, orchestrator)
+	assert.NoError(t, err)
+	// fields set by other actors
+	liveSpec.Build.Config.BaseImage = "registry.example.com/builder:latest"
+	liveSpec.Services.DataIndex.PodTemplate.Replicas = new(int32)
+
+	livePlatform := &sonataapi.SonataFlowPlatform{
+		ObjectMeta: metav1.ObjectMeta{Name: sonataFlowPlatformCRName, Namespace: testNamespace},
+		Spec:       liveSpec,
+	}
+
+	testCases := []struct {
+		name          string
+		mutate        func(*orchestratorv1alpha2.Orchestrator)
+		expectedEvent bool
+	}{
+		{
+			name:          "Unchanged spec is not patched",
+			mutate:        func(o *orchestratorv1alpha2.Orchestrator) {},
+			expectedEvent: false,
+		},
+		{
+			name: "Changed spec is patched",
+			mutate: func(o *orchestratorv1alpha2.Orchestrator) {
+				o.Spec.PlatformConfig.Monitoring.Enabled = true
+				o.Spec.PlatformConfig.Resources.Limits.Memory = "2Gi"
+				o.Spec.PlatformConfig.Eventing.Broker = orchestratorv1alpha2.Broker{Name: "my-broker", Namespace: "knative"}
+			},
+			expectedEvent: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(livePlatform.DeepCopy()).Build()
+			recorder := record.NewFakeRecorder(10)
+			orchestrator := newTestSonataFlowOrchestrator()
+			tc.mutate(orchestrator)
+
+			err := handleSonataFlowPlatformCR(ctx, fakeClient, recorder, orchestrator, sonataFlowClusterPlatformCRName, testNamespace)
+			assert.NoError(t, err)
+
+			updated := &sonataapi.SonataFlowPlatform{}
+			assert.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: sonataFlowPlatformCRName, Namespace: testNamespace}, updated))
+			assert.Equal(t, orchestrator.Spec.PlatformConfig.Monitoring.Enabled, updated.Spec.Monitoring.Enabled)
+			memoryLimit := updated.Spec.Build.Template.Resources.Limits[corev1.ResourceMemory]
+			assert.Equal(t, orchestrator.Spec.PlatformConfig.Resources.Limits.Memory, memoryLimit.String())
+			assert.Equal(t, "registry.example.com/builder:latest", updated.Spec.Build.Config.BaseImage)
+			assert.NotNil(t, updated.Spec.Services.DataIndex.PodTemplate.Replicas)
+
+			if tc.expectedEvent {
+				assert.Len(t, recorder.Events, 1)
+				assert.True(t, strings.Contains(<-recorder.Events, "SonataFlowPlatformUpdated"))
+				assert.Equal(t, "my-broker", updated.Spec.Eventing.Broker.Ref.Name)
+			} else {
+				assert.Len(t, recorder.Events, 0)
+			}
+		})
+	}
+}
+
+func TestGetSonataFlowPlatformSpecResources(t *testing.T) {
+	ctx := context.TODO()
+
+	orchestrator := newTestSonataFlowOrchestrator()
+	orchestrator.Spec.PlatformConfig.Resources = orchestratorv1alpha2.Resource{
+		Limits: orchestratorv1alpha2.MemoryCpu{Memory: "1Gi"},
+	}
+	platformSpec, err := getSonataFlowPlatformSpec(ctx, orchestrator)
+	assert.NoError(t, err)
+	assert.Nil(t, platformSpec.Build.Template.Resources.Requests, "Unset requests are left to the SonataFlow operator")
+	assert.Equal(t, corev1.ResourceList{corev1.ResourceMemory: resource.MustParse("1Gi")},
+		platformSpec.Build.Template.Resources.Limits)
+
+	orchestrator.Spec.PlatformConfig.Resources.Requests.Cpu = "half"
+	_, err = getSonataFlowPlatformSpec(ctx, orchestrator)
+	assert.ErrorContains(t, err, "invalid platform resource requests")
+}
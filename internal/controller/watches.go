@@ -0,0 +1,182 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	sonataapi "github.com/apache/incubator-kie-tools/packages/sonataflow-operator/api/v1alpha08"
+	argocdv1alpha1 "github.com/argoproj/argo-cd/v2/pkg/apis/application/v1alpha1"
+	orchestratorv1alpha2 "github.com/rhdhorchestrator/orchestrator-operator/api/v1alpha3"
+	"github.com/rhdhorchestrator/orchestrator-operator/internal/controller/kube"
+	"github.com/rhdhorchestrator/orchestrator-operator/internal/controller/rhdh"
+	tektonv1 "github.com/tektoncd/pipeline/pkg/apis/pipeline/v1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	knative "knative.dev/operator/pkg/apis/operator/v1beta1"
+	rhdhv1alpha3 "redhat-developer/red-hat-developer-hub-operator/api/v1alpha3"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// optionalWatch describes a watch on a type provided by an optional CRD, started once the CRD is established.
+type optionalWatch struct {
+	crdName   string
+	newObject func() client.Object
+	// statusChanges enqueues the Orchestrators on status updates, for the objects whose readiness is awaited
+	statusChanges bool
+}
+
+var optionalWatches = []optionalWatch{
+	{crdName: sonataFlowClusterPlatformCRDName, newObject: func() client.Object { return &sonataapi.SonataFlowClusterPlatform{} }},
+	{crdName: "sonataflowplatforms.sonataflow.org", newObject: func() client.Object { return &sonataapi.SonataFlowPlatform{} }, statusChanges: true},
+	{crdName: knativeServingCRDName, newObject: func() client.Object { return &knative.KnativeServing{} }, statusChanges: true},
+	{crdName: knativeEventingCRDName, newObject: func() client.Object { return &knative.KnativeEventing{} }, statusChanges: true},
+	{crdName: knativeBrokerCRDName, newObject: newWatchedBrokerObject, statusChanges: true},
+	{crdName: "backstages.rhdh.redhat.com", newObject: func() client.Object { return &rhdhv1alpha3.Backstage{} }, statusChanges: true},
+	{crdName: rhdh.HTTPRouteCRDName, newObject: newWatchedHTTPRouteObject},
+	{crdName: "tasks.tekton.dev", newObject: func() client.Object { return &tektonv1.Task{} }},
+	{crdName: "pipelines.tekton.dev", newObject: func() client.Object { return &tektonv1.Pipeline{} }},
+	{crdName: "appprojects.argoproj.io", newObject: func() client.Object { return &argocdv1alpha1.AppProject{} }},
+}
+
+func newWatchedBrokerObject() client.Object {
+	broker := &unstructured.Unstructured{}
+	broker.SetAPIVersion(knativeBrokerAPIVersion)
+	broker.SetKind(knativeBrokerKind)
+	return broker
+}
+
+func newWatchedHTTPRouteObject() client.Object {
+	return rhdh.NewHTTPRouteObject()
+}
+
+// CacheOptions restricts the cache of the core types watched by the controller to the objects created by the
+// operator, instead of caching every object of these types in the cluster. The objects of these types which the
+// operator does not create, such as the Service of an external database, are read with the APIReader.
+func CacheOptions() cache.Options {
+	createdBy := cache.ByObject{Label: labels.SelectorFromSet(kube.AddLabel())}
+	return cache.Options{
+		ByObject: map[client.Object]cache.ByObject{
+			&corev1.ConfigMap{}:           createdBy,
+			&corev1.Service{}:             createdBy,
+			&appsv1.StatefulSet{}:         createdBy,
+			&networkingv1.NetworkPolicy{}: createdBy,
+			&networkingv1.Ingress{}:       createdBy,
+		},
+	}
+}
+
+// createdByPredicate filters the events of the objects created by the operator.
+var createdByPredicate = predicate.NewPredicateFuncs(func(object client.Object) bool {
+	return kube.CheckLabelExist(object.GetLabels())
+})
+
+// specChangedPredicate ignores the status updates of objects reconciled by other operators.
+var specChangedPredicate = predicate.Or[client.Object](
+	predicate.GenerationChangedPredicate{}, predicate.LabelChangedPredicate{}, predicate.AnnotationChangedPredicate{})
+
+// getOptionalWatch returns the watch started by the establishment of the CRD.
+func getOptionalWatch(crd *apiextensionsv1.CustomResourceDefinition) (optionalWatch, bool) {
+	for _, watch := range optionalWatches {
+		if watch.crdName == crd.Name {
+			return watch, true
+		}
+	}
+	return optionalWatch{}, false
+}
+
+func isCRDEstablished(crd *apiextensionsv1.CustomResourceDefinition) bool {
+	for _, condition := range crd.Status.Conditions {
+		if condition.Type == apiextensionsv1.Established {
+			return condition.Status == apiextensionsv1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// optionalCRDPredicate filters the events of the CRDs providing the optional watched types.
+var optionalCRDPredicate = predicate.NewPredicateFuncs(func(object client.Object) bool {
+	crd, ok := object.(*apiextensionsv1.CustomResourceDefinition)
+	if !ok {
+		return false
+	}
+	_, found := getOptionalWatch(crd)
+	return found
+})
+
+// mapToOrchestrators enqueues the Orchestrator the object was created for, from its owner annotation. The objects
+// without the annotation, such as the CRDs, enqueue every Orchestrator.
+func (r *OrchestratorReconciler) mapToOrchestrators(ctx context.Context, object client.Object) []reconcile.Request {
+	if owner, found := kube.GetOwner(object); found {
+		return []reconcile.Request{{NamespacedName: owner}}
+	}
+	logger := log.FromContext(ctx)
+	orchestratorList := &orchestratorv1alpha2.OrchestratorList{}
+	if err := r.List(ctx, orchestratorList); err != nil {
+		logger.Error(err, "Error occurred when listing Orchestrator resources")
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(orchestratorList.Items))
+	for _, orchestrator := range orchestratorList.Items {
+		requests = append(requests, reconcile.Request{
+			NamespacedName: types.NamespacedName{Name: orchestrator.Name, Namespace: orchestrator.Namespace}})
+	}
+	return requests
+}
+
+// reconcileOptionalCRD starts the watch on the type provided by an established CRD, then enqueues
+// the Orchestrators so that the resources which depend on the CRD are created.
+func (r *OrchestratorReconciler) reconcileOptionalCRD(ctx context.Context, object client.Object) []reconcile.Request {
+	logger := log.FromContext(ctx)
+	crd := object.(*apiextensionsv1.CustomResourceDefinition)
+	watch, found := getOptionalWatch(crd)
+	if !found || !isCRDEstablished(crd) {
+		return nil
+	}
+
+	r.watchesMutex.Lock()
+	defer r.watchesMutex.Unlock()
+	if !r.startedWatches[crd.Name] {
+		predicates := []predicate.Predicate{createdByPredicate}
+		if !watch.statusChanges {
+			predicates = append(predicates, specChangedPredicate)
+		}
+		err := r.controller.Watch(source.Kind(r.cache, watch.newObject(),
+			handler.EnqueueRequestsFromMapFunc(r.mapToOrchestrators), predicates...))
+		if err != nil {
+			logger.Error(err, "Error occurred when starting watch", "CRD", crd.Name)
+			return nil
+		}
+		if r.startedWatches == nil {
+			r.startedWatches = map[string]bool{}
+		}
+		r.startedWatches[crd.Name] = true
+		logger.Info("Started watch", "CRD", crd.Name)
+	}
+	return r.mapToOrchestrators(ctx, object)
+}
@@ -0,0 +1,127 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	orchestratorv1alpha2 "github.com/rhdhorchestrator/orchestrator-operator/api/v1alpha3"
+	"github.com/rhdhorchestrator/orchestrator-operator/internal/controller/kube"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/controller"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+)
+
+// fakeController records the sources of the started watches.
+type fakeController struct {
+	controller.Controller
+	sources []source.Source
+}
+
+func (c *fakeController) Watch(src source.Source) error {
+	c.sources = append(c.sources, src)
+	return nil
+}
+
+func newTestCRD(name string, established bool) *apiextensionsv1.CustomResourceDefinition {
+	status := apiextensionsv1.ConditionFalse
+	if established {
+		status = apiextensionsv1.ConditionTrue
+	}
+	return &apiextensionsv1.CustomResourceDefinition{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Status: apiextensionsv1.CustomResourceDefinitionStatus{
+			Conditions: []apiextensionsv1.CustomResourceDefinitionCondition{{Type: apiextensionsv1.Established, Status: status}},
+		},
+	}
+}
+
+func TestCreatedByPredicate(t *testing.T) {
+	labelled := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "labelled", Labels: kube.AddLabel()}}
+	unlabelled := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "unlabelled"}}
+
+	assert.True(t, createdByPredicate.Create(event.CreateEvent{Object: labelled}))
+	assert.True(t, createdByPredicate.Delete(event.DeleteEvent{Object: labelled}))
+	assert.False(t, createdByPredicate.Create(event.CreateEvent{Object: unlabelled}))
+	assert.False(t, createdByPredicate.Update(event.UpdateEvent{ObjectOld: unlabelled, ObjectNew: unlabelled}))
+}
+
+func TestCacheOptions(t *testing.T) {
+	postgresService := getPostgresService(newTestOrchestrator().Spec.PostgresConfig)
+
+	options := CacheOptions()
+	assert.Len(t, options.ByObject, 5)
+	for object, byObject := range options.ByObject {
+		assert.True(t, byObject.Label.Matches(labels.Set(postgresService.Labels)), "%T of the operator are cached", object)
+		assert.False(t, byObject.Label.Matches(labels.Set{}), "%T of the users are not cached", object)
+	}
+}
+
+func TestMapToOrchestrators(t *testing.T) {
+	ctx := context.TODO()
+	scheme := runtime.NewScheme()
+	utilruntime.Must(orchestratorv1alpha2.AddToScheme(scheme))
+
+	orchestrators := []*orchestratorv1alpha2.Orchestrator{
+		{ObjectMeta: metav1.ObjectMeta{Name: "orchestrator-a", Namespace: "namespace-a"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "orchestrator-b", Namespace: "namespace-b"}},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(orchestrators[0], orchestrators[1]).Build()
+	r := &OrchestratorReconciler{Client: fakeClient, Scheme: scheme}
+
+	owned := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "owned", Labels: kube.AddLabel(),
+		Annotations: map[string]string{kube.OwnerAnnotationKey: "namespace-b/orchestrator-b"}}}
+	requests := r.mapToOrchestrators(ctx, owned)
+	assert.Equal(t, []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Name: "orchestrator-b", Namespace: "namespace-b"}},
+	}, requests, "Only the owner of the object is enqueued")
+
+	unannotated := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "unannotated", Labels: kube.AddLabel()}}
+	assert.Len(t, r.mapToOrchestrators(ctx, unannotated), 2)
+}
+
+func TestOptionalCRDPredicate(t *testing.T) {
+	assert.True(t, optionalCRDPredicate.Create(event.CreateEvent{Object: newTestCRD(knativeBrokerCRDName, true)}))
+	assert.True(t, optionalCRDPredicate.Create(event.CreateEvent{Object: newTestCRD("appprojects.argoproj.io", false)}))
+	assert.False(t, optionalCRDPredicate.Create(event.CreateEvent{Object: newTestCRD("routes.route.openshift.io", true)}))
+}
+
+func TestReconcileOptionalCRD(t *testing.T) {
+	ctx := context.TODO()
+	scheme := runtime.NewScheme()
+	utilruntime.Must(orchestratorv1alpha2.AddToScheme(scheme))
+
+	orchestrators := []*orchestratorv1alpha2.Orchestrator{
+		{ObjectMeta: metav1.ObjectMeta{Name: "orchestrator-a"}},
+		{ObjectMeta: metav1.ObjectMeta{Name: "orchestrator-b"}},
+	}
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(orchestrators[0], orchestrators[1]).Build()
+	fakeCtrl := &fakeController{}
+	r := &OrchestratorReconciler{Client: fakeClient, Scheme: scheme, controller: fakeCtrl}
+
+	requests := r.reconcileOptionalCRD(ctx, newTestCRD(knativeServingCRDName, false))
+	assert.Empty(t, requests, "CRDs which are not established are ignored")
+	assert.Empty(t, fakeCtrl.sources)
+
+	requests = r.reconcileOptionalCRD(ctx, newTestCRD(knativeServingCRDName, true))
+	assert.Len(t, requests, 2)
+	assert.Equal(t, "orchestrator-a", requests[0].Name)
+	assert.Len(t, fakeCtrl.sources, 1)
+
+	// the watch is started once for each CRD
+	requests = r.reconcileOptionalCRD(ctx, newTestCRD(knativeServingCRDName, true))
+	assert.Len(t, requests, 2)
+	assert.Len(t, fakeCtrl.sources, 1)
+
+	r.reconcileOptionalCRD(ctx, newTestCRD(knativeBrokerCRDName, true))
+	assert.Len(t, fakeCtrl.sources, 2)
+}
@@ -0,0 +1,225 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"slices"
+
+	sonataapi "github.com/apache/incubator-kie-tools/packages/sonataflow-operator/api/v1alpha08"
+	orchestratorv1alpha2 "github.com/rhdhorchestrator/orchestrator-operator/api/v1alpha3"
+	"github.com/rhdhorchestrator/orchestrator-operator/internal/controller/kube"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// getWorkflowNamespaces returns the platform namespace followed by the additional workflow namespaces.
+func getWorkflowNamespaces(platformConfig orchestratorv1alpha2.PlatformConfig) []string {
+	workflowNamespaces := []string{platformConfig.Namespace}
+	for _, namespace := range platformConfig.AdditionalWorkflowNamespaces {
+		if !slices.Contains(workflowNamespaces, namespace) {
+			workflowNamespaces = append(workflowNamespaces, namespace)
+		}
+	}
+	return workflowNamespaces
+}
+
+// handleAdditionalWorkflowNamespaces prepares each additional workflow namespace with a SonataFlowPlatform
+// which relies on the services of the cluster platform, and a copy of the PostgreSQL secret.
+// The resources left in namespaces removed from the spec are deleted; the namespaces are kept.
+func handleAdditionalWorkflowNamespaces(
+	ctx context.Context, k8client client.Client, recorder record.EventRecorder,
+	orchestrator *orchestratorv1alpha2.Orchestrator) error {
+	logger := log.FromContext(ctx)
+	platformConfig := orchestrator.Spec.PlatformConfig
+
+	for _, namespace := range getWorkflowNamespaces(platformConfig)[1:] {
+		logger.Info("Handling additional workflow namespace", "NS", namespace)
+		if err := k8client.Get(ctx, types.NamespacedName{Name: namespace}, &corev1.Namespace{}); err != nil {
+			if !apierrors.IsNotFound(err) {
+				logger.Error(err, "Error occurred when retrieving namespace", "NS", namespace)
+				return err
+			}
+			// existing namespaces are not labelled, so that they are never deleted by the clean up
+			if err := kube.CreateNamespace(ctx, k8client, namespace); err != nil {
+				return err
+			}
+		}
+		if err := copyPostgresSecret(ctx, k8client, orchestrator.Spec.PostgresConfig, namespace); err != nil {
+			logger.Error(err, "Error occurred when copying PostgreSQL secret", "NS", namespace)
+			return err
+		}
+		if err := handleWorkflowSonataFlowPlatformCR(ctx, k8client, recorder, orchestrator, namespace); err != nil {
+			logger.Error(err, "Error occurred when handling SonataFlowPlatform", "NS", namespace)
+			return err
+		}
+	}
+	return cleanUpRemovedWorkflowNamespaces(ctx, k8client, orchestrator)
+}
+
+// copyPostgresSecret copies the PostgreSQL auth secret, used by the persistence of the workflows, into namespace.
+// A secret with the same name which was not copied by the operator is left untouched.
+func copyPostgresSecret(ctx context.Context, k8client client.Client,
+	postgresConfig orchestratorv1alpha2.PostgresConfig, namespace string) error {
+	sourceSecret := &corev1.Secret{}
+	if err := k8client.Get(ctx, types.NamespacedName{
+		Name: postgresConfig.AuthSecret.SecretName, Namespace: postgresConfig.Namespace}, sourceSecret); err != nil {
+		return err
+	}
+
+	secret := &corev1.Secret{}
+	if err := k8client.Get(ctx, types.NamespacedName{Name: sourceSecret.Name, Namespace: namespace}, secret); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: sourceSecret.Name, Namespace: namespace, Labels: kube.AddLabel()},
+			Type:       sourceSecret.Type,
+			Data:       sourceSecret.Data,
+		}
+		return k8client.Create(ctx, secret)
+	}
+	if !kube.CheckLabelExist(secret.Labels) || equality.Semantic.DeepEqual(secret.Data, sourceSecret.Data) {
+		return nil
+	}
+	original := secret.DeepCopy()
+	secret.Data = sourceSecret.Data
+	return k8client.Patch(ctx, secret, client.MergeFrom(original))
+}
+
+// getWorkflowSonataFlowPlatformSpec returns the spec of a platform without services: the SonataFlow operator
+// points its workflows to the Data Index and Job Service of the cluster platform.
+func getWorkflowSonataFlowPlatformSpec(ctx context.Context, orchestrator *orchestratorv1alpha2.Orchestrator) (sonataapi.SonataFlowPlatformSpec, error) {
+	platformSpec, err := getSonataFlowPlatformSpec(ctx, orchestrator)
+	if err != nil {
+		return sonataapi.SonataFlowPlatformSpec{}, err
+	}
+	platformSpec.Services = nil
+	return platformSpec, nil
+}
+
+func handleWorkflowSonataFlowPlatformCR(
+	ctx context.Context, k8client client.Client, recorder record.EventRecorder,
+	orchestrator *orchestratorv1alpha2.Orchestrator, namespace string) error {
+	logger := log.FromContext(ctx)
+	desiredSpec, err := getWorkflowSonataFlowPlatformSpec(ctx, orchestrator)
+	if err != nil {
+		return err
+	}
+
+	sfpCR := &sonataapi.SonataFlowPlatform{}
+	if err := k8client.Get(ctx, types.NamespacedName{Name: sonataFlowPlatformCRName, Namespace: namespace}, sfpCR); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return err
+		}
+		sfpCR = &sonataapi.SonataFlowPlatform{
+			TypeMeta: metav1.TypeMeta{
+				APIVersion: sonataFlowAPIVersion,
+				Kind:       sonataFlowPlatformKind,
+			},
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      sonataFlowPlatformCRName,
+				Namespace: namespace,
+				Labels:    kube.AddLabel(),
+			},
+			Spec: desiredSpec,
+		}
+		if err := k8client.Create(ctx, sfpCR); err != nil {
+			return err
+		}
+		logger.Info("Successfully created SonataFlowPlatform CR", "NS", namespace)
+		return nil
+	}
+
+	originalCR := sfpCR.DeepCopy()
+	sfpCR.Spec.Build.Template.Resources = desiredSpec.Build.Template.Resources
+	sfpCR.Spec.Monitoring = desiredSpec.Monitoring
+	sfpCR.Spec.Eventing = desiredSpec.Eventing
+	if equality.Semantic.DeepEqual(originalCR.Spec, sfpCR.Spec) {
+		return nil
+	}
+	if err := k8client.Patch(ctx, sfpCR, client.MergeFrom(originalCR)); err != nil {
+		return err
+	}
+	logger.Info("Successfully updated SonataFlowPlatform CR", "NS", namespace)
+	recorder.Eventf(orchestrator, corev1.EventTypeNormal, "SonataFlowPlatformUpdated",
+		"Updated SonataFlowPlatform %s/%s to match the Orchestrator spec", namespace, sonataFlowPlatformCRName)
+	return nil
+}
+
+// cleanUpRemovedWorkflowNamespaces deletes the SonataFlowPlatforms, copied secrets and NetworkPolicies created for
+// orchestrator outside of its current workflow namespaces.
+func cleanUpRemovedWorkflowNamespaces(
+	ctx context.Context, k8client client.Client, orchestrator *orchestratorv1alpha2.Orchestrator) error {
+	logger := log.FromContext(ctx)
+	workflowNamespaces := getWorkflowNamespaces(orchestrator.Spec.PlatformConfig)
+
+	platforms := &sonataapi.SonataFlowPlatformList{}
+	if err := k8client.List(ctx, platforms, client.MatchingLabels(kube.AddLabel())); err != nil {
+		return err
+	}
+	for i := range platforms.Items {
+		platform := &platforms.Items[i]
+		// the platforms of the other Orchestrators share the created-by label
+		if slices.Contains(workflowNamespaces, platform.Namespace) || !kube.IsOwnedBy(platform, orchestrator) {
+			continue
+		}
+		logger.Info("Deleting SonataFlowPlatform of removed workflow namespace", "NS", platform.Namespace)
+		if err := k8client.Delete(ctx, platform); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+		secret := &corev1.Secret{}
+		err := k8client.Get(ctx, types.NamespacedName{
+			Name: orchestrator.Spec.PostgresConfig.AuthSecret.SecretName, Namespace: platform.Namespace}, secret)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return err
+		}
+		if platform.Namespace != orchestrator.Spec.PostgresConfig.Namespace && kube.CheckLabelExist(secret.Labels) &&
+			kube.IsOwnedBy(secret, orchestrator) {
+			if err := k8client.Delete(ctx, secret); err != nil && !apierrors.IsNotFound(err) {
+				return err
+			}
+		}
+	}
+
+	// the NetworkPolicies would keep allowing the ingress from the other workflow, RHDH and database namespaces
+	networkPolicies := &networkingv1.NetworkPolicyList{}
+	if err := k8client.List(ctx, networkPolicies, client.MatchingLabels(kube.AddLabel())); err != nil {
+		return err
+	}
+	for i := range networkPolicies.Items {
+		networkPolicy := &networkPolicies.Items[i]
+		if slices.Contains(workflowNamespaces, networkPolicy.Namespace) || !kube.IsOwnedBy(networkPolicy, orchestrator) {
+			continue
+		}
+		logger.Info("Deleting NetworkPolicy of removed workflow namespace", "NP", networkPolicy.Name, "NS", networkPolicy.Namespace)
+		if err := k8client.Delete(ctx, networkPolicy); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
+}
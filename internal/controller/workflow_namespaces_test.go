@@ -0,0 +1,91 @@
+package controller
+
+import (
+	"context"
+	"testing"
+
+	sonataapi "github.com/apache/incubator-kie-tools/packages/sonataflow-operator/api/v1alpha08"
+	"github.com/rhdhorchestrator/orchestrator-operator/internal/controller/kube"
+	"github.com/stretchr/testify/assert"
+	corev1 "k8s.io/api/core/v1"
+	networkingv1 "k8s.io/api/networking/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestHandleAdditionalWorkflowNamespaces(t *testing.T) {
+	ctx := context.TODO()
+	scheme := runtime.NewScheme()
+	utilruntime.Must(corev1.AddToScheme(scheme))
+	utilruntime.Must(networkingv1.AddToScheme(scheme))
+	utilruntime.Must(sonataapi.AddToScheme(scheme))
+
+	orchestrator := newTestSonataFlowOrchestrator()
+	orchestrator.Spec.PlatformConfig.AdditionalWorkflowNamespaces = []string{"team-a", "team-b"}
+	postgresSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: orchestrator.Spec.PostgresConfig.AuthSecret.SecretName, Namespace: testNamespace},
+		Data:       map[string][]byte{"postgres-username": []byte("sonataflow"), "postgres-password": []byte("secret")},
+	}
+	existingNamespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: "team-a"}}
+	// left over by a previous spec which listed team-c
+	ownerAnnotations := map[string]string{kube.OwnerAnnotationKey: orchestrator.Namespace + "/" + orchestrator.Name}
+	stalePlatform := &sonataapi.SonataFlowPlatform{
+		ObjectMeta: metav1.ObjectMeta{Name: sonataFlowPlatformCRName, Namespace: "team-c", Labels: kube.AddLabel(),
+			Annotations: ownerAnnotations},
+	}
+	staleSecret := postgresSecret.DeepCopy()
+	staleSecret.Namespace = "team-c"
+	staleSecret.Labels = kube.AddLabel()
+	staleSecret.Annotations = ownerAnnotations
+	staleNetworkPolicy := &networkingv1.NetworkPolicy{
+		ObjectMeta: metav1.ObjectMeta{Name: allowRHDHToSonataflowWorkflows, Namespace: "team-c", Labels: kube.AddLabel(),
+			Annotations: ownerAnnotations},
+	}
+	userNetworkPolicy := &networkingv1.NetworkPolicy{ObjectMeta: metav1.ObjectMeta{Name: "user", Namespace: "team-c"}}
+	// created for another Orchestrator
+	otherPlatform := &sonataapi.SonataFlowPlatform{
+		ObjectMeta: metav1.ObjectMeta{Name: sonataFlowPlatformCRName, Namespace: "team-d", Labels: kube.AddLabel(),
+			Annotations: map[string]string{kube.OwnerAnnotationKey: "other/orchestrator"}},
+	}
+
+	fakeClient := fake.NewClientBuilder().WithScheme(scheme).
+		WithObjects(postgresSecret, existingNamespace, stalePlatform, staleSecret, staleNetworkPolicy, userNetworkPolicy, otherPlatform).
+		Build()
+	recorder := record.NewFakeRecorder(10)
+
+	assert.Equal(t, []string{testNamespace, "team-a", "team-b"}, getWorkflowNamespaces(orchestrator.Spec.PlatformConfig))
+	assert.NoError(t, handleAdditionalWorkflowNamespaces(ctx, fakeClient, recorder, orchestrator))
+
+	for _, namespace := range []string{"team-a", "team-b"} {
+		platform := &sonataapi.SonataFlowPlatform{}
+		assert.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: sonataFlowPlatformCRName, Namespace: namespace}, platform))
+		assert.Nil(t, platform.Spec.Services, "Workflow namespaces use the services of the cluster platform")
+
+		secret := &corev1.Secret{}
+		assert.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: postgresSecret.Name, Namespace: namespace}, secret))
+		assert.Equal(t, postgresSecret.Data, secret.Data)
+	}
+
+	namespace := &corev1.Namespace{}
+	assert.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: "team-a"}, namespace))
+	assert.False(t, kube.CheckLabelExist(namespace.Labels), "Existing namespaces are not labelled for deletion")
+	assert.NoError(t, fakeClient.Get(ctx, types.NamespacedName{Name: "team-b"}, namespace))
+	assert.True(t, kube.CheckLabelExist(namespace.Labels))
+
+	err := fakeClient.Get(ctx, types.NamespacedName{Name: sonataFlowPlatformCRName, Namespace: "team-c"}, &sonataapi.SonataFlowPlatform{})
+	assert.True(t, apierrors.IsNotFound(err))
+	err = fakeClient.Get(ctx, types.NamespacedName{Name: postgresSecret.Name, Namespace: "team-c"}, &corev1.Secret{})
+	assert.True(t, apierrors.IsNotFound(err))
+	err = fakeClient.Get(ctx, client.ObjectKeyFromObject(staleNetworkPolicy), &networkingv1.NetworkPolicy{})
+	assert.True(t, apierrors.IsNotFound(err), "The NetworkPolicies of removed namespaces are deleted")
+	err = fakeClient.Get(ctx, client.ObjectKeyFromObject(userNetworkPolicy), &networkingv1.NetworkPolicy{})
+	assert.NoError(t, err, "The NetworkPolicies of the users are kept")
+	err = fakeClient.Get(ctx, types.NamespacedName{Name: sonataFlowPlatformCRName, Namespace: "team-d"}, &sonataapi.SonataFlowPlatform{})
+	assert.NoError(t, err, "The platforms of other Orchestrators are kept")
+}
@@ -0,0 +1,481 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha3
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"slices"
+
+	orchestratorv1alpha2 "github.com/rhdhorchestrator/orchestrator-operator/api/v1alpha3"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"knative.dev/operator/pkg/apis/operator/base"
+	knative "knative.dev/operator/pkg/apis/operator/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+)
+
+// log is for logging in this package.
+var orchestratorlog = logf.Log.WithName("orchestrator-resource")
+
+// SetupOrchestratorWebhookWithManager registers the webhook for Orchestrator in the manager.
+func SetupOrchestratorWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(&orchestratorv1alpha2.Orchestrator{}).
+		WithValidator(&OrchestratorCustomValidator{}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/validate-rhdh-redhat-com-v1alpha3-orchestrator,mutating=false,failurePolicy=fail,sideEffects=None,groups=rhdh.redhat.com,resources=orchestrators,verbs=create;update,versions=v1alpha3,name=vorchestrator-v1alpha3.kb.io,admissionReviewVersions=v1
+
+// OrchestratorCustomValidator validates the Orchestrator spec at admission time,
+// so that invalid configuration is rejected before it reaches the reconciler.
+type OrchestratorCustomValidator struct{}
+
+var _ webhook.CustomValidator = &OrchestratorCustomValidator{}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the type Orchestrator.
+func (v *OrchestratorCustomValidator) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	orchestrator, ok := obj.(*orchestratorv1alpha2.Orchestrator)
+	if !ok {
+		return nil, fmt.Errorf("expected an Orchestrator object but got %T", obj)
+	}
+	orchestratorlog.Info("Validation for Orchestrator upon creation", "name", orchestrator.GetName())
+
+	return validateOrchestrator(orchestrator)
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the type Orchestrator.
+func (v *OrchestratorCustomValidator) ValidateUpdate(_ context.Context, _, newObj runtime.Object) (admission.Warnings, error) {
+	orchestrator, ok := newObj.(*orchestratorv1alpha2.Orchestrator)
+	if !ok {
+		return nil, fmt.Errorf("expected an Orchestrator object for the newObj but got %T", newObj)
+	}
+	orchestratorlog.Info("Validation for Orchestrator upon update", "name", orchestrator.GetName())
+
+	// allow the finalizer to complete even if the spec is no longer valid
+	if !orchestrator.DeletionTimestamp.IsZero() {
+		return nil, nil
+	}
+	return validateOrchestrator(orchestrator)
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the type Orchestrator.
+func (v *OrchestratorCustomValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validateOrchestrator collects all spec violations into a single Invalid error.
+func validateOrchestrator(orchestrator *orchestratorv1alpha2.Orchestrator) (admission.Warnings, error) {
+	specPath := field.NewPath("spec")
+	spec := orchestrator.Spec
+
+	var allErrs field.ErrorList
+	allErrs = append(allErrs, validateRHDHConfig(spec.RHDHConfig, specPath.Child("rhdh"))...)
+	allErrs = append(allErrs, validatePostgresConfig(spec.PostgresConfig, specPath.Child("postgres"))...)
+	allErrs = append(allErrs, validatePlatformConfig(spec.PlatformConfig, specPath.Child("platform"))...)
+	allErrs = append(allErrs, validateServerlessOperator(spec.ServerlessOperator, specPath.Child("serverless"))...)
+	allErrs = append(allErrs, validateGitOps(spec, specPath)...)
+	allErrs = append(allErrs, validateDisconnected(spec.Disconnected, specPath.Child("disconnected"))...)
+
+	warnings := collectWarnings(spec)
+
+	if len(allErrs) == 0 {
+		return warnings, nil
+	}
+	return warnings, apierrors.NewInvalid(
+		orchestratorv1alpha2.GroupVersion.WithKind("Orchestrator").GroupKind(),
+		orchestrator.Name, allErrs)
+}
+
+func validateRHDHConfig(rhdhConfig orchestratorv1alpha2.RHDHConfig, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	allErrs = append(allErrs, validateName(rhdhConfig.Name, fldPath.Child("name"))...)
+	allErrs = append(allErrs, validateNamespace(rhdhConfig.Namespace, fldPath.Child("namespace"))...)
+
+	notificationsConfig := rhdhConfig.RHDHPlugins.NotificationsConfig
+	notificationsPath := fldPath.Child("plugins", "notificationsEmail")
+	if notificationsConfig.Enabled {
+		if notificationsConfig.Sender == "" {
+			allErrs = append(allErrs, field.Required(notificationsPath.Child("sender"), "sender is required when the notifications email plugin is enabled"))
+		}
+		if notificationsConfig.Port < 1 || notificationsConfig.Port > 65535 {
+			allErrs = append(allErrs, field.Invalid(notificationsPath.Child("port"), notificationsConfig.Port, "must be a valid port number between 1 and 65535"))
+		}
+	}
+	allErrs = append(allErrs, validateEmail(notificationsConfig.Sender, notificationsPath.Child("sender"))...)
+	allErrs = append(allErrs, validateEmail(notificationsConfig.Recipient, notificationsPath.Child("replyTo"))...)
+
+	rhdhResources := orchestratorv1alpha2.Resource{
+		Requests: orchestratorv1alpha2.MemoryCpu(rhdhConfig.Resources.Requests),
+		Limits:   orchestratorv1alpha2.MemoryCpu(rhdhConfig.Resources.Limits),
+	}
+	allErrs = append(allErrs, validateResources(rhdhResources, fldPath.Child("resources"))...)
+	for i, secretName := range rhdhConfig.ExtraEnvSecrets {
+		allErrs = append(allErrs, validateName(secretName, fldPath.Child("extraEnvSecrets").Index(i))...)
+	}
+
+	allErrs = append(allErrs, validateURL(rhdhConfig.BaseURL, fldPath.Child("baseUrl"))...)
+	ingress := rhdhConfig.Ingress
+	ingressPath := fldPath.Child("ingress")
+	if ingress.ClassName != "" {
+		allErrs = append(allErrs, validateName(ingress.ClassName, ingressPath.Child("className"))...)
+	}
+	if ingress.TLSSecretName != "" {
+		allErrs = append(allErrs, validateName(ingress.TLSSecretName, ingressPath.Child("tlsSecretName"))...)
+	}
+	if ingress.Type == orchestratorv1alpha2.RHDHIngressTypeHTTPRoute {
+		if ingress.GatewayName == "" {
+			allErrs = append(allErrs, field.Required(ingressPath.Child("gatewayName"), "gatewayName is required for the HTTPRoute type"))
+		} else {
+			allErrs = append(allErrs, validateName(ingress.GatewayName, ingressPath.Child("gatewayName"))...)
+		}
+		if ingress.GatewayNamespace != "" {
+			allErrs = append(allErrs, validateNamespace(ingress.GatewayNamespace, ingressPath.Child("gatewayNamespace"))...)
+		}
+	}
+	allErrs = append(allErrs, validateRHDHAuth(rhdhConfig.Auth, fldPath.Child("auth"))...)
+	allErrs = append(allErrs, validateRHDHIntegrations(rhdhConfig.Integrations, fldPath.Child("integrations"))...)
+	return allErrs
+}
+
+func validateRHDHIntegrations(integrations []orchestratorv1alpha2.RHDHIntegration, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	hosts := map[orchestratorv1alpha2.RHDHIntegrationType][]string{}
+	for i, integration := range integrations {
+		integrationPath := fldPath.Index(i)
+		if integration.Host == "" {
+			if integration.Type == orchestratorv1alpha2.RHDHIntegrationBitbucketServer {
+				allErrs = append(allErrs, field.Required(integrationPath.Child("host"), "host is required for the bitbucketServer integration"))
+			}
+		} else if parsedURL, err := url.Parse("https://" + integration.Host); err != nil || parsedURL.Host != integration.Host {
+			allErrs = append(allErrs, field.Invalid(integrationPath.Child("host"), integration.Host, "must be a host name with an optional port"))
+		}
+		if slices.Contains(hosts[integration.Type], integration.Host) {
+			allErrs = append(allErrs, field.Duplicate(integrationPath.Child("host"), integration.Host))
+		}
+		hosts[integration.Type] = append(hosts[integration.Type], integration.Host)
+		allErrs = append(allErrs, validateURL(integration.APIBaseURL, integrationPath.Child("apiBaseUrl"))...)
+		allErrs = append(allErrs, validateEnvVarName(integration.TokenKey, integrationPath.Child("tokenKey"))...)
+		allErrs = append(allErrs, validateEnvVarName(integration.UsernameKey, integrationPath.Child("usernameKey"))...)
+	}
+	return allErrs
+}
+
+func validateRHDHAuth(auth orchestratorv1alpha2.RHDHAuth, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	allErrs = append(allErrs, validateEnvVarName(auth.SessionSecretKey, fldPath.Child("sessionSecretKey"))...)
+	for i, provider := range auth.Providers {
+		providerPath := fldPath.Child("providers").Index(i)
+		allErrs = append(allErrs, validateEnvVarName(provider.ClientIDKey, providerPath.Child("clientIdKey"))...)
+		allErrs = append(allErrs, validateEnvVarName(provider.ClientSecretKey, providerPath.Child("clientSecretKey"))...)
+		allErrs = append(allErrs, validateEnvVarName(provider.TenantIDKey, providerPath.Child("tenantIdKey"))...)
+		allErrs = append(allErrs, validateURL(provider.URL, providerPath.Child("url"))...)
+		if provider.Type == orchestratorv1alpha2.RHDHAuthProviderOIDC && provider.URL == "" {
+			allErrs = append(allErrs, field.Required(providerPath.Child("url"), "url of the OIDC metadata is required for the oidc provider"))
+		}
+		supportedResolvers := orchestratorv1alpha2.SupportedSignInResolvers[provider.Type]
+		for j, resolver := range provider.SignInResolvers {
+			if !slices.Contains(supportedResolvers, resolver) {
+				allErrs = append(allErrs, field.NotSupported(providerPath.Child("signInResolvers").Index(j), resolver, supportedResolvers))
+			}
+		}
+	}
+	return allErrs
+}
+
+func validatePostgresConfig(postgresConfig orchestratorv1alpha2.PostgresConfig, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	allErrs = append(allErrs, validateName(postgresConfig.Name, fldPath.Child("name"))...)
+	allErrs = append(allErrs, validateNamespace(postgresConfig.Namespace, fldPath.Child("namespace"))...)
+	allErrs = append(allErrs, validateName(postgresConfig.AuthSecret.SecretName, fldPath.Child("authSecret", "name"))...)
+	if postgresConfig.AuthSecret.UserKey == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("authSecret", "userKey"), ""))
+	}
+	if postgresConfig.AuthSecret.PasswordKey == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("authSecret", "passwordKey"), ""))
+	}
+	if postgresConfig.DatabaseName == "" {
+		allErrs = append(allErrs, field.Required(fldPath.Child("database"), ""))
+	}
+	if postgresConfig.Provision {
+		storageSize, errs := parseQuantity(postgresConfig.StorageSize, fldPath.Child("storageSize"))
+		allErrs = append(allErrs, errs...)
+		if storageSize != nil && storageSize.Sign() == 0 {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("storageSize"), postgresConfig.StorageSize, "must be greater than 0"))
+		}
+		if postgresConfig.StorageClassName != "" {
+			allErrs = append(allErrs, validateName(postgresConfig.StorageClassName, fldPath.Child("storageClassName"))...)
+		}
+	}
+	return allErrs
+}
+
+func validatePlatformConfig(platformConfig orchestratorv1alpha2.PlatformConfig, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	allErrs = append(allErrs, validateNamespace(platformConfig.Namespace, fldPath.Child("namespace"))...)
+	allErrs = append(allErrs, validateResources(platformConfig.Resources, fldPath.Child("resources"))...)
+	for i, namespace := range platformConfig.AdditionalWorkflowNamespaces {
+		namespacePath := fldPath.Child("additionalWorkflowNamespaces").Index(i)
+		allErrs = append(allErrs, validateNamespace(namespace, namespacePath)...)
+		if namespace == platformConfig.Namespace {
+			allErrs = append(allErrs, field.Duplicate(namespacePath, namespace))
+		}
+	}
+
+	if platformConfig.ClusterDomain != "" {
+		allErrs = append(allErrs, validateName(platformConfig.ClusterDomain, fldPath.Child("clusterDomain"))...)
+	}
+
+	broker := platformConfig.Eventing.Broker
+	brokerPath := fldPath.Child("eventing", "broker")
+	switch {
+	case broker.Name == "" && broker.Namespace == "" && broker.Create:
+		allErrs = append(allErrs, field.Required(brokerPath.Child("name"), "name and namespace are required when create is enabled"))
+	case broker.Name == "" && broker.Namespace == "":
+		// no existing broker configured
+	case broker.Name == "":
+		allErrs = append(allErrs, field.Required(brokerPath.Child("name"), "name and namespace of the broker must be set together"))
+	case broker.Namespace == "":
+		allErrs = append(allErrs, field.Required(brokerPath.Child("namespace"), "name and namespace of the broker must be set together"))
+	default:
+		allErrs = append(allErrs, validateName(broker.Name, brokerPath.Child("name"))...)
+		allErrs = append(allErrs, validateNamespace(broker.Namespace, brokerPath.Child("namespace"))...)
+	}
+	return allErrs
+}
+
+// validateResources checks the quantity syntax of the requests and limits,
+// and that no request exceeds its matching limit.
+func validateResources(resources orchestratorv1alpha2.Resource, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+
+	requestsPath := fldPath.Child("requests")
+	limitsPath := fldPath.Child("limits")
+
+	requestCpu, errs := parseQuantity(resources.Requests.Cpu, requestsPath.Child("cpu"))
+	allErrs = append(allErrs, errs...)
+	requestMemory, errs := parseQuantity(resources.Requests.Memory, requestsPath.Child("memory"))
+	allErrs = append(allErrs, errs...)
+	limitCpu, errs := parseQuantity(resources.Limits.Cpu, limitsPath.Child("cpu"))
+	allErrs = append(allErrs, errs...)
+	limitMemory, errs := parseQuantity(resources.Limits.Memory, limitsPath.Child("memory"))
+	allErrs = append(allErrs, errs...)
+
+	if requestCpu != nil && limitCpu != nil && requestCpu.Cmp(*limitCpu) > 0 {
+		allErrs = append(allErrs, field.Invalid(requestsPath.Child("cpu"), resources.Requests.Cpu,
+			fmt.Sprintf("must be less than or equal to cpu limit of %s", resources.Limits.Cpu)))
+	}
+	if requestMemory != nil && limitMemory != nil && requestMemory.Cmp(*limitMemory) > 0 {
+		allErrs = append(allErrs, field.Invalid(requestsPath.Child("memory"), resources.Requests.Memory,
+			fmt.Sprintf("must be less than or equal to memory limit of %s", resources.Limits.Memory)))
+	}
+	return allErrs
+}
+
+// parseQuantity returns nil without error for an unset quantity.
+func parseQuantity(value string, fldPath *field.Path) (*resource.Quantity, field.ErrorList) {
+	if value == "" {
+		return nil, nil
+	}
+	quantity, err := resource.ParseQuantity(value)
+	if err != nil {
+		return nil, field.ErrorList{field.Invalid(fldPath, value, err.Error())}
+	}
+	if quantity.Sign() < 0 {
+		return nil, field.ErrorList{field.Invalid(fldPath, value, "must be greater than or equal to 0")}
+	}
+	return &quantity, nil
+}
+
+// validateServerlessOperator decodes the KnativeServing and KnativeEventing specs strictly, as the controller does
+// before replacing the spec of the live CRs, and checks their replicas.
+func validateServerlessOperator(serverless orchestratorv1alpha2.ServerlessOperator, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	servingSpec := knative.KnativeServingSpec{}
+	servingPath := fldPath.Child("serving")
+	if err := decodeKnativeSpec(serverless.Serving, &servingSpec, servingPath); err != nil {
+		allErrs = append(allErrs, err)
+	} else {
+		allErrs = append(allErrs, validateKnativeCommonSpec(servingSpec.CommonSpec, servingPath)...)
+	}
+
+	eventingSpec := knative.KnativeEventingSpec{}
+	eventingPath := fldPath.Child("eventing")
+	if err := decodeKnativeSpec(serverless.Eventing, &eventingSpec, eventingPath); err != nil {
+		allErrs = append(allErrs, err)
+	} else {
+		allErrs = append(allErrs, validateKnativeCommonSpec(eventingSpec.CommonSpec, eventingPath)...)
+		if mode := eventingSpec.SinkBindingSelectionMode; mode != "" && mode != "inclusion" && mode != "exclusion" {
+			allErrs = append(allErrs, field.NotSupported(
+				eventingPath.Child("sinkBindingSelectionMode"), mode, []string{"inclusion", "exclusion"}))
+		}
+	}
+	return allErrs
+}
+
+// decodeKnativeSpec decodes the passthrough spec into spec, rejecting unknown fields and mistyped values.
+func decodeKnativeSpec(passthrough *runtime.RawExtension, spec any, fldPath *field.Path) *field.Error {
+	if passthrough == nil || len(passthrough.Raw) == 0 {
+		return nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(passthrough.Raw))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(spec); err != nil {
+		return field.Invalid(fldPath, string(passthrough.Raw), err.Error())
+	}
+	return nil
+}
+
+func validateKnativeCommonSpec(spec base.CommonSpec, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if spec.HighAvailability != nil {
+		replicasPath := fldPath.Child("high-availability", "replicas")
+		if spec.HighAvailability.Replicas == nil {
+			allErrs = append(allErrs, field.Required(replicasPath, ""))
+		} else if *spec.HighAvailability.Replicas < 1 {
+			allErrs = append(allErrs, field.Invalid(replicasPath, *spec.HighAvailability.Replicas, "must be greater than or equal to 1"))
+		}
+	}
+	for i, workload := range spec.Workloads {
+		workloadPath := fldPath.Child("workloads").Index(i)
+		if workload.Name == "" {
+			allErrs = append(allErrs, field.Required(workloadPath.Child("name"), ""))
+		}
+		if workload.Replicas != nil && *workload.Replicas < 0 {
+			allErrs = append(allErrs, field.Invalid(workloadPath.Child("replicas"), *workload.Replicas, "must be greater than or equal to 0"))
+		}
+	}
+	return allErrs
+}
+
+func validateGitOps(spec orchestratorv1alpha2.OrchestratorSpec, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	argoCDPath := fldPath.Child("argocd")
+
+	if spec.ArgoCd.Enabled && spec.ArgoCd.Namespace == "" {
+		allErrs = append(allErrs, field.Required(argoCDPath.Child("namespace"), "namespace is required when argocd is enabled"))
+	}
+	if spec.ArgoCd.Namespace != "" {
+		allErrs = append(allErrs, validateNamespace(spec.ArgoCd.Namespace, argoCDPath.Child("namespace"))...)
+	}
+	// the Tekton pipeline and tasks are created in the ArgoCD namespace
+	if spec.Tekton.Enabled && !spec.ArgoCd.Enabled {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("tekton", "enabled"), spec.Tekton.Enabled, "tekton requires argocd to be enabled"))
+	}
+	return allErrs
+}
+
+func validateDisconnected(disconnected orchestratorv1alpha2.DisconnectedConfig, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	allErrs = append(allErrs, validateURL(disconnected.NpmRegistry, fldPath.Child("npmRegistry"))...)
+	allErrs = append(allErrs, validateURL(disconnected.PluginBaseUrl, fldPath.Child("pluginBaseUrl"))...)
+	for i, location := range disconnected.CatalogLocations {
+		allErrs = append(allErrs, validateURL(location, fldPath.Child("catalogLocations").Index(i))...)
+	}
+	toolImagesPath := fldPath.Child("toolImages")
+	allErrs = append(allErrs, validateURL(disconnected.ToolImages.KnWorkflowCliUrl, toolImagesPath.Child("knWorkflowCliUrl"))...)
+	allErrs = append(allErrs, validateURL(disconnected.ToolImages.WorkflowBuilderDockerfileUrl, toolImagesPath.Child("workflowBuilderDockerfileUrl"))...)
+	return allErrs
+}
+
+// collectWarnings returns the non-fatal findings about the spec.
+func collectWarnings(spec orchestratorv1alpha2.OrchestratorSpec) admission.Warnings {
+	var warnings admission.Warnings
+	if spec.RHDHConfig.DevMode {
+		if spec.RHDHConfig.InstallOperator {
+			warnings = append(warnings, "spec.rhdh.devMode enables the guest provider and must not be used in production")
+		} else {
+			warnings = append(warnings, "spec.rhdh.devMode has no effect when spec.rhdh.installOperator is false")
+		}
+	}
+	for _, provider := range spec.RHDHConfig.Auth.Providers {
+		if provider.Type == orchestratorv1alpha2.RHDHAuthProviderGuest {
+			warnings = append(warnings, "spec.rhdh.auth.providers enables the guest provider and must not be used in production")
+		}
+	}
+	if spec.ArgoCd.Enabled && !spec.Tekton.Enabled {
+		warnings = append(warnings, "spec.argocd.enabled without spec.tekton.enabled does not create the orchestrator AppProject or Tekton pipeline")
+	}
+	return warnings
+}
+
+func validateName(name string, fldPath *field.Path) field.ErrorList {
+	if name == "" {
+		return field.ErrorList{field.Required(fldPath, "")}
+	}
+	var allErrs field.ErrorList
+	for _, msg := range validation.IsDNS1123Subdomain(name) {
+		allErrs = append(allErrs, field.Invalid(fldPath, name, msg))
+	}
+	return allErrs
+}
+
+func validateNamespace(namespace string, fldPath *field.Path) field.ErrorList {
+	if namespace == "" {
+		return field.ErrorList{field.Required(fldPath, "")}
+	}
+	var allErrs field.ErrorList
+	for _, msg := range validation.IsDNS1123Label(namespace) {
+		allErrs = append(allErrs, field.Invalid(fldPath, namespace, msg))
+	}
+	return allErrs
+}
+
+// validateEnvVarName accepts an unset name.
+func validateEnvVarName(name string, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if name == "" {
+		return allErrs
+	}
+	for _, msg := range validation.IsEnvVarName(name) {
+		allErrs = append(allErrs, field.Invalid(fldPath, name, msg))
+	}
+	return allErrs
+}
+
+// validateURL accepts an unset URL.
+func validateURL(value string, fldPath *field.Path) field.ErrorList {
+	if value == "" {
+		return nil
+	}
+	parsedURL, err := url.ParseRequestURI(value)
+	if err != nil || (parsedURL.Scheme != "http" && parsedURL.Scheme != "https") || parsedURL.Host == "" {
+		return field.ErrorList{field.Invalid(fldPath, value, "must be a valid http or https URL")}
+	}
+	return nil
+}
+
+// validateEmail accepts an unset address.
+func validateEmail(address string, fldPath *field.Path) field.ErrorList {
+	if address == "" {
+		return nil
+	}
+	if _, err := mail.ParseAddress(address); err != nil {
+		return field.ErrorList{field.Invalid(fldPath, address, "must be a valid email address")}
+	}
+	return nil
+}
@@ -0,0 +1,297 @@
+/*
+Copyright 2024 Red Hat, Inc.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+	http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1alpha3
+
+import (
+	"context"
+	"testing"
+
+	orchestratorv1alpha2 "github.com/rhdhorchestrator/orchestrator-operator/api/v1alpha3"
+	"github.com/stretchr/testify/assert"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+func validOrchestrator() *orchestratorv1alpha2.Orchestrator {
+	return &orchestratorv1alpha2.Orchestrator{
+		ObjectMeta: metav1.ObjectMeta{Name: "orchestrator-sample", Namespace: "default"},
+		Spec: orchestratorv1alpha2.OrchestratorSpec{
+			RHDHConfig: orchestratorv1alpha2.RHDHConfig{
+				Name:            "my-rhdh",
+				Namespace:       "rhdh",
+				InstallOperator: true,
+			},
+			PostgresConfig: orchestratorv1alpha2.PostgresConfig{
+				Name:      "sonataflow-psql-postgresql",
+				Namespace: "sonataflow-infra",
+				AuthSecret: orchestratorv1alpha2.PostgresAuthSecret{
+					SecretName:  "sonataflow-psql-postgresql",
+					UserKey:     "postgres-username",
+					PasswordKey: "postgres-password",
+				},
+				DatabaseName: "sonataflow",
+			},
+			PlatformConfig: orchestratorv1alpha2.PlatformConfig{
+				Namespace: "sonataflow-infra",
+				Resources: orchestratorv1alpha2.Resource{
+					Requests: orchestratorv1alpha2.MemoryCpu{Memory: "64Mi", Cpu: "250m"},
+					Limits:   orchestratorv1alpha2.MemoryCpu{Memory: "1Gi", Cpu: "500m"},
+				},
+			},
+		},
+	}
+}
+
+func TestValidateCreate(t *testing.T) {
+	ctx := context.TODO()
+	validator := &OrchestratorCustomValidator{}
+
+	testCases := []struct {
+		name           string
+		mutate         func(*orchestratorv1alpha2.Orchestrator)
+		expectedFields []string
+	}{
+		{
+			name:   "Valid spec",
+			mutate: func(o *orchestratorv1alpha2.Orchestrator) {},
+		},
+		{
+			name: "Invalid quantity",
+			mutate: func(o *orchestratorv1alpha2.Orchestrator) {
+				o.Spec.PlatformConfig.Resources.Limits.Cpu = "half"
+			},
+			expectedFields: []string{"spec.platform.resources.limits.cpu"},
+		},
+		{
+			name: "Requests exceed limits",
+			mutate: func(o *orchestratorv1alpha2.Orchestrator) {
+				o.Spec.PlatformConfig.Resources.Requests.Memory = "2Gi"
+				o.Spec.PlatformConfig.Resources.Requests.Cpu = "1"
+			},
+			expectedFields: []string{"spec.platform.resources.requests.cpu", "spec.platform.resources.requests.memory"},
+		},
+		{
+			name: "Invalid namespace name",
+			mutate: func(o *orchestratorv1alpha2.Orchestrator) {
+				o.Spec.RHDHConfig.Namespace = "RHDH_Namespace"
+			},
+			expectedFields: []string{"spec.rhdh.namespace"},
+		},
+		{
+			name: "Invalid RHDH exposure",
+			mutate: func(o *orchestratorv1alpha2.Orchestrator) {
+				o.Spec.RHDHConfig.BaseURL = "rhdh.example.com"
+				o.Spec.RHDHConfig.Ingress.Type = orchestratorv1alpha2.RHDHIngressTypeHTTPRoute
+				o.Spec.PlatformConfig.ClusterDomain = "Apps.Example.com"
+			},
+			expectedFields: []string{"spec.rhdh.baseUrl", "spec.rhdh.ingress.gatewayName", "spec.platform.clusterDomain"},
+		},
+		{
+			name: "Valid Knative specs",
+			mutate: func(o *orchestratorv1alpha2.Orchestrator) {
+				o.Spec.ServerlessOperator.Serving = &runtime.RawExtension{
+					Raw: []byte(`{"high-availability":{"replicas":2},"workloads":[{"name":"activator","replicas":3}]}`)}
+				o.Spec.ServerlessOperator.Eventing = &runtime.RawExtension{
+					Raw: []byte(`{"config":{"default-ch-webhook":{"default-ch-config":"{}"}},"sinkBindingSelectionMode":"inclusion"}`)}
+			},
+		},
+		{
+			name: "Unknown and mistyped Knative fields",
+			mutate: func(o *orchestratorv1alpha2.Orchestrator) {
+				o.Spec.ServerlessOperator.Serving = &runtime.RawExtension{Raw: []byte(`{"highAvailability":{"replicas":2}}`)}
+				o.Spec.ServerlessOperator.Eventing = &runtime.RawExtension{Raw: []byte(`{"high-availability":{"replicas":"2"}}`)}
+			},
+			expectedFields: []string{"spec.serverless.serving", "spec.serverless.eventing"},
+		},
+		{
+			name: "Invalid Knative replicas",
+			mutate: func(o *orchestratorv1alpha2.Orchestrator) {
+				o.Spec.ServerlessOperator.Serving = &runtime.RawExtension{
+					Raw: []byte(`{"high-availability":{"replicas":0},"workloads":[{"replicas":-1}]}`)}
+				o.Spec.ServerlessOperator.Eventing = &runtime.RawExtension{Raw: []byte(`{"sinkBindingSelectionMode":"all"}`)}
+			},
+			expectedFields: []string{"spec.serverless.serving.high-availability.replicas", "spec.serverless.serving.workloads[0].name",
+				"spec.serverless.serving.workloads[0].replicas", "spec.serverless.eventing.sinkBindingSelectionMode"},
+		},
+		{
+			name: "Broker name without namespace",
+			mutate: func(o *orchestratorv1alpha2.Orchestrator) {
+				o.Spec.PlatformConfig.Eventing.Broker.Name = "my-broker"
+			},
+			expectedFields: []string{"spec.platform.eventing.broker.namespace"},
+		},
+		{
+			name: "Broker namespace without name",
+			mutate: func(o *orchestratorv1alpha2.Orchestrator) {
+				o.Spec.PlatformConfig.Eventing.Broker.Namespace = "knative"
+			},
+			expectedFields: []string{"spec.platform.eventing.broker.name"},
+		},
+		{
+			name: "Broker creation without name",
+			mutate: func(o *orchestratorv1alpha2.Orchestrator) {
+				o.Spec.PlatformConfig.Eventing.Broker.Create = true
+			},
+			expectedFields: []string{"spec.platform.eventing.broker.name"},
+		},
+		{
+			name: "Invalid notification emails",
+			mutate: func(o *orchestratorv1alpha2.Orchestrator) {
+				o.Spec.RHDHConfig.RHDHPlugins.NotificationsConfig = orchestratorv1alpha2.NotificationConfig{
+					Enabled:   true,
+					Port:      587,
+					Sender:    "not-an-email",
+					Recipient: "also not an email",
+				}
+			},
+			expectedFields: []string{"spec.rhdh.plugins.notificationsEmail.sender", "spec.rhdh.plugins.notificationsEmail.replyTo"},
+		},
+		{
+			name: "Notification email enabled without sender",
+			mutate: func(o *orchestratorv1alpha2.Orchestrator) {
+				o.Spec.RHDHConfig.RHDHPlugins.NotificationsConfig = orchestratorv1alpha2.NotificationConfig{
+					Enabled: true,
+					Port:    587,
+				}
+			},
+			expectedFields: []string{"spec.rhdh.plugins.notificationsEmail.sender"},
+		},
+		{
+			name: "ArgoCD enabled without namespace",
+			mutate: func(o *orchestratorv1alpha2.Orchestrator) {
+				o.Spec.ArgoCd.Enabled = true
+				o.Spec.Tekton.Enabled = true
+			},
+			expectedFields: []string{"spec.argocd.namespace"},
+		},
+		{
+			name: "Tekton enabled without ArgoCD",
+			mutate: func(o *orchestratorv1alpha2.Orchestrator) {
+				o.Spec.Tekton.Enabled = true
+			},
+			expectedFields: []string{"spec.tekton.enabled"},
+		},
+		{
+			name: "Invalid RHDH resources and extra env secret",
+			mutate: func(o *orchestratorv1alpha2.Orchestrator) {
+				o.Spec.RHDHConfig.Resources.Limits.Memory = "lots"
+				o.Spec.RHDHConfig.ExtraEnvSecrets = []string{"my-secret", "My_Secret"}
+			},
+			expectedFields: []string{"spec.rhdh.resources.limits.memory", "spec.rhdh.extraEnvSecrets[1]"},
+		},
+		{
+			name: "Invalid disconnected mirror URLs",
+			mutate: func(o *orchestratorv1alpha2.Orchestrator) {
+				o.Spec.Disconnected = orchestratorv1alpha2.DisconnectedConfig{
+					Enabled:          true,
+					NpmRegistry:      "npm.mirror.example.com",
+					CatalogLocations: []string{"https://git.mirror.example.com/catalog.yaml", "ftp://mirror/catalog.yaml"},
+				}
+			},
+			expectedFields: []string{"spec.disconnected.npmRegistry", "spec.disconnected.catalogLocations[1]"},
+		},
+		{
+			name: "Invalid provisioned PostgreSQL storage",
+			mutate: func(o *orchestratorv1alpha2.Orchestrator) {
+				o.Spec.PostgresConfig.Provision = true
+				o.Spec.PostgresConfig.StorageSize = "0"
+				o.Spec.PostgresConfig.StorageClassName = "Fast_SSD"
+			},
+			expectedFields: []string{"spec.postgres.storageSize", "spec.postgres.storageClassName"},
+		},
+		{
+			name: "Invalid additional workflow namespaces",
+			mutate: func(o *orchestratorv1alpha2.Orchestrator) {
+				o.Spec.PlatformConfig.AdditionalWorkflowNamespaces = []string{"team-a", o.Spec.PlatformConfig.Namespace, "Team_B"}
+			},
+			expectedFields: []string{"spec.platform.additionalWorkflowNamespaces[1]", "spec.platform.additionalWorkflowNamespaces[2]"},
+		},
+		{
+			name: "Invalid RHDH auth providers",
+			mutate: func(o *orchestratorv1alpha2.Orchestrator) {
+				o.Spec.RHDHConfig.Auth.SessionSecretKey = "SESSION SECRET"
+				o.Spec.RHDHConfig.Auth.Providers = []orchestratorv1alpha2.RHDHAuthProvider{
+					{Type: orchestratorv1alpha2.RHDHAuthProviderOIDC, ClientIDKey: "1_CLIENT_ID"},
+					{Type: orchestratorv1alpha2.RHDHAuthProviderGitHub,
+						SignInResolvers: []string{"usernameMatchingUserEntityName", "oidcSubClaimMatchingKeycloakUserId"}},
+				}
+			},
+			expectedFields: []string{"spec.rhdh.auth.sessionSecretKey", "spec.rhdh.auth.providers[0].clientIdKey",
+				"spec.rhdh.auth.providers[0].url", "spec.rhdh.auth.providers[1].signInResolvers[1]"},
+		},
+		{
+			name: "Invalid RHDH integrations",
+			mutate: func(o *orchestratorv1alpha2.Orchestrator) {
+				o.Spec.RHDHConfig.Integrations = []orchestratorv1alpha2.RHDHIntegration{
+					{Type: orchestratorv1alpha2.RHDHIntegrationGitHub, Host: "github.example.com"},
+					{Type: orchestratorv1alpha2.RHDHIntegrationGitHub, Host: "github.example.com"},
+					{Type: orchestratorv1alpha2.RHDHIntegrationBitbucketServer},
+					{Type: orchestratorv1alpha2.RHDHIntegrationAzure, Host: "https://dev.azure.com/my-org", TokenKey: "AZURE TOKEN"},
+				}
+			},
+			expectedFields: []string{"spec.rhdh.integrations[1].host", "spec.rhdh.integrations[2].host",
+				"spec.rhdh.integrations[3].host", "spec.rhdh.integrations[3].tokenKey"},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			orchestrator := validOrchestrator()
+			tc.mutate(orchestrator)
+			_, err := validator.ValidateCreate(ctx, orchestrator)
+			if len(tc.expectedFields) == 0 {
+				assert.NoError(t, err)
+				return
+			}
+			assert.True(t, apierrors.IsInvalid(err), "Expected an Invalid error")
+
+			statusErr := err.(*apierrors.StatusError)
+			var actualFields []string
+			for _, cause := range statusErr.ErrStatus.Details.Causes {
+				actualFields = append(actualFields, cause.Field)
+			}
+			assert.ElementsMatch(t, tc.expectedFields, actualFields)
+		})
+	}
+}
+
+func TestValidateCreateWarnings(t *testing.T) {
+	ctx := context.TODO()
+	validator := &OrchestratorCustomValidator{}
+
+	orchestrator := validOrchestrator()
+	orchestrator.Spec.RHDHConfig.DevMode = true
+	orchestrator.Spec.ArgoCd = orchestratorv1alpha2.ArgoCD{Enabled: true, Namespace: "orchestrator-gitops"}
+
+	warnings, err := validator.ValidateCreate(ctx, orchestrator)
+	assert.NoError(t, err)
+	assert.Len(t, warnings, 2)
+}
+
+func TestValidateUpdateSkipsDeletion(t *testing.T) {
+	ctx := context.TODO()
+	validator := &OrchestratorCustomValidator{}
+
+	orchestrator := validOrchestrator()
+	orchestrator.Spec.PlatformConfig.Resources.Limits.Cpu = "half"
+	now := metav1.Now()
+	orchestrator.DeletionTimestamp = &now
+
+	_, err := validator.ValidateUpdate(ctx, validOrchestrator(), orchestrator)
+	assert.NoError(t, err)
+}